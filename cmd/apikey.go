@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go-image-cleanup/internal/domain/models"
+	repoImpl "go-image-cleanup/internal/infrastructure/repositories"
+	"go-image-cleanup/internal/usecases/auth"
+)
+
+// runAPIKey manages API keys stored in the local SQLite database
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: apikey <create|list|revoke|hash> [flags]")
+	}
+
+	switch args[0] {
+	case "create":
+		return runAPIKeyCreate(args[1:])
+	case "list":
+		return runAPIKeyList(args[1:])
+	case "revoke":
+		return runAPIKeyRevoke(args[1:])
+	case "hash":
+		return runAPIKeyHash(args[1:])
+	default:
+		return fmt.Errorf("unknown apikey command %q (expected create, list, revoke or hash)", args[0])
+	}
+}
+
+func runAPIKeyCreate(args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	nameFlag := fs.String("name", "", "name identifying the key owner")
+	scopesFlag := fs.String("scopes", string(models.ScopeRead), "comma-separated scopes: read, trigger, admin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	scopes, err := models.ParseScopes(*scopesFlag)
+	if err != nil {
+		return err
+	}
+
+	return withAuthService(func(ctx context.Context, authService *auth.AuthService) error {
+		token, key, err := authService.CreateKey(ctx, *nameFlag, scopes)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Created API key %s (%s) with scopes %s\n", key.ID, key.Name, models.FormatScopes(key.Scopes))
+		fmt.Fprintln(os.Stderr, "Store this key now, it cannot be shown again:")
+		fmt.Println(token)
+		return nil
+	})
+}
+
+func runAPIKeyList(args []string) error {
+	fs := flag.NewFlagSet("apikey list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return withAuthService(func(ctx context.Context, authService *auth.AuthService) error {
+		keys, err := authService.ListKeys(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tSOURCE\tLAST USED\tSTATUS")
+		for _, key := range keys {
+			status := "active"
+			if key.Revoked() {
+				status = "revoked"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Name, key.Prefix, models.FormatScopes(key.Scopes), key.Source, formatOptionalTime(key.LastUsedAt), status)
+		}
+		return w.Flush()
+	})
+}
+
+func runAPIKeyRevoke(args []string) error {
+	fs := flag.NewFlagSet("apikey revoke", flag.ContinueOnError)
+	idFlag := fs.String("id", "", "ID of the key to revoke")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *idFlag == "" {
+		return fmt.Errorf("-id is required")
+	}
+
+	return withAuthService(func(ctx context.Context, authService *auth.AuthService) error {
+		if err := authService.RevokeKey(ctx, *idFlag); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Revoked API key %s\n", *idFlag)
+		return nil
+	})
+}
+
+// runAPIKeyHash prints the API_KEYS_FILE line for a key read from stdin
+func runAPIKeyHash(args []string) error {
+	fs := flag.NewFlagSet("apikey hash", flag.ContinueOnError)
+	nameFlag := fs.String("name", "", "name identifying the key owner")
+	scopesFlag := fs.String("scopes", string(models.ScopeRead), "comma-separated scopes: read, trigger, admin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *nameFlag == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	scopes, err := models.ParseScopes(*scopesFlag)
+	if err != nil {
+		return err
+	}
+
+	var token string
+	if _, err := fmt.Fscanln(os.Stdin, &token); err != nil {
+		return fmt.Errorf("failed to read key from stdin: %w", err)
+	}
+
+	fmt.Printf("%s:%s:%s\n", *nameFlag, models.FormatScopes(scopes), auth.HashAPIKey(token))
+	return nil
+}
+
+// withAuthService opens the local SQLite database and runs fn with an auth service on it
+func withAuthService(fn func(ctx context.Context, authService *auth.AuthService) error) error {
+	cfg, log, err := loadCommandEnv()
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+
+	db, err := repoImpl.OpenSQLiteDatabase(cfg.SQLiteDBPath, log)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var fileKeys []models.APIKey
+	if cfg.APIKeysFile != "" {
+		if fileKeys, err = repoImpl.LoadAPIKeyFile(cfg.APIKeysFile); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return fn(ctx, auth.NewAuthService(repoImpl.NewSQLiteAPIKeyRepository(db, log), fileKeys, log))
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	repoImpl "go-image-cleanup/internal/infrastructure/repositories"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+)
+
+// runAuditArchive moves audit entries older than -before into an NDJSON file and deletes them
+// from audit_log. It is safe to run while the service is up.
+func runAuditArchive(args []string) error {
+	fs := flag.NewFlagSet("audit-archive", flag.ContinueOnError)
+	beforeFlag := fs.String("before", "", "archive entries older than this time (RFC3339 or YYYY-MM-DD, required)")
+	outputFlag := fs.String("output", "", "archive file path, must not exist (default: BACKUP_DIR/audit-before-<date>-<timestamp>.ndjson)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *beforeFlag == "" {
+		return fmt.Errorf("-before is required")
+	}
+	before, err := helper.ParseTimeParam(*beforeFlag)
+	if err != nil {
+		return err
+	}
+	if before.After(time.Now()) {
+		return fmt.Errorf("-before must not be in the future")
+	}
+
+	cfg, log, err := loadCommandEnv()
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+
+	output := *outputFlag
+	if output == "" {
+		if err := os.MkdirAll(cfg.BackupDir, 0700); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		name := fmt.Sprintf("audit-before-%s-%s.ndjson", before.UTC().Format("20060102"), time.Now().UTC().Format("20060102-150405"))
+		output = filepath.Join(cfg.BackupDir, name)
+	}
+
+	db, err := repoImpl.OpenSQLiteDatabase(cfg.SQLiteDBPath, log)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.BackupTimeout)
+	defer cancel()
+
+	archive, err := audit.NewAuditService(repoImpl.NewSQLiteAuditRepository(db, log), log).Archive(ctx, before, output)
+	if err != nil {
+		return err
+	}
+	if archive == nil {
+		fmt.Fprintf(os.Stderr, "No audit entries older than %s\n", before.Format(time.RFC3339))
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Archived %d audit entries to %s (sha256 %s)\n", archive.Entries, archive.Path, archive.SHA256)
+	return nil
+}
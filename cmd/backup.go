@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go-image-cleanup/config"
+	repoImpl "go-image-cleanup/internal/infrastructure/repositories"
+	"go-image-cleanup/internal/usecases/backup"
+	"go-image-cleanup/pkg/constants"
+)
+
+// runBackup creates a consistent copy of the SQLite database with VACUUM INTO
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	outputFlag := fs.String("output", "", "backup file path (default: BACKUP_DIR/cleanup-<timestamp>.db)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, log, err := loadCommandEnv()
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+
+	if cfg.ResultStore != config.ResultStoreSQLite {
+		return fmt.Errorf("backup is only supported for the SQLite result store, use pg_dump for PostgreSQL")
+	}
+
+	db, err := repoImpl.OpenSQLiteDatabase(cfg.SQLiteDBPath, log)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.BackupTimeout)
+	defer cancel()
+
+	backupRepo := repoImpl.NewSQLiteBackupRepository(db, log)
+
+	if *outputFlag != "" {
+		if err := backupRepo.Backup(ctx, *outputFlag); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Database backed up to %s\n", *outputFlag)
+		return nil
+	}
+
+	info, err := backup.NewBackupService(backupRepo, cfg.BackupDir, log).CreateBackup(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Database backed up to %s (%d bytes)\n", info.Path, info.SizeBytes)
+	return nil
+}
+
+// runRestore validates a backup and swaps it in place of the configured SQLite database.
+// It refuses to run while the service holds the database lock.
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	inputFlag := fs.String("input", "", "backup file to restore (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inputFlag == "" {
+		return fmt.Errorf("-input is required")
+	}
+
+	cfg, log, err := loadCommandEnv()
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+
+	if cfg.ResultStore != config.ResultStoreSQLite {
+		return fmt.Errorf("restore is only supported for the SQLite result store")
+	}
+
+	// The service holds this lock while it runs; swapping the file under an open database loses writes
+	unlock, err := repoImpl.LockSQLiteDatabase(cfg.SQLiteDBPath)
+	if errors.Is(err, repoImpl.ErrDatabaseLocked) {
+		return fmt.Errorf("refusing to restore while the service has the database open, stop it first: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.BackupTimeout)
+	defer cancel()
+
+	previous, err := repoImpl.RestoreSQLiteDatabase(ctx, *inputFlag, cfg.SQLiteDBPath, log)
+	if err != nil {
+		return err
+	}
+
+	// Open once so migrations bring an older backup up to the current schema
+	db, err := repoImpl.OpenSQLiteDatabase(cfg.SQLiteDBPath, log)
+	if err != nil {
+		return fmt.Errorf("restored database failed to open: %w", err)
+	}
+	db.Close()
+
+	fmt.Fprintf(os.Stderr, "Database restored from %s at %s\n", *inputFlag, time.Now().Format(time.RFC3339))
+	if previous != "" {
+		fmt.Fprintf(os.Stderr, "Previous database kept at %s\n", previous)
+	}
+	return nil
+}
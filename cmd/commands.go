@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"go-image-cleanup/config"
+	loggerPkg "go-image-cleanup/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+// command is a one-shot CLI subcommand run instead of the service
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]command{
+	"apikey":        {description: "Manage API keys: create, list, revoke, hash", run: runAPIKey},
+	"audit-archive": {description: "Move old audit log entries to an NDJSON file and delete them", run: runAuditArchive},
+	"backup":        {description: "Create an online backup of the SQLite database", run: runBackup},
+	"export":        {description: "Export cleanup history as CSV, JSON or NDJSON", run: runExport},
+	"restore":       {description: "Restore the SQLite database from a backup (service must be stopped)", run: runRestore},
+}
+
+// runCommand executes a CLI subcommand and returns the process exit code
+func runCommand(name string, args []string) int {
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return 0
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", name)
+		printUsage()
+		return 2
+	}
+
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Image Cleanup Service %s (built at %s)\n\n", Version, BuildTime)
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  image-cleanup                 Run the service")
+	fmt.Fprintln(os.Stderr, "  image-cleanup <command> -h    Show help for a command")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].description)
+	}
+}
+
+// loadCommandEnv loads the service configuration and logger for a CLI command
+func loadCommandEnv() (*config.Config, *zap.Logger, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	log, err := loggerPkg.NewLogger(cfg.Logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	return cfg, log, nil
+}
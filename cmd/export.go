@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+)
+
+// runExport streams cleanup history, or the per-image removals of those runs, from the configured
+// result store to a file or stdout
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatFlag := fs.String("format", "json", "output format: csv, json or ndjson")
+	typeFlag := fs.String("type", "runs", "exported data: runs or removals (per-image removal events)")
+	fromFlag := fs.String("from", "", "start of range (RFC3339 or YYYY-MM-DD, inclusive)")
+	toFlag := fs.String("to", "", "end of range (RFC3339 or YYYY-MM-DD, exclusive)")
+	outputFlag := fs.String("output", "-", "output file, - for stdout")
+	hostnameFlag := fs.String("hostname", "", "only export runs from this hostname")
+	nodeFlag := fs.String("node", "", "only export runs from this node name")
+	machineIDFlag := fs.String("machine_id", "", "only export runs from this machine ID")
+	labelsFlag := fs.String("labels", "", "only export runs whose host has all labels (key=value,key2=value2)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := history.ParseFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+
+	exportType, err := history.ParseExportType(*typeFlag)
+	if err != nil {
+		return err
+	}
+
+	from, err := helper.ParseTimeParam(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := helper.ParseTimeParam(*toFlag)
+	if err != nil {
+		return err
+	}
+
+	labels, err := helper.ParseLabels(*labelsFlag)
+	if err != nil {
+		return err
+	}
+
+	query := repositories.ResultQuery{
+		From:      from,
+		To:        to,
+		Hostname:  *hostnameFlag,
+		NodeName:  *nodeFlag,
+		MachineID: *machineIDFlag,
+		Labels:    labels,
+	}
+
+	cfg, log, err := loadCommandEnv()
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+
+	resultRepo, resultDB, err := newResultStore(cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to open result store: %w", err)
+	}
+	defer resultDB.Close()
+
+	var out io.Writer = os.Stdout
+	if *outputFlag != "-" {
+		file, err := os.Create(*outputFlag)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	w := bufio.NewWriter(out)
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ExportTimeout)
+	defer cancel()
+
+	service := history.NewHistoryService(resultRepo, log)
+	export, what := service.Export, "cleanup runs"
+	if exportType == history.ExportRemovals {
+		export, what = service.ExportRemovals, "image removals"
+	}
+
+	count, err := export(ctx, w, format, query)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d %s\n", count, what)
+	return nil
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"database/sql"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"strings"
@@ -10,16 +13,35 @@ import (
 	"time"
 
 	"go-image-cleanup/config"
+	"go-image-cleanup/internal/domain/health"
 	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/models"
+	notificationDomain "go-image-cleanup/internal/domain/notification"
 	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/infrastructure/certs"
 	"go-image-cleanup/internal/infrastructure/container"
+	"go-image-cleanup/internal/infrastructure/disk"
+	healthChecks "go-image-cleanup/internal/infrastructure/health"
+	"go-image-cleanup/internal/infrastructure/host"
+	"go-image-cleanup/internal/infrastructure/listener"
 	loggerPkg "go-image-cleanup/internal/infrastructure/logger"
 	prometheusMetrics "go-image-cleanup/internal/infrastructure/metrics"
 	"go-image-cleanup/internal/infrastructure/notification"
 	repoImpl "go-image-cleanup/internal/infrastructure/repositories"
 	"go-image-cleanup/internal/interfaces/http/handlers"
 	"go-image-cleanup/internal/interfaces/http/router"
+	"go-image-cleanup/internal/interfaces/telegram"
+	"go-image-cleanup/internal/usecases/alerting"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/internal/usecases/auth"
+	"go-image-cleanup/internal/usecases/backup"
 	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/internal/usecases/digest"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/internal/usecases/maintenance"
+	"go-image-cleanup/internal/usecases/outbox"
+	"go-image-cleanup/internal/usecases/readiness"
+	"go-image-cleanup/internal/usecases/schedule"
 	"go-image-cleanup/pkg/constants"
 	"go-image-cleanup/pkg/helper"
 
@@ -34,6 +56,11 @@ var (
 )
 
 func main() {
+	// Run a one-shot CLI command when one is given, otherwise start the service
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	// Print version info
 	fmt.Printf("Image Cleanup Service %s (built at %s)\n", Version, BuildTime)
 
@@ -57,52 +84,306 @@ func main() {
 
 	// Initialize infrastructure dependencies
 	repo := container.NewCrictlRepository(log)
-	notifier := notification.NewTelegramNotifier(cfg.TelegramBotToken, cfg.TelegramChatID, log)
 	metricsCollector := prometheusMetrics.NewPrometheusMetrics(log)
+	// Template được kiểm tra ngay khi khởi động để lỗi cú pháp không làm mất thông báo về sau
+	templates, err := notification.LoadTemplates(cfg.NotifyTemplateDir, cfg.NotifyLanguage, cfg.NotifyTimezone)
+	if err != nil {
+		log.Fatal("Failed to load notification templates",
+			zap.String("template_dir", cfg.NotifyTemplateDir),
+			zap.Error(err))
+	}
+	if cfg.NotifyTemplateDir != "" {
+		log.Info("Notification templates loaded", zap.Strings("templates", templates.Names()))
+	}
 
-	// Khai báo resultRepo với kiểu interface
-	var resultRepo repositories.CleanupResultRepository
-
-	// Khởi tạo SQLite repository
-	sqliteRepo, err := repoImpl.NewSQLiteCleanupResultRepository(cfg.SQLiteDBPath, log)
+	// Giữ lock trên SQLite local suốt thời gian chạy để lệnh restore không thay database đang mở
+	unlockDB, err := repoImpl.LockSQLiteDatabase(cfg.SQLiteDBPath)
 	if err != nil {
-		log.Fatal("Failed to initialize SQLite repository", zap.Error(err))
+		log.Fatal("Failed to lock local database, is another instance running?",
+			zap.String("path", cfg.SQLiteDBPath),
+			zap.Error(err))
 	}
+	defer func() {
+		if err := unlockDB(); err != nil {
+			log.Error("Error unlocking local database", zap.Error(err))
+		}
+	}()
 
-	// Gán instance cho interface
-	resultRepo = sqliteRepo
+	// Khởi tạo result store theo RESULT_STORE
+	resultRepo, resultDB, err := newResultStore(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to initialize result store",
+			zap.String("result_store", cfg.ResultStore),
+			zap.Error(err))
+	}
 
 	// Đóng kết nối khi app shutdown
 	defer func() {
-		if err := sqliteRepo.Close(); err != nil {
-			log.Error("Error closing SQLite connection", zap.Error(err))
+		if err := resultDB.Close(); err != nil {
+			log.Error("Error closing result store connection", zap.Error(err))
 		}
 	}()
 
-	// Initialize services
-	cleanupService := cleanup.NewCleanupService(repo, resultRepo, notifier, metricsCollector, log)
+	// SQLite local chứa dữ liệu riêng của node như API key
+	localDB, err := openLocalDatabase(cfg, resultDB, log)
+	if err != nil {
+		log.Fatal("Failed to open local database", zap.Error(err))
+	}
+	if localDB != resultDB {
+		defer func() {
+			if err := localDB.Close(); err != nil {
+				log.Error("Error closing local database connection", zap.Error(err))
+			}
+		}()
+	}
 
-	// Initialize handlers
-	handlers := initializeHandlers(log, Version, BuildTime, metricsCollector, cleanupService)
+	authService, err := newAuthService(cfg, localDB, log)
+	if err != nil {
+		log.Fatal("Failed to initialize API authentication", zap.Error(err))
+	}
 
-	// Setup router and HTTP server
-	app := router.NewFiberApp(log)
-	router.SetupRoutes(app, handlers, metricsCollector, log)
+	// Initialize services
+	hostIdentifier := host.NewSystemIdentifier(cfg.NodeName, cfg.HostLabels, repo, log)
+
+	// Lịch thay đổi qua API được lưu trong SQLite local và ưu tiên hơn file cấu hình;
+	// scheduler chỉ được start sau khi server đã sẵn sàng
+	cronScheduler := newCronScheduler()
+	scheduleService := schedule.NewScheduleService(cronScheduler, repoImpl.NewSQLiteScheduleRepository(localDB, log), log)
+
+	// Alert tới Alertmanager khi cleanup lỗi hoặc lâu không chạy thành công, resolve ở lần chạy thành công sau
+	var alertingService *alerting.AlertingService
+	if len(cfg.AlertmanagerURLs) > 0 {
+		alertingService = alerting.NewAlertingService(notification.NewAlertmanagerClient(notification.AlertmanagerConfig{
+			URLs:    cfg.AlertmanagerURLs,
+			Timeout: cfg.AlertmanagerTimeout,
+		}, log), resultRepo, hostIdentifier, cfg.AlertmanagerFailureThreshold,
+			cleanupRunWindow(scheduleService, cfg.CleanupSchedule, alertStaleAfter(cfg)), log)
+	}
+	notifier := newNotifier(cfg, templates, alertingService, metricsCollector, log)
+	cleanupService := cleanup.NewCleanupService(repo, resultRepo, notifier, metricsCollector, hostIdentifier, log)
+	if cfg.DiskPressureThreshold > 0 {
+		cleanupService.SetDiskPressureCheck(disk.NewStatfsReader(), cfg.DiskPressurePath, cfg.DiskPressureThreshold)
+	}
+	maintenanceService := maintenance.NewMaintenanceService(resultRepo, retentionPolicy(cfg, hostIdentifier.NodeName()), metricsCollector, log)
+	historyService := history.NewHistoryService(resultRepo, log)
+	auditService := audit.NewAuditService(repoImpl.NewSQLiteAuditRepository(localDB, log), log)
+
+	// Thông báo gửi thất bại được lưu trong SQLite local và gửi lại từ worker chạy nền
+	outboxService := outbox.NewOutboxService(repoImpl.NewSQLiteOutboxRepository(localDB, log), notifier, metricsCollector,
+		cfg.NotifyOutboxMaxAttempts, cfg.NotifyRetryBackoff, log)
+	notifier.SetOutbox(outboxService)
+
+	// Online backup chỉ hỗ trợ SQLite; với PostgreSQL dùng pg_dump
+	var backupService backup.BackupUseCase
+	if cfg.ResultStore == config.ResultStoreSQLite {
+		backupService = backup.NewBackupService(repoImpl.NewSQLiteBackupRepository(resultDB, log), cfg.BackupDir, log)
+	}
 
 	// Initialize cleanup job context
 	cleanupCtx, cleanupCancel := context.WithCancel(context.Background())
 	defer cleanupCancel()
+	go outboxService.Run(cleanupCtx)
+	if alertingService != nil {
+		go alertingService.Run(cleanupCtx)
+	}
 
-	// Setup and start cron jobs
-	cronScheduler := setupCronJobs(cleanupCtx, cleanupService, cfg.CleanupSchedule, log)
+	// Bot nhận lệnh Telegram dùng chung use case với API; lần chạy /cleanup dừng khi service shutdown
+	// và được ghi vào audit log như POST /api/v1/cleanup
+	if cfg.TelegramCommandsEnabled {
+		bot := telegram.NewBot(telegram.BotConfig{
+			BotToken:   cfg.TelegramBotToken,
+			APIBase:    cfg.TelegramAPIURL,
+			AllowedIDs: cfg.TelegramAllowedIDs,
+			NodeName:   hostIdentifier.NodeName(),
+		}, cleanupService, historyService, auditService, log)
+		go bot.Run(cleanupCtx)
+	}
+
+	// Setup cron jobs; the scheduler is started once the server is set up.
+	setupCronJobs(cleanupCtx, scheduleService, cleanupService, cfg.CleanupSchedule, log)
+	addMaintenanceJob(cleanupCtx, scheduleService, maintenanceService, cfg.MaintenanceSchedule, log)
+
+	// Bản tổng hợp qua email chạy như một job riêng, đổi lịch được qua /api/v1/schedules/digest
+	if cfg.SMTPHost != "" && cfg.EmailMode != config.EmailModeRun {
+		emailNotifier := notification.NewTemplatedNotifier(notification.ChannelEmail, newEmailNotifier(cfg, log), templates)
+		digestService := digest.NewDigestService(resultRepo, emailNotifier, hostIdentifier, cfg.EmailDigestPeriod, log)
+		addDigestJob(cleanupCtx, scheduleService, digestService, cfg.EmailDigestSchedule, log)
+	}
+
+	// Dependency checks cho /ready và /health
+	readinessService := readiness.NewReadinessService([]health.Checker{
+		healthChecks.NewRuntimeCheck(repo),
+		healthChecks.NewDatabaseCheck("result_store", resultRepo),
+		healthChecks.NewSchedulerCheck(cronScheduler, pausedJobs(scheduleService)),
+		healthChecks.NewLastRunCheck(resultRepo, hostIdentifier.NodeName(),
+			cleanupRunWindow(scheduleService, cfg.CleanupSchedule, cfg.ReadyMaxRunAge), time.Now()),
+	}, constants.ReadinessCheckTimeout, log)
+
+	// Initialize handlers
+	handlers := initializeHandlers(log, Version, BuildTime, metricsCollector, cleanupService, historyService, backupService, readinessService, scheduleService, auditService, outboxService)
+
+	// TLS là tùy chọn; nil khi chạy HTTP thường
+	tlsReloader, err := newTLSReloader(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to load TLS configuration", zap.Error(err))
+	}
+	requireClientCert := tlsReloader != nil && tlsReloader.ClientAuthEnabled()
+
+	// Setup router and HTTP server
+	app := router.NewFiberApp(log)
+	router.SetupRoutes(app, handlers, metricsCollector, authService, auditService, requireClientCert, log)
+
+	// Start cron jobs
 	cronScheduler.Start()
 	defer cronScheduler.Stop()
 
+	// Nạp lại certificate khi file thay đổi
+	if tlsReloader != nil {
+		go tlsReloader.Watch(cleanupCtx, constants.TLSReloadInterval)
+	}
+
 	// Start server and handle shutdown
-	serverErrChan := startServer(app, cfg.HTTPPort, log)
+	serverErrChan := startServer(app, cfg, tlsReloader, log)
 	handleGracefulShutdown(app, serverErrChan, cleanupCancel, log)
 }
 
+// newNotifier gộp các kênh thông báo được cấu hình; không có kênh nào thì thông báo bị bỏ qua
+func newNotifier(cfg *config.Config, templates *notification.Templates, alertingService *alerting.AlertingService, metricsCollector metrics.MetricsCollector, log *zap.Logger) *notification.MultiNotifier {
+	var channels []notification.Channel
+	add := func(name string, notifier notificationDomain.EventNotifier) {
+		rule := cfg.NotificationRules[name]
+		channels = append(channels, notification.Channel{
+			Name:     name,
+			Notifier: notifier,
+			Rule:     rule,
+		})
+		log.Info("Notification channel configured",
+			zap.String("channel", name),
+			zap.Stringer("rule", rule))
+	}
+
+	if cfg.TelegramBotToken != "" {
+		// Chat ID đã được kiểm tra khi load config
+		chats, _ := notification.ParseTelegramChats(cfg.TelegramChatIDs)
+		add(notification.ChannelTelegram, notification.NewTelegramNotifier(notification.TelegramConfig{
+			BotToken:   cfg.TelegramBotToken,
+			Chats:      chats,
+			ParseMode:  cfg.TelegramParseMode,
+			Timeout:    cfg.TelegramTimeout,
+			MaxRetries: cfg.TelegramMaxRetries,
+			APIBase:    cfg.TelegramAPIURL,
+		}, templates, log))
+	}
+	// Webhook gửi mỗi sự kiện dưới dạng JSON có kiểu, không dùng template
+	if len(cfg.WebhookURLs) > 0 {
+		add(notification.ChannelWebhook, notification.NewWebhookNotifier(notification.WebhookConfig{
+			URLs:         cfg.WebhookURLs,
+			Secret:       cfg.WebhookSecret,
+			Timeout:      cfg.WebhookTimeout,
+			MaxRetries:   cfg.WebhookMaxRetries,
+			RetryBackoff: constants.WebhookRetryBackoff,
+		}, log))
+	}
+	// Các kênh còn lại nhận sự kiện qua TemplatedNotifier
+	if cfg.SlackWebhookURL != "" {
+		add(notification.ChannelSlack, notification.NewTemplatedNotifier(notification.ChannelSlack, notification.NewSlackNotifier(cfg.SlackWebhookURL, log), templates))
+	}
+	if cfg.SMTPHost != "" && cfg.EmailMode != config.EmailModeDigest {
+		add(notification.ChannelEmail, notification.NewTemplatedNotifier(notification.ChannelEmail, newEmailNotifier(cfg, log), templates))
+	}
+	// Alertmanager cần kết quả của mọi lần chạy để resolve alert, nên không dùng rule cấu hình được
+	if alertingService != nil {
+		channels = append(channels, notification.Channel{
+			Name:     notification.ChannelAlertmanager,
+			Notifier: alertingService,
+			Rule:     notificationDomain.Rule{Events: []notificationDomain.EventType{notificationDomain.EventRunCompleted, notificationDomain.EventRunFailed}},
+		})
+		log.Info("Alertmanager alerts configured",
+			zap.Int("alertmanagers", len(cfg.AlertmanagerURLs)),
+			zap.Int("failure_threshold", cfg.AlertmanagerFailureThreshold),
+			zap.Duration("stale_after", alertStaleAfter(cfg)))
+	}
+
+	if len(channels) == 0 {
+		log.Warn("No notification channel configured, cleanup results will not be sent (set TELEGRAM_BOT_TOKEN, SLACK_WEBHOOK_URL, WEBHOOK_URLS, SMTP_HOST or ALERTMANAGER_URL)")
+	}
+	return notification.NewMultiNotifier(metricsCollector, log, channels...)
+}
+
+func newEmailNotifier(cfg *config.Config, log *zap.Logger) *notification.EmailNotifier {
+	return notification.NewEmailNotifier(notification.EmailConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		TLSMode:  cfg.SMTPTLS,
+		From:     cfg.EmailFrom,
+		To:       cfg.EmailTo,
+	}, log)
+}
+
+// resultStore là repository kết quả cleanup có hỗ trợ bảo trì
+type resultStore interface {
+	repositories.CleanupResultRepository
+	repositories.MaintenanceRepository
+}
+
+// newResultStore mở database theo RESULT_STORE và trả về repository cùng kết nối để đóng khi shutdown
+func newResultStore(cfg *config.Config, log *zap.Logger) (resultStore, *sql.DB, error) {
+	switch cfg.ResultStore {
+	case config.ResultStorePostgres:
+		db, err := repoImpl.OpenPostgresDatabase(cfg.PostgresDSN, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repoImpl.NewPostgresCleanupResultRepository(db, log), db, nil
+	default:
+		db, err := repoImpl.OpenSQLiteDatabase(cfg.SQLiteDBPath, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repoImpl.NewSQLiteCleanupResultRepository(db, log), db, nil
+	}
+}
+
+// openLocalDatabase trả về database SQLite local; dùng chung kết nối với result store khi RESULT_STORE=sqlite
+func openLocalDatabase(cfg *config.Config, resultDB *sql.DB, log *zap.Logger) (*sql.DB, error) {
+	if cfg.ResultStore == config.ResultStoreSQLite {
+		return resultDB, nil
+	}
+	return repoImpl.OpenSQLiteDatabase(cfg.SQLiteDBPath, log)
+}
+
+// newAuthService tạo service xác thực API key, trả về nil khi AUTH_ENABLED=false
+func newAuthService(cfg *config.Config, localDB *sql.DB, log *zap.Logger) (auth.AuthUseCase, error) {
+	if !cfg.AuthEnabled {
+		if cfg.HTTPPort != "" {
+			log.Warn("API authentication is disabled and HTTP_PORT listens on every interface, "+
+				"anyone who can reach it can trigger cleanups and change schedules (set AUTH_ENABLED=true)",
+				zap.String("http_port", cfg.HTTPPort))
+		} else {
+			log.Info("API authentication is disabled, /api/v1 is only served on the unix socket",
+				zap.String("http_socket_path", cfg.HTTPSocketPath))
+		}
+		return nil, nil
+	}
+
+	var fileKeys []models.APIKey
+	if cfg.APIKeysFile != "" {
+		keys, err := repoImpl.LoadAPIKeyFile(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		fileKeys = keys
+	}
+
+	log.Info("API authentication enabled",
+		zap.String("api_keys_file", cfg.APIKeysFile),
+		zap.Int("file_keys", len(fileKeys)))
+
+	return auth.NewAuthService(repoImpl.NewSQLiteAPIKeyRepository(localDB, log), fileKeys, log), nil
+}
+
 func logStartupInfo(log *zap.Logger, cfg *config.Config, version, buildTime string) {
 	log.Info("Starting Image Cleanup Service",
 		zap.String("version", version),
@@ -110,9 +391,20 @@ func logStartupInfo(log *zap.Logger, cfg *config.Config, version, buildTime stri
 
 	log.Info("Configuration loaded",
 		zap.String("telegram_bot_token", helper.MaskValue(cfg.TelegramBotToken)),
-		zap.String("telegram_chat_id", helper.MaskValue(cfg.TelegramChatID)),
+		zap.String("telegram_chat_id", helper.MaskValue(strings.Join(cfg.TelegramChatIDs, ","))),
+		zap.String("slack_webhook_url", helper.MaskValue(cfg.SlackWebhookURL)),
 		zap.String("cleanup_schedule", cfg.CleanupSchedule),
-		zap.String("http_port", cfg.HTTPPort))
+		zap.String("http_port", cfg.HTTPPort),
+		zap.String("http_socket_path", cfg.HTTPSocketPath),
+		zap.String("result_store", cfg.ResultStore),
+		zap.Bool("auth_enabled", cfg.AuthEnabled),
+		zap.String("node_name", cfg.NodeName),
+		zap.String("host_labels", helper.FormatLabels(cfg.HostLabels)))
+
+	log.Info("History retention configuration",
+		zap.Int("history_retention_days", cfg.HistoryRetentionDays),
+		zap.Int("history_max_rows", cfg.HistoryMaxRows),
+		zap.String("maintenance_schedule", cfg.MaintenanceSchedule))
 
 	log.Info("Logger configuration",
 		zap.String("log_level", cfg.Logger.Level),
@@ -126,39 +418,200 @@ func logStartupInfo(log *zap.Logger, cfg *config.Config, version, buildTime stri
 func initializeHandlers(log *zap.Logger,
 	version, buildTime string,
 	metricsCollector metrics.MetricsCollector,
-	cleanupUseCase cleanup.CleanupUseCase) *handlers.Handlers {
-	return handlers.NewHandlers(log, version, buildTime, metricsCollector, cleanupUseCase)
+	cleanupUseCase cleanup.CleanupUseCase,
+	historyUseCase history.HistoryUseCase,
+	backupUseCase backup.BackupUseCase,
+	readinessUseCase readiness.ReadinessUseCase,
+	scheduleUseCase schedule.ScheduleUseCase,
+	auditUseCase audit.AuditUseCase,
+	outboxUseCase outbox.OutboxUseCase) *handlers.Handlers {
+	return handlers.NewHandlers(log, version, buildTime, metricsCollector, cleanupUseCase, historyUseCase, backupUseCase, readinessUseCase, scheduleUseCase, auditUseCase, outboxUseCase)
 }
 
-func setupCronJobs(ctx context.Context, cleanupUseCase cleanup.CleanupUseCase, schedule string, log *zap.Logger) *cron.Cron {
-	c := cron.New(cron.WithChain(
+func newCronScheduler() *cron.Cron {
+	return cron.New(cron.WithChain(
 		cron.SkipIfStillRunning(cron.DefaultLogger),
 		cron.Recover(cron.DefaultLogger),
 	))
+}
 
-	_, err := c.AddFunc(schedule, func() {
+func setupCronJobs(ctx context.Context, scheduleService *schedule.ScheduleService, cleanupUseCase cleanup.CleanupUseCase, expression string, log *zap.Logger) {
+	err := scheduleService.Register(ctx, models.ScheduleCleanup, expression, func() {
 		jobCtx, cancel := context.WithTimeout(ctx, constants.CleanupTimeout)
 		defer cancel()
 
 		if err := cleanupUseCase.Cleanup(jobCtx); err != nil {
-			log.Error("Cleanup job failed",
-				zap.Error(err),
-				zap.String("schedule", schedule))
+			log.Error("Cleanup job failed", zap.Error(err))
 		}
 	})
 	if err != nil {
 		log.Fatal("Failed to schedule cleanup job", zap.Error(err))
 	}
+}
+
+// retentionPolicy tạo policy lưu trữ lịch sử. PostgreSQL được nhiều node dùng chung nên mỗi node
+// chỉ xóa lịch sử của chính nó; SQLite là file local nên giữ nguyên cả những dòng có node_name cũ.
+func retentionPolicy(cfg *config.Config, nodeName string) repositories.RetentionPolicy {
+	policy := repositories.RetentionPolicy{
+		MaxAge:  time.Duration(cfg.HistoryRetentionDays) * 24 * time.Hour,
+		MaxRows: cfg.HistoryMaxRows,
+	}
+	if cfg.ResultStore == config.ResultStorePostgres {
+		policy.NodeName = nodeName
+	}
+	return policy
+}
+
+// cleanupRunWindow trả về hàm đọc lịch cleanup hiện tại từ scheduleService, để /ready và alert
+// theo kịp việc đổi lịch hay tạm dừng qua API. maxAge > 0 thay cho giá trị tính từ biểu thức cron.
+func cleanupRunWindow(scheduleService *schedule.ScheduleService, configured string, maxAge time.Duration) health.RunWindowFunc {
+	return func() health.RunWindow {
+		status, err := scheduleService.GetSchedule(context.Background(), models.ScheduleCleanup)
+		if err != nil {
+			return health.RunWindow{MaxAge: runMaxAge(configured, maxAge)}
+		}
+
+		window := health.RunWindow{
+			MaxAge: runMaxAge(status.Expression, maxAge),
+			Paused: status.Paused,
+		}
+		if status.UpdatedAt != nil {
+			window.Since = *status.UpdatedAt
+		}
+		return window
+	}
+}
+
+// runMaxAge trả về maxAge nếu được cấu hình, nếu không là hai chu kỳ của expression cộng thời gian chạy tối đa
+func runMaxAge(expression string, maxAge time.Duration) time.Duration {
+	if maxAge > 0 {
+		return maxAge
+	}
+
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return 48*time.Hour + constants.CleanupTimeout
+	}
+	next := schedule.Next(time.Now())
+	interval := schedule.Next(next).Sub(next)
+
+	return 2*interval + constants.CleanupTimeout
+}
+
+// alertStaleAfter là khoảng không có lần chạy thành công trước khi bật alert; mặc định giống /ready,
+// 0 là tính theo lịch cleanup hiện tại
+func alertStaleAfter(cfg *config.Config) time.Duration {
+	if cfg.AlertmanagerStaleAfter > 0 {
+		return cfg.AlertmanagerStaleAfter
+	}
+	return cfg.ReadyMaxRunAge
+}
+
+// pausedJobs đếm số job đang tạm dừng qua API cho SchedulerCheck
+func pausedJobs(scheduleService *schedule.ScheduleService) func() int {
+	return func() int {
+		paused := 0
+		for _, status := range scheduleService.ListSchedules(context.Background()) {
+			if status.Paused {
+				paused++
+			}
+		}
+		return paused
+	}
+}
+
+func addMaintenanceJob(ctx context.Context, scheduleService *schedule.ScheduleService, maintenanceUseCase maintenance.MaintenanceUseCase, expression string, log *zap.Logger) {
+	err := scheduleService.Register(ctx, models.ScheduleMaintenance, expression, func() {
+		jobCtx, cancel := context.WithTimeout(ctx, constants.MaintenanceTimeout)
+		defer cancel()
+
+		if err := maintenanceUseCase.RunMaintenance(jobCtx); err != nil {
+			log.Error("Database maintenance job failed", zap.Error(err))
+		}
+	})
+	if err != nil {
+		log.Fatal("Failed to schedule database maintenance job", zap.Error(err))
+	}
+}
+
+func addDigestJob(ctx context.Context, scheduleService *schedule.ScheduleService, digestUseCase digest.DigestUseCase, expression string, log *zap.Logger) {
+	err := scheduleService.Register(ctx, models.ScheduleDigest, expression, func() {
+		jobCtx, cancel := context.WithTimeout(ctx, constants.DigestTimeout)
+		defer cancel()
+
+		if err := digestUseCase.SendDigest(jobCtx); err != nil {
+			log.Error("Cleanup digest job failed", zap.Error(err))
+		}
+	})
+	if err != nil {
+		log.Fatal("Failed to schedule cleanup digest job", zap.Error(err))
+	}
+}
+
+// newTLSReloader nạp certificate khi TLS_CERT_FILE được đặt, trả về nil khi TLS tắt
+func newTLSReloader(cfg *config.Config, log *zap.Logger) (*certs.Reloader, error) {
+	if cfg.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	reloader, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile, log)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("TLS enabled",
+		zap.String("cert_file", cfg.TLSCertFile),
+		zap.Bool("client_cert_required", reloader.ClientAuthEnabled()))
+
+	return reloader, nil
+}
+
+// listen serves plain HTTP, or HTTPS with the reloadable certificate when tlsReloader is set, on HTTP_PORT
+// and/or the unix socket. The socket is always plain HTTP: it is local and guarded by its file permissions.
+func listen(app *router.FiberApp, cfg *config.Config, tlsReloader *certs.Reloader) error {
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+	}
+
+	if cfg.HTTPPort != "" {
+		network := app.Config().Network
+		if tlsReloader != nil {
+			network = "tcp"
+		}
+		ln, err := net.Listen(network, ":"+cfg.HTTPPort)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+		if tlsReloader != nil {
+			ln = tls.NewListener(ln, tlsReloader.TLSConfig())
+		}
+		listeners = append(listeners, ln)
+	}
+
+	if cfg.HTTPSocketPath != "" {
+		ln, err := listener.Unix(cfg.HTTPSocketPath, cfg.HTTPSocketMode)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, ln)
+	}
 
-	log.Info("Cron scheduler started", zap.String("schedule", schedule))
-	return c
+	return app.Listener(listener.Merge(listeners...))
 }
 
-func startServer(app *router.FiberApp, port string, log *zap.Logger) chan error {
+func startServer(app *router.FiberApp, cfg *config.Config, tlsReloader *certs.Reloader, log *zap.Logger) chan error {
 	serverErr := make(chan error, 1)
 	go func() {
-		log.Info("Starting HTTP server", zap.String("port", port))
-		if err := app.Listen(":" + port); err != nil {
+		log.Info("Starting HTTP server",
+			zap.String("port", cfg.HTTPPort),
+			zap.Bool("tls", tlsReloader != nil),
+			zap.String("socket_path", cfg.HTTPSocketPath),
+			zap.String("socket_mode", fmt.Sprintf("%#o", cfg.HTTPSocketMode)))
+		if err := listen(app, cfg, tlsReloader); err != nil {
 			// Only send error if it's not a normal shutdown
 			if !strings.Contains(err.Error(), "server closed") {
 				log.Error("Server error", zap.Error(err))
@@ -173,7 +626,8 @@ func startServer(app *router.FiberApp, port string, log *zap.Logger) chan error
 	time.Sleep(100 * time.Millisecond)
 
 	log.Info("Service started successfully",
-		zap.String("port", port),
+		zap.String("port", cfg.HTTPPort),
+		zap.String("socket_path", cfg.HTTPSocketPath),
 		zap.String("version", Version),
 		zap.String("buildTime", BuildTime))
 
@@ -2,19 +2,119 @@ package config
 
 import (
 	"fmt"
+	notificationDomain "go-image-cleanup/internal/domain/notification"
 	"go-image-cleanup/internal/infrastructure/logger"
+	"go-image-cleanup/internal/infrastructure/notification"
 	"go-image-cleanup/pkg/helper"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Các giá trị hợp lệ cho RESULT_STORE
+const (
+	ResultStoreSQLite   = "sqlite"
+	ResultStorePostgres = "postgres"
+)
+
+// Các giá trị hợp lệ cho EMAIL_MODE
+const (
+	EmailModeRun    = "run"    // Gửi báo cáo sau mỗi lần cleanup
+	EmailModeDigest = "digest" // Chỉ gửi bản tổng hợp theo EMAIL_DIGEST_SCHEDULE
+	EmailModeBoth   = "both"
+)
+
 type Config struct {
 	TelegramBotToken string
-	TelegramChatID   string
+	TelegramChatIDs  []string // <chat_id>[:<thread_id>], gửi tới từng chat
 	CleanupSchedule  string
-	HTTPPort         string
+	HTTPPort         string // Port TCP cho API, rỗng hoặc "0" = chỉ dùng unix socket
 	SQLiteDBPath     string // Thêm đường dẫn đến SQLite database
+	ResultStore      string // Nơi lưu kết quả cleanup: sqlite hoặc postgres
+	PostgresDSN      string // Connection string khi ResultStore = postgres
+	BackupDir        string // Thư mục lưu file backup SQLite
+
+	// Telegram config, dùng cùng TelegramBotToken và TelegramChatIDs
+	TelegramParseMode  string        // text hoặc MarkdownV2
+	TelegramTimeout    time.Duration // Timeout cho mỗi request tới Bot API
+	TelegramMaxRetries int           // Số lần thử lại khi lỗi mạng, 429 hoặc 5xx
+	TelegramAPIURL     string        // Bot API server, thay đổi khi dùng server riêng hoặc stub để test
+
+	// Bot nhận lệnh Telegram (/status, /cleanup, ...) qua long polling
+	TelegramCommandsEnabled bool
+	TelegramAllowedIDs      []int64 // Chat hoặc user được phép gửi lệnh
+
+	// Notification config
+	SlackWebhookURL   string        // Incoming webhook của Slack, bỏ trống để tắt
+	WebhookURLs       []string      // Các URL nhận event JSON của mỗi lần chạy
+	WebhookSecret     string        // Khóa HMAC-SHA256 để ký event, bắt buộc khi có WebhookURLs
+	WebhookTimeout    time.Duration // Timeout cho mỗi lần gửi
+	WebhookMaxRetries int           // Số lần thử lại khi lỗi mạng, 429 hoặc 5xx
+
+	// Alertmanager config
+	AlertmanagerURLs             []string      // Alertmanager nhận alert khi cleanup lỗi, bỏ trống để tắt
+	AlertmanagerTimeout          time.Duration // Timeout cho mỗi request
+	AlertmanagerFailureThreshold int           // Bật alert khi số image xóa lỗi trong một lần chạy vượt quá ngưỡng
+	AlertmanagerStaleAfter       time.Duration // Bật alert khi không có lần chạy thành công trong khoảng này, 0 = tự tính từ CLEANUP_SCHEDULE
+
+	// Email config
+	SMTPHost            string        // SMTP server, bỏ trống để tắt email
+	SMTPPort            string        // 587 cho STARTTLS, 465 cho TLS
+	SMTPUsername        string        // Bỏ trống nếu relay không yêu cầu xác thực
+	SMTPPassword        string        // Mật khẩu cho AUTH PLAIN
+	SMTPTLS             string        // starttls, tls hoặc none
+	EmailFrom           string        // Địa chỉ người gửi
+	EmailTo             []string      // Danh sách người nhận
+	EmailMode           string        // run, digest hoặc both
+	EmailDigestSchedule string        // Cron schedule gửi bản tổng hợp
+	EmailDigestPeriod   time.Duration // Khoảng thời gian được tổng hợp, tính lùi từ lúc gửi
+
+	// Rule lọc sự kiện theo từng kênh (telegram, slack, webhook, email), đọc từ <KÊNH>_EVENTS,
+	// <KÊNH>_ONLY_FAILURES, <KÊNH>_MIN_REMOVED, <KÊNH>_HOSTS và <KÊNH>_QUIET_HOURS
+	NotificationRules map[string]notificationDomain.Rule
+
+	// Template thông báo
+	NotifyLanguage    string // Ngôn ngữ của template có sẵn: en hoặc vi
+	NotifyTimezone    string // Múi giờ hiển thị trong thông báo, rỗng = ICT
+	NotifyTemplateDir string // Thư mục chứa <event>.tmpl và <kênh>.<event>.tmpl, tùy chọn
+
+	// Outbox thông báo: lần gửi thất bại được gửi lại với khoảng chờ tăng gấp đôi
+	NotifyOutboxMaxAttempts int           // Số lần gửi tối đa, tính cả lần đầu, trước khi thành dead letter
+	NotifyRetryBackoff      time.Duration // Khoảng chờ trước lần gửi lại đầu tiên
+
+	// Cảnh báo disk pressure sau cleanup
+	DiskPressurePath      string  // Thư mục nằm trên filesystem chứa image
+	DiskPressureThreshold float64 // Phần trăm dung lượng đã dùng để gửi cảnh báo, 0 = tắt
+
+	// Unix socket config
+	HTTPSocketPath string      // Unix socket cho API, dùng cùng hoặc thay cho HTTPPort, tùy chọn
+	HTTPSocketMode os.FileMode // Quyền của file socket, quyết định user/group nào gọi được API
+
+	// Host identity config
+	NodeName   string            // Tên node (ví dụ Kubernetes node name), mặc định là hostname
+	HostLabels map[string]string // Label gắn vào mỗi kết quả, dùng để lọc khi nhiều node chung một store
+
+	// API authentication config
+	AuthEnabled bool   // Bật xác thực bằng API key cho /api/v1
+	APIKeysFile string // File chứa API key tĩnh dạng name:scopes:sha256, tùy chọn
+
+	// TLS config
+	TLSCertFile     string // Certificate của server, bật HTTPS khi được đặt
+	TLSKeyFile      string // Private key tương ứng với TLSCertFile
+	TLSClientCAFile string // CA bundle để xác thực client certificate cho /api/v1, tùy chọn
+
+	// Readiness config
+	ReadyMaxRunAge time.Duration // Tuổi tối đa của lần cleanup gần nhất trước khi /ready báo lỗi, 0 = tự tính từ CLEANUP_SCHEDULE
+
+	// History retention config
+	HistoryRetentionDays int    // Số ngày giữ lịch sử cleanup, 0 = không giới hạn
+	HistoryMaxRows       int    // Số kết quả tối đa được giữ lại, 0 = không giới hạn
+	MaintenanceSchedule  string // Cron schedule cho job bảo trì database
 
 	// Logger config
 	Logger logger.Config
@@ -27,10 +127,61 @@ func (c *Config) String() string {
 	sb.WriteString("----------------\n")
 	// Hide sensitive information
 	sb.WriteString(fmt.Sprintf("TELEGRAM_BOT_TOKEN: %s\n", helper.MaskValue(c.TelegramBotToken)))
-	sb.WriteString(fmt.Sprintf("TELEGRAM_CHAT_ID: %s\n", helper.MaskValue(c.TelegramChatID)))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_CHAT_ID: %s\n", helper.MaskValue(strings.Join(c.TelegramChatIDs, ","))))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_PARSE_MODE: %s\n", c.TelegramParseMode))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_TIMEOUT: %s\n", c.TelegramTimeout))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_MAX_RETRIES: %d\n", c.TelegramMaxRetries))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_API_URL: %s\n", c.TelegramAPIURL))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_COMMANDS_ENABLED: %v\n", c.TelegramCommandsEnabled))
+	sb.WriteString(fmt.Sprintf("TELEGRAM_ALLOWED_IDS: %v\n", c.TelegramAllowedIDs))
+	sb.WriteString(fmt.Sprintf("SLACK_WEBHOOK_URL: %s\n", helper.MaskValue(c.SlackWebhookURL)))
+	sb.WriteString(fmt.Sprintf("WEBHOOK_URLS: %s\n", helper.MaskValue(strings.Join(c.WebhookURLs, ","))))
+	sb.WriteString(fmt.Sprintf("WEBHOOK_SECRET: %s\n", helper.MaskValue(c.WebhookSecret)))
+	sb.WriteString(fmt.Sprintf("WEBHOOK_TIMEOUT: %s\n", c.WebhookTimeout))
+	sb.WriteString(fmt.Sprintf("WEBHOOK_MAX_RETRIES: %d\n", c.WebhookMaxRetries))
+	sb.WriteString(fmt.Sprintf("SMTP_HOST: %s\n", c.SMTPHost))
+	sb.WriteString(fmt.Sprintf("SMTP_PORT: %s\n", c.SMTPPort))
+	sb.WriteString(fmt.Sprintf("SMTP_USERNAME: %s\n", c.SMTPUsername))
+	sb.WriteString(fmt.Sprintf("SMTP_PASSWORD: %s\n", helper.MaskValue(c.SMTPPassword)))
+	sb.WriteString(fmt.Sprintf("SMTP_TLS: %s\n", c.SMTPTLS))
+	sb.WriteString(fmt.Sprintf("EMAIL_FROM: %s\n", c.EmailFrom))
+	sb.WriteString(fmt.Sprintf("EMAIL_TO: %s\n", strings.Join(c.EmailTo, ",")))
+	sb.WriteString(fmt.Sprintf("EMAIL_MODE: %s\n", c.EmailMode))
+	sb.WriteString(fmt.Sprintf("EMAIL_DIGEST_SCHEDULE: %s\n", c.EmailDigestSchedule))
+	sb.WriteString(fmt.Sprintf("EMAIL_DIGEST_PERIOD: %s\n", c.EmailDigestPeriod))
+	sb.WriteString(fmt.Sprintf("NOTIFY_LANGUAGE: %s\n", c.NotifyLanguage))
+	sb.WriteString(fmt.Sprintf("NOTIFY_TIMEZONE: %s\n", c.NotifyTimezone))
+	sb.WriteString(fmt.Sprintf("NOTIFY_TEMPLATE_DIR: %s\n", c.NotifyTemplateDir))
+	sb.WriteString(fmt.Sprintf("NOTIFY_OUTBOX_MAX_ATTEMPTS: %d\n", c.NotifyOutboxMaxAttempts))
+	sb.WriteString(fmt.Sprintf("ALERTMANAGER_URL: %s\n", helper.MaskValue(strings.Join(c.AlertmanagerURLs, ","))))
+	sb.WriteString(fmt.Sprintf("ALERTMANAGER_TIMEOUT: %s\n", c.AlertmanagerTimeout))
+	sb.WriteString(fmt.Sprintf("ALERTMANAGER_FAILURE_THRESHOLD: %d\n", c.AlertmanagerFailureThreshold))
+	sb.WriteString(fmt.Sprintf("ALERTMANAGER_STALE_AFTER: %s\n", c.AlertmanagerStaleAfter))
+	sb.WriteString(fmt.Sprintf("NOTIFY_RETRY_BACKOFF: %s\n", c.NotifyRetryBackoff))
+	sb.WriteString(fmt.Sprintf("DISK_PRESSURE_PATH: %s\n", c.DiskPressurePath))
+	sb.WriteString(fmt.Sprintf("DISK_PRESSURE_THRESHOLD: %g%%\n", c.DiskPressureThreshold))
+	for _, channel := range notification.Channels {
+		sb.WriteString(fmt.Sprintf("%s rules: %s\n", strings.ToUpper(channel), c.NotificationRules[channel]))
+	}
 	sb.WriteString(fmt.Sprintf("CLEANUP_SCHEDULE: %s\n", c.CleanupSchedule))
 	sb.WriteString(fmt.Sprintf("HTTP_PORT: %s\n", c.HTTPPort))
+	sb.WriteString(fmt.Sprintf("HTTP_SOCKET_PATH: %s\n", c.HTTPSocketPath))
+	sb.WriteString(fmt.Sprintf("HTTP_SOCKET_MODE: %#o\n", c.HTTPSocketMode))
 	sb.WriteString(fmt.Sprintf("SQLITE_DB_PATH: %s\n", c.SQLiteDBPath))
+	sb.WriteString(fmt.Sprintf("RESULT_STORE: %s\n", c.ResultStore))
+	sb.WriteString(fmt.Sprintf("POSTGRES_DSN: %s\n", helper.MaskValue(c.PostgresDSN)))
+	sb.WriteString(fmt.Sprintf("BACKUP_DIR: %s\n", c.BackupDir))
+	sb.WriteString(fmt.Sprintf("NODE_NAME: %s\n", c.NodeName))
+	sb.WriteString(fmt.Sprintf("HOST_LABELS: %s\n", helper.FormatLabels(c.HostLabels)))
+	sb.WriteString(fmt.Sprintf("AUTH_ENABLED: %v\n", c.AuthEnabled))
+	sb.WriteString(fmt.Sprintf("API_KEYS_FILE: %s\n", c.APIKeysFile))
+	sb.WriteString(fmt.Sprintf("TLS_CERT_FILE: %s\n", c.TLSCertFile))
+	sb.WriteString(fmt.Sprintf("TLS_KEY_FILE: %s\n", c.TLSKeyFile))
+	sb.WriteString(fmt.Sprintf("TLS_CLIENT_CA_FILE: %s\n", c.TLSClientCAFile))
+	sb.WriteString(fmt.Sprintf("READY_MAX_RUN_AGE: %s\n", c.ReadyMaxRunAge))
+	sb.WriteString(fmt.Sprintf("HISTORY_RETENTION_DAYS: %d days\n", c.HistoryRetentionDays))
+	sb.WriteString(fmt.Sprintf("HISTORY_MAX_ROWS: %d\n", c.HistoryMaxRows))
+	sb.WriteString(fmt.Sprintf("MAINTENANCE_SCHEDULE: %s\n", c.MaintenanceSchedule))
 	sb.WriteString("\nLogger Configuration:\n")
 	sb.WriteString("--------------------\n")
 	sb.WriteString(fmt.Sprintf("LOG_LEVEL: %s\n", c.Logger.Level))
@@ -50,7 +201,37 @@ func LoadConfig() (*Config, error) {
 	// Set defaults
 	viper.SetDefault("CLEANUP_SCHEDULE", "0 0 * * *")
 	viper.SetDefault("HTTP_PORT", "8080")
+	viper.SetDefault("HTTP_SOCKET_MODE", "0660")
+	viper.SetDefault("TELEGRAM_PARSE_MODE", notification.TelegramParseModeText)
+	viper.SetDefault("TELEGRAM_TIMEOUT", "10s")
+	viper.SetDefault("TELEGRAM_MAX_RETRIES", 3)
+	viper.SetDefault("TELEGRAM_API_URL", "https://api.telegram.org")
+	viper.SetDefault("TELEGRAM_COMMANDS_ENABLED", false)
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_MAX_RETRIES", 3)
+	viper.SetDefault("ALERTMANAGER_TIMEOUT", "10s")
+	viper.SetDefault("ALERTMANAGER_FAILURE_THRESHOLD", 0)
+	viper.SetDefault("ALERTMANAGER_STALE_AFTER", 0) // 0 = tự tính từ CLEANUP_SCHEDULE
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_TLS", notification.EmailTLSStartTLS)
+	viper.SetDefault("EMAIL_MODE", EmailModeRun)
+	viper.SetDefault("EMAIL_DIGEST_SCHEDULE", "0 8 * * 1") // Sáng thứ Hai hằng tuần
+	viper.SetDefault("EMAIL_DIGEST_PERIOD", "168h")
+	viper.SetDefault("NOTIFY_LANGUAGE", notification.LanguageEnglish)
+	viper.SetDefault("NOTIFY_OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("NOTIFY_RETRY_BACKOFF", "30s")
+	viper.SetDefault("DISK_PRESSURE_PATH", "/var/lib/containerd")
+	viper.SetDefault("DISK_PRESSURE_THRESHOLD", 85)
 	viper.SetDefault("SQLITE_DB_PATH", "/var/lib/image-cleanup/cleanup.db") // Mặc định cho SQLite database
+	viper.SetDefault("RESULT_STORE", ResultStoreSQLite)
+	viper.SetDefault("BACKUP_DIR", "/var/lib/image-cleanup/backups")
+	viper.SetDefault("AUTH_ENABLED", true)   // HTTP_PORT lắng nghe trên mọi interface nên mặc định bắt buộc API key
+	viper.SetDefault("READY_MAX_RUN_AGE", 0) // 0 = tự tính từ CLEANUP_SCHEDULE
+
+	// History retention defaults
+	viper.SetDefault("HISTORY_RETENTION_DAYS", 0) // unlimited; pruning is opt-in
+	viper.SetDefault("HISTORY_MAX_ROWS", 0)       // unlimited
+	viper.SetDefault("MAINTENANCE_SCHEDULE", "30 3 * * *")
 
 	// Logger defaults
 	viper.SetDefault("LOG_LEVEL", "info")
@@ -63,19 +244,74 @@ func LoadConfig() (*Config, error) {
 	// Read config file
 	err := viper.ReadInConfig()
 	if err != nil {
-		fmt.Printf("Error reading config file: %v\n", err)
-		fmt.Println("Using environment variables and defaults")
+		fmt.Fprintf(os.Stderr, "Error reading config file: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Using environment variables and defaults")
 	} else {
-		fmt.Printf("Using config file: %s\n", viper.ConfigFileUsed())
+		fmt.Fprintf(os.Stderr, "Using config file: %s\n", viper.ConfigFileUsed())
 	}
 
 	// Create config structure
 	config := &Config{
 		TelegramBotToken: viper.GetString("TELEGRAM_BOT_TOKEN"),
-		TelegramChatID:   viper.GetString("TELEGRAM_CHAT_ID"),
+		TelegramChatIDs:  helper.SplitList(viper.GetString("TELEGRAM_CHAT_ID")),
 		CleanupSchedule:  viper.GetString("CLEANUP_SCHEDULE"),
 		HTTPPort:         viper.GetString("HTTP_PORT"),
+		HTTPSocketPath:   viper.GetString("HTTP_SOCKET_PATH"),
 		SQLiteDBPath:     viper.GetString("SQLITE_DB_PATH"),
+		ResultStore:      strings.ToLower(viper.GetString("RESULT_STORE")),
+		PostgresDSN:      viper.GetString("POSTGRES_DSN"),
+		BackupDir:        viper.GetString("BACKUP_DIR"),
+		NodeName:         viper.GetString("NODE_NAME"),
+		AuthEnabled:      viper.GetBool("AUTH_ENABLED"),
+		APIKeysFile:      viper.GetString("API_KEYS_FILE"),
+		TLSCertFile:      viper.GetString("TLS_CERT_FILE"),
+		TLSKeyFile:       viper.GetString("TLS_KEY_FILE"),
+		TLSClientCAFile:  viper.GetString("TLS_CLIENT_CA_FILE"),
+		ReadyMaxRunAge:   viper.GetDuration("READY_MAX_RUN_AGE"),
+
+		TelegramParseMode:  viper.GetString("TELEGRAM_PARSE_MODE"),
+		TelegramTimeout:    viper.GetDuration("TELEGRAM_TIMEOUT"),
+		TelegramMaxRetries: viper.GetInt("TELEGRAM_MAX_RETRIES"),
+		TelegramAPIURL:     strings.TrimRight(viper.GetString("TELEGRAM_API_URL"), "/"),
+
+		TelegramCommandsEnabled: viper.GetBool("TELEGRAM_COMMANDS_ENABLED"),
+
+		SlackWebhookURL:   viper.GetString("SLACK_WEBHOOK_URL"),
+		WebhookURLs:       helper.SplitList(viper.GetString("WEBHOOK_URLS")),
+		WebhookSecret:     viper.GetString("WEBHOOK_SECRET"),
+		WebhookTimeout:    viper.GetDuration("WEBHOOK_TIMEOUT"),
+		WebhookMaxRetries: viper.GetInt("WEBHOOK_MAX_RETRIES"),
+
+		AlertmanagerURLs:             helper.SplitList(viper.GetString("ALERTMANAGER_URL")),
+		AlertmanagerTimeout:          viper.GetDuration("ALERTMANAGER_TIMEOUT"),
+		AlertmanagerFailureThreshold: viper.GetInt("ALERTMANAGER_FAILURE_THRESHOLD"),
+		AlertmanagerStaleAfter:       viper.GetDuration("ALERTMANAGER_STALE_AFTER"),
+
+		SMTPHost:            viper.GetString("SMTP_HOST"),
+		SMTPPort:            viper.GetString("SMTP_PORT"),
+		SMTPUsername:        viper.GetString("SMTP_USERNAME"),
+		SMTPPassword:        viper.GetString("SMTP_PASSWORD"),
+		SMTPTLS:             strings.ToLower(viper.GetString("SMTP_TLS")),
+		EmailFrom:           viper.GetString("EMAIL_FROM"),
+		EmailTo:             helper.SplitList(viper.GetString("EMAIL_TO")),
+		EmailMode:           strings.ToLower(viper.GetString("EMAIL_MODE")),
+		EmailDigestSchedule: viper.GetString("EMAIL_DIGEST_SCHEDULE"),
+		EmailDigestPeriod:   viper.GetDuration("EMAIL_DIGEST_PERIOD"),
+
+		NotifyLanguage:    strings.ToLower(viper.GetString("NOTIFY_LANGUAGE")),
+		NotifyTimezone:    viper.GetString("NOTIFY_TIMEZONE"),
+		NotifyTemplateDir: viper.GetString("NOTIFY_TEMPLATE_DIR"),
+
+		NotifyOutboxMaxAttempts: viper.GetInt("NOTIFY_OUTBOX_MAX_ATTEMPTS"),
+		NotifyRetryBackoff:      viper.GetDuration("NOTIFY_RETRY_BACKOFF"),
+
+		DiskPressurePath:      viper.GetString("DISK_PRESSURE_PATH"),
+		DiskPressureThreshold: viper.GetFloat64("DISK_PRESSURE_THRESHOLD"),
+
+		HistoryRetentionDays: viper.GetInt("HISTORY_RETENTION_DAYS"),
+		HistoryMaxRows:       viper.GetInt("HISTORY_MAX_ROWS"),
+		MaintenanceSchedule:  viper.GetString("MAINTENANCE_SCHEDULE"),
+
 		Logger: logger.Config{
 			Level:      viper.GetString("LOG_LEVEL"),
 			LogDir:     viper.GetString("LOG_DIR"),
@@ -86,8 +322,176 @@ func LoadConfig() (*Config, error) {
 		},
 	}
 
+	hostLabels, err := helper.ParseLabels(viper.GetString("HOST_LABELS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HOST_LABELS: %w", err)
+	}
+	config.HostLabels = hostLabels
+
+	// HTTP_PORT=0 tắt listener TCP, khi đó bắt buộc phải có HTTP_SOCKET_PATH
+	if config.HTTPPort == "0" {
+		config.HTTPPort = ""
+	}
+	if config.HTTPPort == "" && config.HTTPSocketPath == "" {
+		return nil, fmt.Errorf("HTTP_SOCKET_PATH is required when HTTP_PORT is disabled")
+	}
+	socketMode, err := strconv.ParseUint(viper.GetString("HTTP_SOCKET_MODE"), 8, 32)
+	if err != nil || socketMode > 0o777 {
+		return nil, fmt.Errorf("invalid HTTP_SOCKET_MODE %q (expected octal permissions such as 0660)", viper.GetString("HTTP_SOCKET_MODE"))
+	}
+	config.HTTPSocketMode = os.FileMode(socketMode)
+
+	switch config.ResultStore {
+	case ResultStoreSQLite:
+	case ResultStorePostgres:
+		if config.PostgresDSN == "" {
+			return nil, fmt.Errorf("POSTGRES_DSN is required when RESULT_STORE=%s", ResultStorePostgres)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported RESULT_STORE %q (expected %s or %s)", config.ResultStore, ResultStoreSQLite, ResultStorePostgres)
+	}
+
+	if config.TelegramBotToken != "" {
+		if len(config.TelegramChatIDs) == 0 {
+			return nil, fmt.Errorf("TELEGRAM_CHAT_ID is required when TELEGRAM_BOT_TOKEN is set")
+		}
+		if _, err := notification.ParseTelegramChats(config.TelegramChatIDs); err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_CHAT_ID: %w", err)
+		}
+	}
+	if !slices.Contains(notification.TelegramParseModes, config.TelegramParseMode) {
+		return nil, fmt.Errorf("unsupported TELEGRAM_PARSE_MODE %q (expected %s)", config.TelegramParseMode, strings.Join(notification.TelegramParseModes, " or "))
+	}
+	if config.TelegramMaxRetries < 0 {
+		return nil, fmt.Errorf("TELEGRAM_MAX_RETRIES must not be negative")
+	}
+	if u, err := url.Parse(config.TelegramAPIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid TELEGRAM_API_URL %q: expected an http(s) URL", config.TelegramAPIURL)
+	}
+	for _, value := range helper.SplitList(viper.GetString("TELEGRAM_ALLOWED_IDS")) {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_ALLOWED_IDS entry %q: expected a numeric chat or user ID", value)
+		}
+		config.TelegramAllowedIDs = append(config.TelegramAllowedIDs, id)
+	}
+	if config.TelegramCommandsEnabled {
+		if config.TelegramBotToken == "" {
+			return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is required when TELEGRAM_COMMANDS_ENABLED=true")
+		}
+		// Bot có thể xóa image, nên không cho phép ai cũng gửi lệnh
+		if len(config.TelegramAllowedIDs) == 0 {
+			return nil, fmt.Errorf("TELEGRAM_ALLOWED_IDS is required when TELEGRAM_COMMANDS_ENABLED=true")
+		}
+	}
+
+	if len(config.WebhookURLs) > 0 && config.WebhookSecret == "" {
+		return nil, fmt.Errorf("WEBHOOK_SECRET is required when WEBHOOK_URLS is set")
+	}
+
+	for _, value := range config.AlertmanagerURLs {
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid ALERTMANAGER_URL %q: expected an http(s) URL", helper.MaskValue(value))
+		}
+	}
+	if config.AlertmanagerTimeout <= 0 {
+		return nil, fmt.Errorf("ALERTMANAGER_TIMEOUT must be positive")
+	}
+	if config.AlertmanagerFailureThreshold < 0 {
+		return nil, fmt.Errorf("ALERTMANAGER_FAILURE_THRESHOLD must not be negative")
+	}
+	if config.AlertmanagerStaleAfter < 0 {
+		return nil, fmt.Errorf("ALERTMANAGER_STALE_AFTER must not be negative")
+	}
+
+	if !slices.Contains(notification.Languages, config.NotifyLanguage) {
+		return nil, fmt.Errorf("unsupported NOTIFY_LANGUAGE %q (expected %s)", config.NotifyLanguage, strings.Join(notification.Languages, " or "))
+	}
+	if _, err := time.LoadLocation(config.NotifyTimezone); err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_TIMEZONE %q: %w", config.NotifyTimezone, err)
+	}
+
+	if config.NotifyOutboxMaxAttempts < 1 {
+		return nil, fmt.Errorf("NOTIFY_OUTBOX_MAX_ATTEMPTS must be at least 1")
+	}
+	if config.NotifyRetryBackoff <= 0 {
+		return nil, fmt.Errorf("NOTIFY_RETRY_BACKOFF must be positive")
+	}
+
+	if config.DiskPressureThreshold < 0 || config.DiskPressureThreshold > 100 {
+		return nil, fmt.Errorf("DISK_PRESSURE_THRESHOLD must be between 0 and 100")
+	}
+	if config.DiskPressureThreshold > 0 && config.DiskPressurePath == "" {
+		return nil, fmt.Errorf("DISK_PRESSURE_PATH is required when DISK_PRESSURE_THRESHOLD is set")
+	}
+
+	config.NotificationRules = make(map[string]notificationDomain.Rule)
+	for _, channel := range notification.Channels {
+		rule, err := loadNotificationRule(strings.ToUpper(channel))
+		if err != nil {
+			return nil, err
+		}
+		config.NotificationRules[channel] = rule
+	}
+
+	if config.SMTPHost != "" {
+		if config.EmailFrom == "" || len(config.EmailTo) == 0 {
+			return nil, fmt.Errorf("EMAIL_FROM and EMAIL_TO are required when SMTP_HOST is set")
+		}
+		switch config.SMTPTLS {
+		case notification.EmailTLSStartTLS, notification.EmailTLSImplicit, notification.EmailTLSNone:
+		default:
+			return nil, fmt.Errorf("unsupported SMTP_TLS %q (expected %s, %s or %s)", config.SMTPTLS,
+				notification.EmailTLSStartTLS, notification.EmailTLSImplicit, notification.EmailTLSNone)
+		}
+		switch config.EmailMode {
+		case EmailModeRun, EmailModeDigest, EmailModeBoth:
+		default:
+			return nil, fmt.Errorf("unsupported EMAIL_MODE %q (expected %s, %s or %s)", config.EmailMode, EmailModeRun, EmailModeDigest, EmailModeBoth)
+		}
+		if config.EmailMode != EmailModeRun && config.EmailDigestPeriod <= 0 {
+			return nil, fmt.Errorf("EMAIL_DIGEST_PERIOD must be positive")
+		}
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if config.TLSClientCAFile != "" && config.TLSCertFile == "" {
+		return nil, fmt.Errorf("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
 	// Đảm bảo thư mục cho SQLite database tồn tại
 	helper.EnsureDirectoryExists(helper.GetParentDirectory(config.SQLiteDBPath))
 
 	return config, nil
 }
+
+// loadNotificationRule đọc rule của một kênh từ các biến có tiền tố prefix
+func loadNotificationRule(prefix string) (notificationDomain.Rule, error) {
+	var events []notificationDomain.EventType
+	for _, event := range helper.SplitList(strings.ToLower(viper.GetString(prefix + "_EVENTS"))) {
+		events = append(events, notificationDomain.EventType(event))
+	}
+
+	rule := notificationDomain.Rule{
+		Events:       events,
+		OnlyFailures: viper.GetBool(prefix + "_ONLY_FAILURES"),
+		MinRemoved:   viper.GetInt(prefix + "_MIN_REMOVED"),
+		Hosts:        helper.SplitList(viper.GetString(prefix + "_HOSTS")),
+	}
+	if rule.MinRemoved < 0 {
+		return rule, fmt.Errorf("%s_MIN_REMOVED must not be negative", prefix)
+	}
+	// Validate kiểm tra cả <KÊNH>_EVENTS và <KÊNH>_HOSTS
+	if err := rule.Validate(); err != nil {
+		return rule, fmt.Errorf("invalid %s rule: %w", prefix, err)
+	}
+
+	quietHours, err := notificationDomain.ParseQuietHours(viper.GetString(prefix + "_QUIET_HOURS"))
+	if err != nil {
+		return rule, fmt.Errorf("invalid %s_QUIET_HOURS: %w", prefix, err)
+	}
+	rule.QuietHours = quietHours
+	return rule, nil
+}
@@ -0,0 +1,21 @@
+package disk
+
+// Usage là dung lượng của filesystem chứa một đường dẫn
+type Usage struct {
+	Path       string
+	UsedBytes  uint64
+	TotalBytes uint64
+}
+
+// UsedPercent trả về phần trăm đã dùng, 0 khi không biết tổng dung lượng
+func (u Usage) UsedPercent() float64 {
+	if u.TotalBytes == 0 {
+		return 0
+	}
+	return float64(u.UsedBytes) / float64(u.TotalBytes) * 100
+}
+
+// UsageReader đọc dung lượng filesystem, ví dụ thư mục lưu image của container runtime
+type UsageReader interface {
+	Usage(path string) (Usage, error)
+}
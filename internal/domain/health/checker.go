@@ -0,0 +1,10 @@
+package health
+
+import "context"
+
+// Checker kiểm tra một dependency của service. Check trả về chi tiết ngắn gọn khi thành công
+// và lỗi mô tả nguyên nhân khi dependency không sẵn sàng.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) (string, error)
+}
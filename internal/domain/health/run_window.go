@@ -0,0 +1,21 @@
+package health
+
+import "time"
+
+// RunWindow là khoảng thời gian mà lần cleanup gần nhất phải nằm trong, theo lịch cleanup hiện tại
+type RunWindow struct {
+	MaxAge time.Duration // Tuổi tối đa của lần chạy gần nhất
+	Since  time.Time     // Lúc lịch được đổi hoặc chạy lại; tuổi không được tính từ trước mốc này
+	Paused bool          // Cleanup đang tạm dừng nên không chờ lần chạy nào
+}
+
+// RunWindowFunc trả về RunWindow tại thời điểm gọi, để thay đổi lịch qua API có hiệu lực ngay
+type RunWindowFunc func() RunWindow
+
+// Deadline trả về thời điểm muộn nhất cho lần chạy tiếp theo khi lần gần nhất diễn ra lúc last
+func (w RunWindow) Deadline(last time.Time) time.Time {
+	if w.Since.After(last) {
+		last = w.Since
+	}
+	return last.Add(w.MaxAge)
+}
@@ -0,0 +1,12 @@
+package host
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+)
+
+type Identifier interface {
+	// Identify trả về danh tính của node hiện tại. Khi một số thông tin không lấy được,
+	// Host vẫn được trả về với các trường còn lại cùng với lỗi.
+	Identify(ctx context.Context) (models.Host, error)
+}
@@ -9,8 +9,20 @@ type MetricsCollector interface {
 	SetLastCleanupTime(timestamp time.Time)
 	IncCleanupErrors()
 
+	// Database maintenance metrics
+	SetDatabaseSize(bytes int64)
+	AddResultsPruned(count int64)
+
+	// Notification metrics
+	IncNotifications(channel, status string)
+	SetOutboxMessages(status string, count int)
+
 	// HTTP metrics
 	IncHttpRequests(path, method string, status int)
 	IncHttpTimeout(path, method string)
 	IncHttpError(path, method string, status int, errorType string)
+	IncAuthFailures(path, reason string)
+	ObserveHttpDuration(path, method string, status int, duration time.Duration)
+	IncHttpInFlight()
+	DecHttpInFlight()
 }
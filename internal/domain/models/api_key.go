@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope là quyền được gán cho một API key
+type Scope string
+
+const (
+	ScopeRead    Scope = "read"    // Đọc trạng thái, lịch sử, export
+	ScopeTrigger Scope = "trigger" // Kích hoạt cleanup
+	ScopeAdmin   Scope = "admin"   // Backup và các thao tác quản trị, bao gồm mọi scope khác
+)
+
+// ParseScopes đọc danh sách scope dạng "read,trigger"
+func ParseScopes(value string) ([]Scope, error) {
+	var scopes []Scope
+	seen := make(map[Scope]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		scope := Scope(strings.ToLower(strings.TrimSpace(part)))
+		if scope == "" || seen[scope] {
+			continue
+		}
+
+		switch scope {
+		case ScopeRead, ScopeTrigger, ScopeAdmin:
+		default:
+			return nil, fmt.Errorf("unknown scope %q (expected %s, %s or %s)", scope, ScopeRead, ScopeTrigger, ScopeAdmin)
+		}
+
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// FormatScopes nối các scope thành chuỗi "read,trigger"
+func FormatScopes(scopes []Scope) string {
+	parts := make([]string, len(scopes))
+	for i, scope := range scopes {
+		parts[i] = string(scope)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Nguồn của API key
+const (
+	APIKeySourceDatabase = "database"
+	APIKeySourceFile     = "file"
+
+	// FileAPIKeyIDPrefix đứng trước tên của key đọc từ file để tạo ID
+	FileAPIKeyIDPrefix = "file:"
+)
+
+// APIKey là một API key đã đăng ký. Chỉ lưu hash SHA-256 của key, không lưu key gốc.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Vài ký tự đầu của key để nhận diện
+	Hash       string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	Source     string     `json:"source"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope kiểm tra key có scope yêu cầu không; admin bao gồm mọi scope
+func (k APIKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoked cho biết key đã bị thu hồi chưa
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
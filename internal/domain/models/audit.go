@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Kết quả của một lời gọi API được ghi audit
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditMethodTelegram là method của lệnh Telegram bot được ghi audit; route là lệnh (vd. /cleanup),
+// actor là telegram:<user_id> và chat ID nằm trong query params
+const AuditMethodTelegram = "TELEGRAM"
+
+// AuditParams là tham số của lời gọi API: path params, query string và body (đã cắt ngắn)
+type AuditParams struct {
+	Path  map[string]string `json:"path,omitempty"`
+	Query map[string]string `json:"query,omitempty"`
+	Body  string            `json:"body,omitempty"`
+}
+
+// AuditEntry là một bản ghi audit của lời gọi API làm thay đổi trạng thái
+type AuditEntry struct {
+	ID         int64       `json:"id"`
+	Time       time.Time   `json:"time"`
+	Actor      string      `json:"actor"`
+	APIKeyID   string      `json:"api_key_id,omitempty"`
+	SourceIP   string      `json:"source_ip"`
+	RequestID  string      `json:"request_id,omitempty"`
+	Method     string      `json:"method"`
+	Route      string      `json:"route"`
+	Path       string      `json:"path"`
+	Params     AuditParams `json:"params"`
+	Status     int         `json:"status"`
+	Outcome    string      `json:"outcome"`
+	Error      string      `json:"error,omitempty"`
+	DurationMs int64       `json:"duration_ms"`
+}
+
+// AuditArchive ghi lại một lần chuyển các bản ghi audit cũ ra file. Chỉ các bản ghi nằm trong một
+// archive (id <= UpToID và time < Before) mới được xóa khỏi audit_log.
+type AuditArchive struct {
+	ID         int64     `json:"id"`
+	ArchivedAt time.Time `json:"archived_at"`
+	Before     time.Time `json:"before"`
+	UpToID     int64     `json:"up_to_id"`
+	Entries    int       `json:"entries"`
+	Path       string    `json:"path"`
+	SHA256     string    `json:"sha256"`
+}
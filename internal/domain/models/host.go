@@ -0,0 +1,25 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Host mô tả danh tính của node thực hiện cleanup
+type Host struct {
+	Hostname       string            `json:"hostname"`
+	IPv4           []string          `json:"ipv4"`
+	IPv6           []string          `json:"ipv6"`
+	MachineID      string            `json:"machine_id"`
+	NodeName       string            `json:"node_name"`
+	RuntimeVersion string            `json:"runtime_version"`
+	Labels         map[string]string `json:"labels"`
+}
+
+// String trả về định dạng "Host: ...\nIP(s): ..." dùng trong thông báo và cột host_info cũ
+func (h Host) String() string {
+	if h.Hostname == "" {
+		return "Unknown host"
+	}
+	return fmt.Sprintf("Host: %s\nIP(s): %s", h.Hostname, strings.Join(h.IPv4, ", "))
+}
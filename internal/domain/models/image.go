@@ -3,5 +3,6 @@ package models
 type Image struct {
     ID      string
     Tags    []string
+    Size    uint64 // bytes, theo crictl images
     InUse   bool
 }
\ No newline at end of file
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Trạng thái của một thông báo trong outbox
+const (
+	OutboxPending = "pending" // Đang chờ gửi lại
+	OutboxFailed  = "failed"  // Dead letter: đã hết số lần thử, chỉ gửi lại khi được yêu cầu qua API
+)
+
+// OutboxMessage là một sự kiện gửi tới một kênh không thành công, được lưu lại để gửi lại sau
+type OutboxMessage struct {
+	ID          string          `json:"id"`
+	Channel     string          `json:"channel"`
+	Target      string          `json:"target,omitempty"` // Đích bị lỗi của kênh (chat Telegram, URL webhook), rỗng = cả kênh
+	DeliveryID  string          `json:"delivery_id"`      // Giữ nguyên qua mọi lần gửi lại để bên nhận loại bỏ bản trùng
+	EventType   string          `json:"event_type"`
+	Payload     json.RawMessage `json:"payload"` // Sự kiện dạng JSON
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// OutboxStats là số thông báo trong outbox theo trạng thái
+type OutboxStats struct {
+	Pending int `json:"pending"`
+	Failed  int `json:"failed"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Tên các job cron của service
+const (
+	ScheduleCleanup     = "cleanup"
+	ScheduleMaintenance = "maintenance"
+	ScheduleDigest      = "digest"
+)
+
+// Schedule là lịch chạy của một job đã được thay đổi qua API, được lưu lại để giữ nguyên sau khi restart
+type Schedule struct {
+	Name       string    `json:"name"`
+	Expression string    `json:"expression"`
+	Paused     bool      `json:"paused"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UpdatedBy  string    `json:"updated_by"`
+}
@@ -0,0 +1,21 @@
+package notification
+
+import (
+	"context"
+	"time"
+)
+
+// Alert là một cảnh báo theo định dạng của Alertmanager API v2. Alertmanager nhận diện alert theo
+// Labels, nên Labels phải giống nhau giữa các lần gửi lại và lần resolve.
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"` // Trong quá khứ nghĩa là alert đã được resolve
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// AlertSender gửi danh sách alert tới Alertmanager
+type AlertSender interface {
+	SendAlerts(ctx context.Context, alerts []Alert) error
+}
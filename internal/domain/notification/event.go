@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+	"time"
+)
+
+// EventType là loại sự kiện gửi tới các kênh thông báo, cũng là tên template tương ứng
+type EventType string
+
+const (
+	EventRunStarted   EventType = "run_started"
+	EventRunCompleted EventType = "run_completed"
+	EventRunFailed    EventType = "run_failed"
+	EventDiskPressure EventType = "disk_pressure"
+	EventImageDeleted EventType = "image_deleted"
+)
+
+// EventTypes liệt kê mọi loại sự kiện
+var EventTypes = []EventType{EventRunStarted, EventRunCompleted, EventRunFailed, EventDiskPressure, EventImageDeleted}
+
+// Event là sự kiện có kiểu; mỗi kênh tự định dạng theo loại sự kiện
+type Event interface {
+	Type() EventType
+	// Origin là node phát sinh sự kiện
+	Origin() models.Host
+	// Failure cho biết sự kiện cần được chú ý, được gửi cả trong giờ yên lặng
+	Failure() bool
+}
+
+// EventNotifier là kênh nhận sự kiện có kiểu; ctx cho phép hủy việc gửi
+type EventNotifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// RunStarted được gửi khi một lần cleanup bắt đầu
+type RunStarted struct {
+	Host      models.Host
+	StartTime time.Time
+	RequestID string
+}
+
+// RunFailed được gửi khi lần cleanup dừng vì lỗi trước khi xử lý được image
+type RunFailed struct {
+	Host      models.Host
+	StartTime time.Time
+	Error     string
+	RequestID string
+}
+
+// DiskPressure được gửi khi filesystem chứa image vẫn đầy quá ngưỡng sau cleanup
+type DiskPressure struct {
+	Host             models.Host
+	Path             string
+	UsedBytes        uint64
+	TotalBytes       uint64
+	UsedPercent      float64
+	ThresholdPercent float64
+}
+
+// ImageDeleted được gửi cho từng image đã xóa
+type ImageDeleted struct {
+	Host      models.Host
+	ImageID   string
+	Tags      []string
+	SizeBytes uint64
+	RequestID string
+}
+
+func (e RunStarted) Type() EventType     { return EventRunStarted }
+func (e RunStarted) Origin() models.Host { return e.Host }
+func (e RunStarted) Failure() bool       { return false }
+
+// Report là sự kiện run completed; lần chạy có image xóa lỗi được coi là lỗi
+func (r Report) Type() EventType     { return EventRunCompleted }
+func (r Report) Origin() models.Host { return r.Host }
+func (r Report) Failure() bool       { return r.Failed > 0 }
+
+func (e RunFailed) Type() EventType     { return EventRunFailed }
+func (e RunFailed) Origin() models.Host { return e.Host }
+func (e RunFailed) Failure() bool       { return true }
+
+func (e DiskPressure) Type() EventType     { return EventDiskPressure }
+func (e DiskPressure) Origin() models.Host { return e.Host }
+func (e DiskPressure) Failure() bool       { return true }
+
+func (e ImageDeleted) Type() EventType     { return EventImageDeleted }
+func (e ImageDeleted) Origin() models.Host { return e.Host }
+func (e ImageDeleted) Failure() bool       { return false }
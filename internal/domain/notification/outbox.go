@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Outbox lưu lần gửi thất bại tới một kênh để gửi lại sau.
+// target là đích bị lỗi của kênh (rỗng = cả kênh), deliveryID được dùng lại ở mọi lần gửi lại.
+type Outbox interface {
+	Enqueue(ctx context.Context, channel, target, deliveryID string, event Event, cause error) error
+}
+
+// ChannelNotifier gửi sự kiện tới đúng một kênh theo tên, bỏ qua rule của kênh; dùng khi gửi lại từ outbox.
+// target khác rỗng thì chỉ gửi tới đích đó của kênh.
+type ChannelNotifier interface {
+	NotifyChannel(ctx context.Context, channel, target string, event Event) error
+}
+
+// TargetNotifier là kênh gửi tới nhiều đích (chat Telegram, URL webhook) và gửi lại được tới từng đích
+type TargetNotifier interface {
+	EventNotifier
+	NotifyTarget(ctx context.Context, target string, event Event) error
+}
+
+// TargetError là lỗi gửi tới một đích của kênh; kênh nhiều đích trả về một TargetError cho mỗi đích bị lỗi
+type TargetError struct {
+	Target string
+	Err    error
+}
+
+func (e *TargetError) Error() string { return e.Err.Error() }
+func (e *TargetError) Unwrap() error { return e.Err }
+
+// FailedTargets trả về các đích bị lỗi trong err. ok = false khi có lỗi không gắn với đích nào,
+// khi đó cả kênh phải được gửi lại.
+func FailedTargets(err error) (targets []*TargetError, ok bool) {
+	if targetErr, isTarget := err.(*TargetError); isTarget {
+		return []*TargetError{targetErr}, true
+	}
+	joined, isJoined := err.(interface{ Unwrap() []error })
+	if !isJoined {
+		return nil, false
+	}
+	for _, e := range joined.Unwrap() {
+		found, ok := FailedTargets(e)
+		if !ok {
+			return nil, false
+		}
+		targets = append(targets, found...)
+	}
+	return targets, len(targets) > 0
+}
+
+type deliveryIDKey struct{}
+
+// WithDeliveryID gắn ID của lần gửi vào ctx; kênh có ID riêng cho mỗi lần gửi (webhook) dùng lại ID này
+// để lần gửi lại từ outbox mang cùng ID với lần gửi đầu tiên
+func WithDeliveryID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, deliveryIDKey{}, id)
+}
+
+// DeliveryIDFromContext trả về ID lần gửi đã gắn bởi WithDeliveryID, rỗng nếu không có
+func DeliveryIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(deliveryIDKey{}).(string)
+	return id
+}
+
+// DecodeEvent đọc lại sự kiện đã được lưu dạng JSON theo loại sự kiện
+func DecodeEvent(eventType EventType, payload []byte) (Event, error) {
+	switch eventType {
+	case EventRunStarted:
+		return decodeAs[RunStarted](eventType, payload)
+	case EventRunCompleted:
+		return decodeAs[Report](eventType, payload)
+	case EventRunFailed:
+		return decodeAs[RunFailed](eventType, payload)
+	case EventDiskPressure:
+		return decodeAs[DiskPressure](eventType, payload)
+	case EventImageDeleted:
+		return decodeAs[ImageDeleted](eventType, payload)
+	}
+	return nil, fmt.Errorf("unknown event %q", eventType)
+}
+
+func decodeAs[T Event](eventType EventType, payload []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", eventType, err)
+	}
+	return event, nil
+}
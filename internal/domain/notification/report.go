@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+)
+
+// Report là kết quả có cấu trúc của một lần cleanup, để mỗi kênh tự định dạng theo cách riêng
+type Report struct {
+	Host           models.Host
+	HostInfo       string
+	StartTime      time.Time
+	EndTime        time.Time
+	Duration       time.Duration
+	Total          int
+	Removed        int
+	Skipped        int // Gồm cả các image xóa lỗi
+	Failed         int // Số image xóa lỗi
+	ReclaimedBytes uint64
+	RequestID      string
+
+	// Message là nội dung dạng text cho các kênh chỉ gửi được chuỗi
+	Message string
+}
+
+// ReportNotifier là kênh định dạng thông báo từ Report thay vì dùng Message có sẵn
+type ReportNotifier interface {
+	Notifier
+	SendReport(report Report) error
+}
+
+// Send gửi report qua SendReport khi kênh hỗ trợ, ngược lại gửi Message
+func Send(notifier Notifier, report Report) error {
+	if rn, ok := notifier.(ReportNotifier); ok {
+		return rn.SendReport(report)
+	}
+	return notifier.SendNotification(report.Message)
+}
+
+// Digest tổng hợp các lần cleanup của một node trong một khoảng thời gian
+type Digest struct {
+	Host models.Host
+	From time.Time
+	To   time.Time
+
+	Runs          int
+	Total         int
+	Removed       int
+	Skipped       int
+	TotalDuration time.Duration
+	LongestRun    time.Duration
+
+	// Results là các lần chạy gần nhất trong khoảng, cũ nhất trước; có thể ít hơn Runs
+	Results []repositories.CleanupResult
+}
+
+// DigestNotifier là kênh gửi được bản tổng hợp định kỳ
+type DigestNotifier interface {
+	SendDigest(digest Digest) error
+}
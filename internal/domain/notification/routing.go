@@ -0,0 +1,172 @@
+package notification
+
+import (
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/pkg/helper"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEvents là các sự kiện một kênh nhận khi không cấu hình Events
+var DefaultEvents = []EventType{EventRunCompleted, EventRunFailed, EventDiskPressure}
+
+// Rule quyết định một kênh có nhận sự kiện hay không; giá trị zero cho qua mọi sự kiện trong DefaultEvents
+type Rule struct {
+	Events       []EventType // Loại sự kiện kênh nhận, rỗng = DefaultEvents
+	OnlyFailures bool        // Chỉ gửi sự kiện lỗi: run failed, disk pressure, lần chạy có image xóa lỗi
+	MinRemoved   int         // Chỉ gửi report đã xóa ít nhất MinRemoved image, 0 = không lọc
+	Hosts        []string    // Pattern (path.Match) theo node name hoặc hostname, rỗng = mọi host
+	QuietHours   QuietHours  // Trong khoảng này chỉ gửi sự kiện lỗi
+}
+
+// Allows trả về false kèm lý do khi sự kiện không được gửi qua kênh tại thời điểm now
+func (r Rule) Allows(event Event, now time.Time) (bool, string) {
+	events := r.Events
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	if !slices.Contains(events, event.Type()) {
+		return false, "event not selected"
+	}
+
+	failed := event.Failure()
+	if r.OnlyFailures && !failed {
+		return false, "no failures"
+	}
+	if report, ok := event.(Report); ok && report.Removed < r.MinRemoved {
+		return false, fmt.Sprintf("removed %d < %d", report.Removed, r.MinRemoved)
+	}
+	if len(r.Hosts) > 0 && !r.MatchesHost(event.Origin()) {
+		return false, "host not selected"
+	}
+	// Lỗi vẫn được báo trong giờ yên lặng
+	if !failed && r.QuietHours.Contains(now) {
+		return false, "quiet hours"
+	}
+	return true, ""
+}
+
+// MatchesHost kiểm tra host có khớp một trong các pattern của Hosts
+func (r Rule) MatchesHost(host models.Host) bool {
+	for _, pattern := range r.Hosts {
+		for _, name := range []string{host.NodeName, host.Hostname} {
+			if name == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String mô tả rule cho log khởi động
+func (r Rule) String() string {
+	var parts []string
+	if len(r.Events) > 0 {
+		names := make([]string, len(r.Events))
+		for i, event := range r.Events {
+			names[i] = string(event)
+		}
+		parts = append(parts, "events "+strings.Join(names, ","))
+	}
+	if r.OnlyFailures {
+		parts = append(parts, "only failures")
+	}
+	if r.MinRemoved > 0 {
+		parts = append(parts, "removed >= "+strconv.Itoa(r.MinRemoved))
+	}
+	if len(r.Hosts) > 0 {
+		parts = append(parts, "hosts "+strings.Join(r.Hosts, ","))
+	}
+	if r.QuietHours.Enabled() {
+		parts = append(parts, "quiet hours "+r.QuietHours.String())
+	}
+	if len(parts) == 0 {
+		return "default events"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Validate kiểm tra loại sự kiện và cú pháp các pattern trong Hosts
+func (r Rule) Validate() error {
+	for _, event := range r.Events {
+		if !slices.Contains(EventTypes, event) {
+			names := make([]string, len(EventTypes))
+			for i, known := range EventTypes {
+				names[i] = string(known)
+			}
+			return fmt.Errorf("unknown event %q (expected one of %s)", event, strings.Join(names, ", "))
+		}
+	}
+	for _, pattern := range r.Hosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid host pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// QuietHours là khoảng giờ trong ngày theo giờ ICT, có thể qua nửa đêm (ví dụ 22:00-07:00)
+type QuietHours struct {
+	Start time.Duration // Tính từ nửa đêm
+	End   time.Duration
+}
+
+// ParseQuietHours đọc chuỗi dạng "HH:MM-HH:MM"; chuỗi rỗng nghĩa là không có giờ yên lặng
+func ParseQuietHours(value string) (QuietHours, error) {
+	if value == "" {
+		return QuietHours{}, nil
+	}
+	startValue, endValue, ok := strings.Cut(value, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: expected HH:MM-HH:MM", value)
+	}
+	start, err := parseClock(strings.TrimSpace(startValue))
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: %w", value, err)
+	}
+	end, err := parseClock(strings.TrimSpace(endValue))
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: %w", value, err)
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Enabled trả về false khi Start trùng End
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+// Contains kiểm tra t (quy về ICT) có nằm trong khoảng [Start, End)
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	local := helper.TimeInICT(t)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+func (q QuietHours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(q.Start) + "-" + clock(q.End)
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"time"
+)
+
+// ErrAPIKeyNotFound được trả về khi không tìm thấy API key
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository lưu trữ API key dùng cho xác thực HTTP API
+type APIKeyRepository interface {
+	// CreateKey lưu key mới (chỉ gồm hash, không có key gốc)
+	CreateKey(ctx context.Context, key models.APIKey) error
+
+	// FindKeyByHash tìm key theo hash SHA-256, trả về ErrAPIKeyNotFound nếu không có
+	FindKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+
+	// ListKeys trả về tất cả key, kể cả key đã thu hồi
+	ListKeys(ctx context.Context) ([]models.APIKey, error)
+
+	// RevokeKey thu hồi key theo ID, trả về ErrAPIKeyNotFound nếu không có key còn hiệu lực
+	RevokeKey(ctx context.Context, id string) error
+
+	// TouchKey cập nhật thời điểm sử dụng gần nhất
+	TouchKey(ctx context.Context, id string, usedAt time.Time) error
+}
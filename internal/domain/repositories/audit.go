@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+	"time"
+)
+
+// AuditQuery là bộ lọc khi đọc audit log; trường rỗng nghĩa là không lọc
+type AuditQuery struct {
+	From    time.Time // Bao gồm
+	To      time.Time // Không bao gồm
+	Actor   string
+	Method  string
+	Outcome string
+}
+
+// AuditRepository lưu audit log dạng chỉ ghi thêm, không cho sửa; bản ghi chỉ được xóa sau khi đã archive
+type AuditRepository interface {
+	// AppendEntry ghi thêm một bản ghi
+	AppendEntry(ctx context.Context, entry models.AuditEntry) error
+
+	// ListEntries trả về các bản ghi theo bộ lọc, mới nhất trước
+	ListEntries(ctx context.Context, query AuditQuery, limit, offset int) ([]models.AuditEntry, error)
+
+	// StreamEntriesBefore duyệt các bản ghi có time < before theo thứ tự id tăng dần
+	StreamEntriesBefore(ctx context.Context, before time.Time, fn func(models.AuditEntry) error) error
+
+	// DeleteArchived lưu archive rồi xóa các bản ghi nó chứa (id <= UpToID và time < Before)
+	// trong cùng một transaction, trả về số bản ghi đã xóa
+	DeleteArchived(ctx context.Context, archive models.AuditArchive) (int64, error)
+}
@@ -0,0 +1,9 @@
+package repositories
+
+import "context"
+
+// BackupRepository định nghĩa việc sao lưu database khi service đang chạy
+type BackupRepository interface {
+	// Backup ghi một bản sao nhất quán của database ra destPath (file chưa tồn tại)
+	Backup(ctx context.Context, destPath string) error
+}
@@ -2,13 +2,19 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
 	"time"
 )
 
+// ErrNoResults được trả về khi không có kết quả cleanup nào khớp
+var ErrNoResults = errors.New("no cleanup results found")
+
 // CleanupResult định nghĩa kết quả của một lần cleanup
 type CleanupResult struct {
 	ID         string        `json:"id"`
 	HostInfo   string        `json:"host_info"`
+	Host       models.Host   `json:"host"`
 	StartTime  time.Time     `json:"start_time"`
 	EndTime    time.Time     `json:"end_time"`
 	Duration   time.Duration `json:"duration"`
@@ -16,6 +22,40 @@ type CleanupResult struct {
 	Removed    int           `json:"removed"`
 	Skipped    int           `json:"skipped"`
 	CreatedAt  time.Time     `json:"created_at"`
+	RequestID  string        `json:"request_id,omitempty"` // X-Request-ID của lời gọi API đã kích hoạt lần chạy, rỗng với lần chạy theo lịch
+}
+
+// Kết quả xóa của một image trong lần cleanup
+const (
+	RemovalOutcomeRemoved = "removed"
+	RemovalOutcomeFailed  = "failed"
+)
+
+// ImageRemoval là sự kiện xóa một image trong một lần cleanup, gắn với kết quả của lần chạy qua RunID.
+// Hostname và NodeName được đọc từ lần chạy tương ứng, không cần điền khi lưu.
+type ImageRemoval struct {
+	RunID     string    `json:"run_id"`
+	RequestID string    `json:"request_id,omitempty"`
+	Hostname  string    `json:"hostname"`
+	NodeName  string    `json:"node_name"`
+	ImageID   string    `json:"image_id"`
+	Tags      []string  `json:"tags"`
+	SizeBytes uint64    `json:"size_bytes"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// ResultQuery lọc kết quả theo khoảng thời gian (start_time) và danh tính host.
+// From là cận dưới (bao gồm), To là cận trên (không bao gồm); giá trị zero nghĩa là không lọc.
+type ResultQuery struct {
+	From time.Time
+	To   time.Time
+
+	Hostname  string
+	NodeName  string
+	MachineID string
+	Labels    map[string]string // tất cả label phải khớp
 }
 
 // CleanupResultRepository định nghĩa interface cho việc lưu trữ kết quả cleanup
@@ -23,12 +63,24 @@ type CleanupResultRepository interface {
 	// SaveResult lưu kết quả của một lần cleanup
 	SaveResult(ctx context.Context, result CleanupResult) error
 
-	// GetLatestResult lấy kết quả của lần cleanup gần nhất
-	GetLatestResult(ctx context.Context) (*CleanupResult, error)
+	// GetLatestResult lấy kết quả của lần cleanup gần nhất trên nodeName (rỗng = mọi node).
+	// Với store dùng chung nhiều node, truyền node hiện tại để không đọc lần chạy của node khác.
+	GetLatestResult(ctx context.Context, nodeName string) (*CleanupResult, error)
 
 	// GetResultByID lấy kết quả cleanup theo ID
 	GetResultByID(ctx context.Context, id string) (*CleanupResult, error)
 
-	// GetResults lấy danh sách kết quả cleanup, có phân trang
-	GetResults(ctx context.Context, limit, offset int) ([]CleanupResult, error)
+	// GetResults lấy danh sách kết quả cleanup khớp query, mới nhất trước, có phân trang
+	GetResults(ctx context.Context, query ResultQuery, limit, offset int) ([]CleanupResult, error)
+
+	// StreamResults duyệt các kết quả khớp query theo thứ tự start_time tăng dần,
+	// gọi fn cho từng kết quả mà không tải toàn bộ bảng vào bộ nhớ
+	StreamResults(ctx context.Context, query ResultQuery, fn func(CleanupResult) error) error
+
+	// SaveRemovals lưu các sự kiện xóa image của một lần chạy trong một transaction
+	SaveRemovals(ctx context.Context, removals []ImageRemoval) error
+
+	// StreamRemovals duyệt các sự kiện xóa image của những lần chạy khớp query,
+	// theo thứ tự start_time của lần chạy rồi thời điểm xóa
+	StreamRemovals(ctx context.Context, query ResultQuery, fn func(ImageRemoval) error) error
 }
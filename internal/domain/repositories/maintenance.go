@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// RetentionPolicy định nghĩa giới hạn lưu trữ lịch sử cleanup.
+// Giá trị 0 nghĩa là không giới hạn theo tiêu chí đó.
+type RetentionPolicy struct {
+	MaxAge  time.Duration
+	MaxRows int
+	// NodeName giới hạn việc xóa trong lịch sử của một node (MaxRows tính riêng cho node đó).
+	// Để trống thì áp dụng cho toàn bộ bảng.
+	NodeName string
+}
+
+// Enabled cho biết policy có giới hạn nào được bật hay không
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxRows > 0
+}
+
+// MaintenanceRepository định nghĩa các thao tác bảo trì database lưu kết quả
+type MaintenanceRepository interface {
+	// PruneResults xóa các kết quả vượt quá retention policy, trả về số dòng đã xóa
+	PruneResults(ctx context.Context, policy RetentionPolicy) (int64, error)
+
+	// Optimize thu hồi dung lượng trống sau khi xóa dữ liệu
+	Optimize(ctx context.Context) error
+
+	// DatabaseSize trả về kích thước database tính bằng byte
+	DatabaseSize(ctx context.Context) (int64, error)
+
+	// Ping kiểm tra database có kết nối và đọc được bảng kết quả không
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"time"
+)
+
+// ErrOutboxMessageNotFound được trả về khi không có thông báo với ID tương ứng
+var ErrOutboxMessageNotFound = errors.New("outbox message not found")
+
+// OutboxRepository lưu các thông báo chờ gửi lại của node này
+type OutboxRepository interface {
+	// Enqueue thêm một thông báo mới
+	Enqueue(ctx context.Context, message models.OutboxMessage) error
+
+	// Due trả về tối đa limit thông báo pending đã đến lượt gửi tại now, cũ nhất trước
+	Due(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error)
+
+	// Update lưu trạng thái, số lần thử, lần thử kế tiếp và lỗi gần nhất của thông báo
+	Update(ctx context.Context, message models.OutboxMessage) error
+
+	// Delete xóa thông báo đã gửi được hoặc bị bỏ qua
+	Delete(ctx context.Context, id string) error
+
+	// Get trả về một thông báo theo ID
+	Get(ctx context.Context, id string) (*models.OutboxMessage, error)
+
+	// List trả về các thông báo theo trạng thái (rỗng = mọi trạng thái), mới nhất trước
+	List(ctx context.Context, status string, limit, offset int) ([]models.OutboxMessage, error)
+
+	// Stats đếm số thông báo theo trạng thái
+	Stats(ctx context.Context) (models.OutboxStats, error)
+}
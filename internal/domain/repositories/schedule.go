@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+)
+
+// ScheduleRepository lưu lịch chạy đã thay đổi qua API, ghi đè giá trị trong file cấu hình
+type ScheduleRepository interface {
+	// ListSchedules trả về tất cả lịch đã lưu
+	ListSchedules(ctx context.Context) ([]models.Schedule, error)
+
+	// SaveSchedule tạo mới hoặc cập nhật lịch theo tên
+	SaveSchedule(ctx context.Context, schedule models.Schedule) error
+}
@@ -0,0 +1,173 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reloader giữ certificate của server và CA bundle dùng để xác thực client,
+// nạp lại khi các file thay đổi mà không cần restart service
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+
+	logger *zap.Logger
+}
+
+// NewReloader nạp certificate, key và CA bundle (tùy chọn) lần đầu; lỗi nếu file không hợp lệ
+func NewReloader(certFile, keyFile, clientCAFile string, logger *zap.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		modTimes:     make(map[string]time.Time),
+		logger:       logger,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ClientAuthEnabled cho biết client certificate có được xác thực không
+func (r *Reloader) ClientAuthEnabled() bool {
+	return r.clientCAFile != ""
+}
+
+// TLSConfig trả về cấu hình TLS luôn dùng certificate và CA mới nhất.
+// Client certificate được xác thực nếu có gửi; việc bắt buộc theo route do middleware đảm nhận.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.currentConfig(), nil
+		},
+	}
+}
+
+func (r *Reloader) currentConfig() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+	}
+	if r.clientCAs != nil {
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		cfg.ClientCAs = r.clientCAs
+	}
+	return cfg
+}
+
+// Watch kiểm tra thời gian sửa đổi của các file theo interval và nạp lại khi thay đổi.
+// Nếu file mới không hợp lệ, certificate cũ tiếp tục được dùng.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil {
+				r.logger.Warn("Failed to check TLS files", zap.Error(err))
+				continue
+			}
+			if !changed {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				r.logger.Error("Failed to reload TLS certificate, keeping the previous one", zap.Error(err))
+				continue
+			}
+			r.logger.Info("TLS certificate reloaded",
+				zap.String("cert_file", r.certFile),
+				zap.String("client_ca_file", r.clientCAFile))
+		}
+	}
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// changed so sánh thời gian sửa đổi hiện tại với lần nạp trước
+func (r *Reloader) changed() (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, file := range r.files() {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		if !stat.ModTime().Equal(r.modTimes[file]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		modTimes[file] = stat.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.clientCAFile)
+		}
+	}
+
+	if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+		cert.Leaf = leaf
+		r.logger.Info("TLS certificate loaded",
+			zap.String("subject", leaf.Subject.String()),
+			zap.Time("not_after", leaf.NotAfter))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
@@ -0,0 +1,207 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert tạo certificate ký bởi parent, hoặc tự ký khi parent là nil
+func newTestCert(t *testing.T, serial int64, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("test-%d", serial)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              []string{"localhost"},
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string, modTime time.Time) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), modTime)
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), modTime)
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func servingSerial(t *testing.T, r *Reloader) int64 {
+	t.Helper()
+	cfg, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Certificates[0].Leaf.SerialNumber.Int64()
+}
+
+func TestReloaderReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	start := time.Now().Add(-time.Minute)
+
+	newTestCert(t, 1, false, nil).write(t, certFile, keyFile, start)
+
+	reloader, err := NewReloader(certFile, keyFile, "", zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create reloader: %v", err)
+	}
+	if reloader.ClientAuthEnabled() {
+		t.Error("expected client auth to be disabled without a CA file")
+	}
+	if serial := servingSerial(t, reloader); serial != 1 {
+		t.Fatalf("expected serial 1, got %d", serial)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	// Certificate mới được nạp khi file thay đổi
+	newTestCert(t, 2, false, nil).write(t, certFile, keyFile, start.Add(time.Second))
+	waitFor(t, func() bool { return servingSerial(t, reloader) == 2 })
+
+	// File hỏng không thay thế certificate đang dùng
+	writeFile(t, certFile, []byte("not a certificate"), start.Add(2*time.Second))
+	time.Sleep(50 * time.Millisecond)
+	if serial := servingSerial(t, reloader); serial != 2 {
+		t.Errorf("expected previous certificate to be kept, got serial %d", serial)
+	}
+}
+
+func TestReloaderVerifiesClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := newTestCert(t, 10, true, nil)
+	writeFile(t, caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.der}), time.Now())
+	newTestCert(t, 11, false, ca).write(t, certFile, keyFile, time.Now())
+
+	reloader, err := NewReloader(certFile, keyFile, caFile, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create reloader: %v", err)
+	}
+	if !reloader.ClientAuthEnabled() {
+		t.Fatal("expected client auth to be enabled")
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%d", len(r.TLS.VerifiedChains))
+	}))
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	get := func(clientCerts ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: "localhost",
+			// Luôn gửi certificate, kể cả khi CA của nó không nằm trong danh sách server chấp nhận
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(clientCerts) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &clientCerts[0], nil
+			},
+		}}}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body := make([]byte, 8)
+		n, _ := resp.Body.Read(body)
+		return string(body[:n]), nil
+	}
+
+	if chains, err := get(newTestCert(t, 12, false, ca).tlsCertificate()); err != nil || chains != "1" {
+		t.Errorf("expected verified client certificate, got %q (%v)", chains, err)
+	}
+	if chains, err := get(); err != nil || chains != "0" {
+		t.Errorf("expected connection without client certificate to be unverified, got %q (%v)", chains, err)
+	}
+	if _, err := get(newTestCert(t, 13, false, nil).tlsCertificate()); err == nil {
+		t.Error("expected handshake to fail for a client certificate from another CA")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"go-image-cleanup/internal/domain/models"
 	"os/exec"
+	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 )
@@ -39,6 +41,7 @@ func (r *CrictlRepository) GetAllImages(ctx context.Context) ([]models.Image, er
 		Images []struct {
 			ID       string   `json:"id"`
 			RepoTags []string `json:"repoTags"`
+			Size     string   `json:"size"`
 		} `json:"images"`
 	}
 
@@ -48,9 +51,12 @@ func (r *CrictlRepository) GetAllImages(ctx context.Context) ([]models.Image, er
 
 	var images []models.Image
 	for _, img := range response.Images {
+		// crictl trả size dạng chuỗi; bỏ qua nếu không parse được vì size chỉ dùng để hiển thị
+		size, _ := strconv.ParseUint(img.Size, 10, 64)
 		images = append(images, models.Image{
 			ID:   img.ID,
 			Tags: img.RepoTags,
+			Size: size,
 		})
 	}
 
@@ -94,3 +100,22 @@ func (r *CrictlRepository) RemoveImage(ctx context.Context, imageID string) erro
 	r.logger.Debug("Removed image", zap.String("imageID", imageID))
 	return nil
 }
+
+// RuntimeVersion returns the container runtime name and version reported by crictl
+func (r *CrictlRepository) RuntimeVersion(ctx context.Context) (string, error) {
+	output, err := r.executeCommand(ctx, "version", "--output=json")
+	if err != nil {
+		return "", fmt.Errorf("failed to execute crictl version: %w", err)
+	}
+
+	var response struct {
+		RuntimeName    string `json:"runtimeName"`
+		RuntimeVersion string `json:"runtimeVersion"`
+	}
+
+	if err := json.Unmarshal(output, &response); err != nil {
+		return "", fmt.Errorf("failed to parse version output: %w", err)
+	}
+
+	return strings.TrimSpace(response.RuntimeName + " " + response.RuntimeVersion), nil
+}
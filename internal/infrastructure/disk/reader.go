@@ -0,0 +1,13 @@
+package disk
+
+import "go-image-cleanup/internal/domain/disk"
+
+// StatfsReader đọc dung lượng filesystem từ kernel
+type StatfsReader struct{}
+
+// Verify that StatfsReader implements UsageReader interface
+var _ disk.UsageReader = StatfsReader{}
+
+func NewStatfsReader() StatfsReader {
+	return StatfsReader{}
+}
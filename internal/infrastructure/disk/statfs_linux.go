@@ -0,0 +1,26 @@
+//go:build linux
+
+package disk
+
+import (
+	"fmt"
+	"go-image-cleanup/internal/domain/disk"
+	"syscall"
+)
+
+// Usage đọc dung lượng bằng statfs. Dung lượng đã dùng tính theo block không còn trống
+// và tổng tính theo phần người dùng thường ghi được, giống cột Use% của df.
+func (StatfsReader) Usage(path string) (disk.Usage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return disk.Usage{}, fmt.Errorf("failed to stat filesystem of %s: %w", path, err)
+	}
+
+	blockSize := uint64(stat.Bsize)
+	used := (stat.Blocks - stat.Bfree) * blockSize
+	return disk.Usage{
+		Path:       path,
+		UsedBytes:  used,
+		TotalBytes: used + stat.Bavail*blockSize,
+	}, nil
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package disk
+
+import "testing"
+
+func TestStatfsReaderUsage(t *testing.T) {
+	usage, err := NewStatfsReader().Usage(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.TotalBytes == 0 || usage.UsedBytes > usage.TotalBytes {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+	if percent := usage.UsedPercent(); percent < 0 || percent > 100 {
+		t.Errorf("unexpected used percent %f", percent)
+	}
+
+	if _, err := NewStatfsReader().Usage("/does/not/exist"); err == nil {
+		t.Error("expected an error for a missing path")
+	}
+}
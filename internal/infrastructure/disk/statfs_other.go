@@ -0,0 +1,13 @@
+//go:build !linux
+
+package disk
+
+import (
+	"fmt"
+	"go-image-cleanup/internal/domain/disk"
+)
+
+// Usage chỉ được hỗ trợ trên Linux, nơi service chạy cùng container runtime
+func (StatfsReader) Usage(path string) (disk.Usage, error) {
+	return disk.Usage{}, fmt.Errorf("disk usage is not supported on this platform")
+}
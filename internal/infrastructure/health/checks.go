@@ -0,0 +1,180 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/health"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Verify that all checks implement Checker
+var (
+	_ health.Checker = (*RuntimeCheck)(nil)
+	_ health.Checker = (*DatabaseCheck)(nil)
+	_ health.Checker = (*SchedulerCheck)(nil)
+	_ health.Checker = (*LastRunCheck)(nil)
+)
+
+// RuntimeVersioner trả về phiên bản container runtime qua crictl
+type RuntimeVersioner interface {
+	RuntimeVersion(ctx context.Context) (string, error)
+}
+
+// Pinger kiểm tra kết nối database
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// RuntimeCheck kiểm tra crictl có chạy được và kết nối được tới container runtime
+type RuntimeCheck struct {
+	runtime RuntimeVersioner
+}
+
+func NewRuntimeCheck(runtime RuntimeVersioner) *RuntimeCheck {
+	return &RuntimeCheck{runtime: runtime}
+}
+
+func (c *RuntimeCheck) Name() string {
+	return "container_runtime"
+}
+
+func (c *RuntimeCheck) Check(ctx context.Context) (string, error) {
+	return c.runtime.RuntimeVersion(ctx)
+}
+
+// DatabaseCheck kiểm tra result repository có đọc được không
+type DatabaseCheck struct {
+	name string
+	db   Pinger
+}
+
+func NewDatabaseCheck(name string, db Pinger) *DatabaseCheck {
+	return &DatabaseCheck{name: name, db: db}
+}
+
+func (c *DatabaseCheck) Name() string {
+	return c.name
+}
+
+func (c *DatabaseCheck) Check(ctx context.Context) (string, error) {
+	if err := c.db.Ping(ctx); err != nil {
+		return "", err
+	}
+	return "reachable", nil
+}
+
+// schedulerGrace là độ trễ cho phép giữa thời điểm chạy dự kiến và hiện tại trước khi coi scheduler bị treo
+const schedulerGrace = time.Minute
+
+// SchedulerCheck kiểm tra cron scheduler đang chạy và vòng lặp của nó còn phản hồi
+type SchedulerCheck struct {
+	cron   *cron.Cron
+	paused func() int
+}
+
+// NewSchedulerCheck tạo check; paused trả về số job đang tạm dừng, vốn không có entry trong cron
+func NewSchedulerCheck(c *cron.Cron, paused func() int) *SchedulerCheck {
+	return &SchedulerCheck{cron: c, paused: paused}
+}
+
+func (c *SchedulerCheck) Name() string {
+	return "scheduler"
+}
+
+func (c *SchedulerCheck) Check(ctx context.Context) (string, error) {
+	// Entries() chờ vòng lặp của cron trả lời, nên bị treo nếu vòng lặp không còn chạy
+	entriesCh := make(chan []cron.Entry, 1)
+	go func() {
+		entriesCh <- c.cron.Entries()
+	}()
+
+	var entries []cron.Entry
+	select {
+	case entries = <-entriesCh:
+	case <-ctx.Done():
+		return "", fmt.Errorf("scheduler did not respond: %w", ctx.Err())
+	}
+
+	paused := c.paused()
+	if len(entries) == 0 {
+		if paused > 0 {
+			return fmt.Sprintf("all %d jobs are paused", paused), nil
+		}
+		return "", errors.New("no jobs are scheduled")
+	}
+
+	now := time.Now()
+	var next time.Time
+	for _, entry := range entries {
+		if entry.Next.IsZero() {
+			return "", errors.New("scheduler is not running")
+		}
+		if entry.Next.Before(now.Add(-schedulerGrace)) {
+			return "", fmt.Errorf("job %d was due at %s but has not run", entry.ID, entry.Next.Format(time.RFC3339))
+		}
+		if next.IsZero() || entry.Next.Before(next) {
+			next = entry.Next
+		}
+	}
+
+	if paused > 0 {
+		return fmt.Sprintf("%d jobs, %d paused, next run at %s", len(entries), paused, next.Format(time.RFC3339)), nil
+	}
+	return fmt.Sprintf("%d jobs, next run at %s", len(entries), next.Format(time.RFC3339)), nil
+}
+
+// LastRunCheck kiểm tra lần cleanup gần nhất của node này không quá cũ so với lịch cleanup hiện tại
+type LastRunCheck struct {
+	results   repositories.CleanupResultRepository
+	nodeName  string
+	window    health.RunWindowFunc
+	startedAt time.Time
+}
+
+// NewLastRunCheck tạo check; khi chưa có kết quả nào, tuổi được tính từ lúc service khởi động
+func NewLastRunCheck(results repositories.CleanupResultRepository, nodeName string, window health.RunWindowFunc, startedAt time.Time) *LastRunCheck {
+	return &LastRunCheck{
+		results:   results,
+		nodeName:  nodeName,
+		window:    window,
+		startedAt: startedAt,
+	}
+}
+
+func (c *LastRunCheck) Name() string {
+	return "last_run"
+}
+
+func (c *LastRunCheck) Check(ctx context.Context) (string, error) {
+	window := c.window()
+	if window.Paused {
+		return "cleanup is paused", nil
+	}
+
+	// Lọc theo node vì nhiều node có thể dùng chung một result store
+	results, err := c.results.GetResults(ctx, repositories.ResultQuery{NodeName: c.nodeName}, 1, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to read last run: %w", err)
+	}
+
+	now := time.Now()
+	if len(results) == 0 {
+		if now.After(window.Deadline(c.startedAt)) {
+			return "", fmt.Errorf("no cleanup run recorded within %s", window.MaxAge)
+		}
+		return "no runs yet", nil
+	}
+
+	last := results[0].StartTime
+	age := now.Sub(last)
+	if now.After(window.Deadline(last)) {
+		return "", fmt.Errorf("last run started %s ago at %s, older than %s",
+			age.Truncate(time.Second), last.Format(time.RFC3339), window.MaxAge)
+	}
+
+	return fmt.Sprintf("last run started %s ago", age.Truncate(time.Second)), nil
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/health"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// mockResultRepository trả về results cho mọi query
+type mockResultRepository struct {
+	repositories.CleanupResultRepository
+	results []repositories.CleanupResult
+}
+
+func (m *mockResultRepository) GetResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
+	return m.results, nil
+}
+
+func TestLastRunCheck(t *testing.T) {
+	now := time.Now()
+	lastRun := &mockResultRepository{results: []repositories.CleanupResult{{StartTime: now.Add(-72 * time.Hour)}}}
+
+	tests := []struct {
+		name    string
+		repo    *mockResultRepository
+		window  health.RunWindow
+		wantErr bool
+		detail  string
+	}{
+		{"recent enough", lastRun, health.RunWindow{MaxAge: 96 * time.Hour}, false, "last run started"},
+		{"too old", lastRun, health.RunWindow{MaxAge: 48 * time.Hour}, true, ""},
+		{"paused", lastRun, health.RunWindow{MaxAge: 48 * time.Hour, Paused: true}, false, "cleanup is paused"},
+		{"resumed recently", lastRun, health.RunWindow{MaxAge: 48 * time.Hour, Since: now.Add(-time.Hour)}, false, "last run started"},
+		{"no runs since start", &mockResultRepository{}, health.RunWindow{MaxAge: 48 * time.Hour}, false, "no runs yet"},
+		{"no runs since reschedule", &mockResultRepository{}, health.RunWindow{MaxAge: time.Hour, Since: now.Add(-2 * time.Hour)}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := tt.window
+			check := NewLastRunCheck(tt.repo, "node-1", func() health.RunWindow { return window }, now.Add(-3*time.Hour))
+
+			detail, err := check.Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %q, %v", tt.wantErr, detail, err)
+			}
+			if !strings.Contains(detail, tt.detail) {
+				t.Errorf("expected detail to contain %q, got %q", tt.detail, detail)
+			}
+		})
+	}
+}
+
+func TestSchedulerCheckWithPausedJobs(t *testing.T) {
+	c := cron.New()
+	c.Start()
+	defer c.Stop()
+
+	paused := 2
+	check := NewSchedulerCheck(c, func() int { return paused })
+
+	detail, err := check.Check(context.Background())
+	if err != nil || detail != "all 2 jobs are paused" {
+		t.Errorf("expected the scheduler to be healthy with every job paused, got %q, %v", detail, err)
+	}
+
+	paused = 0
+	if _, err := check.Check(context.Background()); err == nil {
+		t.Error("expected an error when no job is scheduled or paused")
+	}
+
+	if _, err := c.AddFunc("0 0 * * *", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	paused = 1
+	detail, err = check.Check(context.Background())
+	if err != nil || !strings.Contains(detail, "1 jobs, 1 paused") {
+		t.Errorf("expected running and paused jobs in the detail, got %q, %v", detail, err)
+	}
+}
@@ -0,0 +1,143 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/host"
+	"go-image-cleanup/internal/domain/models"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// machineIDPaths là các vị trí machine-id thường gặp trên Linux
+var machineIDPaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// RuntimeVersioner trả về phiên bản container runtime, ví dụ "containerd 1.7.2"
+type RuntimeVersioner interface {
+	RuntimeVersion(ctx context.Context) (string, error)
+}
+
+// Verify that SystemIdentifier implements Identifier
+var _ host.Identifier = (*SystemIdentifier)(nil)
+
+// SystemIdentifier lấy danh tính node từ hệ điều hành và container runtime
+type SystemIdentifier struct {
+	nodeName string
+	labels   map[string]string
+	runtime  RuntimeVersioner
+	logger   *zap.Logger
+
+	machineIDOnce sync.Once
+	machineID     string
+}
+
+// NewSystemIdentifier tạo identifier; nodeName rỗng sẽ dùng hostname
+func NewSystemIdentifier(nodeName string, labels map[string]string, runtime RuntimeVersioner, logger *zap.Logger) *SystemIdentifier {
+	return &SystemIdentifier{
+		nodeName: nodeName,
+		labels:   labels,
+		runtime:  runtime,
+		logger:   logger,
+	}
+}
+
+// NodeName trả về node name đã cấu hình, hoặc hostname khi không cấu hình
+func (i *SystemIdentifier) NodeName() string {
+	if i.nodeName != "" {
+		return i.nodeName
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+func (i *SystemIdentifier) Identify(ctx context.Context) (models.Host, error) {
+	var errs []error
+
+	h := models.Host{
+		NodeName: i.nodeName,
+		Labels:   i.labels,
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to get hostname: %w", err))
+	}
+	h.Hostname = hostname
+	if h.NodeName == "" {
+		h.NodeName = hostname
+	}
+
+	h.IPv4, h.IPv6, err = interfaceAddresses()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	h.MachineID = i.readMachineID()
+
+	if i.runtime != nil {
+		version, err := i.runtime.RuntimeVersion(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get runtime version: %w", err))
+		}
+		h.RuntimeVersion = version
+	}
+
+	return h, errors.Join(errs...)
+}
+
+// readMachineID đọc machine-id một lần vì giá trị không đổi trong suốt vòng đời process
+func (i *SystemIdentifier) readMachineID() string {
+	i.machineIDOnce.Do(func() {
+		for _, path := range machineIDPaths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if id := strings.TrimSpace(string(data)); id != "" {
+				i.machineID = id
+				return
+			}
+		}
+		i.logger.Warn("Machine ID not found", zap.Strings("paths", machineIDPaths))
+	})
+	return i.machineID
+}
+
+// interfaceAddresses trả về địa chỉ IPv4 và IPv6 của các interface đang up, bỏ qua loopback và link-local
+func interfaceAddresses() ([]string, []string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get network interfaces: %w", err)
+	}
+
+	var ipv4, ipv6 []string
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if ipNet.IP.To4() != nil {
+				ipv4 = append(ipv4, ipNet.IP.String())
+			} else {
+				ipv6 = append(ipv6, ipNet.IP.String())
+			}
+		}
+	}
+
+	return ipv4, ipv6, nil
+}
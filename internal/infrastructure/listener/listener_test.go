@@ -0,0 +1,93 @@
+package listener
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "api.sock")
+
+	ln, err := Unix(path, 0o600)
+	if err != nil {
+		t.Fatalf("failed to create socket: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket file missing: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected socket with mode 0600, got %v", info.Mode())
+	}
+
+	// Socket đang được dùng thì không được xóa
+	if _, err := Unix(path, 0o600); err == nil {
+		t.Error("expected an error for a socket that is in use")
+	}
+	ln.Close()
+
+	// Socket cũ còn sót lại được thay thế
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err = Unix(path, 0o660)
+	if err != nil {
+		t.Fatalf("expected stale socket to be replaced: %v", err)
+	}
+	ln.Close()
+
+	// Không bao giờ xóa file thường
+	regular := filepath.Join(t.TempDir(), "regular")
+	if err := os.WriteFile(regular, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unix(regular, 0o600); err == nil {
+		t.Error("expected an error for a regular file")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unix, err := Unix(filepath.Join(t.TempDir(), "api.sock"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln := Merge(tcp, unix)
+
+	for _, addr := range []net.Addr{tcp.Addr(), unix.Addr()} {
+		client, err := net.Dial(addr.Network(), addr.String())
+		if err != nil {
+			t.Fatalf("failed to dial %s: %v", addr, err)
+		}
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatalf("accept for %s failed: %v", addr, err)
+		}
+		if conn.LocalAddr().Network() != addr.Network() {
+			t.Errorf("expected a %s connection, got %s", addr.Network(), conn.LocalAddr().Network())
+		}
+		conn.Close()
+		client.Close()
+	}
+
+	if err := ln.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if _, err := ln.Accept(); err == nil {
+		t.Error("expected Accept to fail after Close")
+	}
+	if _, err := os.Stat(unix.Addr().String()); !os.IsNotExist(err) {
+		t.Errorf("expected socket file to be removed on close, got %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"go.uber.org/zap"
+)
+
+// Database maintenance metrics
+func (p *PrometheusMetrics) SetDatabaseSize(bytes int64) {
+	p.DatabaseSize.WithLabelValues(p.hostname).Set(float64(bytes))
+	p.logger.Debug("Database size metric set",
+		zap.String("metric", "image_cleanup_database_size_bytes"),
+		zap.String("hostname", p.hostname),
+		zap.Int64("bytes", bytes))
+}
+
+func (p *PrometheusMetrics) AddResultsPruned(count int64) {
+	p.ResultsPruned.WithLabelValues(p.hostname).Add(float64(count))
+	p.logger.Debug("Results pruned metric incremented",
+		zap.String("metric", "image_cleanup_results_pruned_total"),
+		zap.String("hostname", p.hostname),
+		zap.Int64("count", count))
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -54,3 +55,33 @@ func (p *PrometheusMetrics) IncHttpError(path, method string, status int, errorT
 		zap.Int("status", status),
 		zap.String("error_type", errorType))
 }
+
+func (p *PrometheusMetrics) IncAuthFailures(path, reason string) {
+	p.AuthFailures.WithLabelValues(
+		p.hostname,
+		path,
+		reason,
+	).Inc()
+	p.logger.Debug("Auth failure metric incremented",
+		zap.String("metric", "image_cleanup_http_auth_failures_total"),
+		zap.String("hostname", p.hostname),
+		zap.String("path", path),
+		zap.String("reason", reason))
+}
+
+func (p *PrometheusMetrics) ObserveHttpDuration(path, method string, status int, duration time.Duration) {
+	p.HttpDuration.WithLabelValues(
+		p.hostname,
+		strconv.Itoa(status),
+		method,
+		path,
+	).Observe(duration.Seconds())
+}
+
+func (p *PrometheusMetrics) IncHttpInFlight() {
+	p.HttpInFlight.WithLabelValues(p.hostname).Inc()
+}
+
+func (p *PrometheusMetrics) DecHttpInFlight() {
+	p.HttpInFlight.WithLabelValues(p.hostname).Dec()
+}
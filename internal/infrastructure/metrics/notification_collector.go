@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"go.uber.org/zap"
+)
+
+// Notification metrics
+func (p *PrometheusMetrics) IncNotifications(channel, status string) {
+	p.Notifications.WithLabelValues(p.hostname, channel, status).Inc()
+	p.logger.Debug("Notification metric incremented",
+		zap.String("metric", "image_cleanup_notifications_total"),
+		zap.String("hostname", p.hostname),
+		zap.String("channel", channel),
+		zap.String("status", status))
+}
+
+func (p *PrometheusMetrics) SetOutboxMessages(status string, count int) {
+	p.OutboxMessages.WithLabelValues(p.hostname, status).Set(float64(count))
+	p.logger.Debug("Outbox metric set",
+		zap.String("metric", "image_cleanup_notification_outbox_messages"),
+		zap.String("hostname", p.hostname),
+		zap.String("status", status),
+		zap.Int("count", count))
+}
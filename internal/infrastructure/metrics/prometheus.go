@@ -18,6 +18,13 @@ type PrometheusMetrics struct {
 	HttpRequestTotal   *prometheus.CounterVec
 	HttpRequestTimeout *prometheus.CounterVec
 	HttpRequestErrors  *prometheus.CounterVec
+	AuthFailures       *prometheus.CounterVec
+	HttpDuration       *prometheus.HistogramVec
+	HttpInFlight       *prometheus.GaugeVec
+	DatabaseSize       *prometheus.GaugeVec
+	ResultsPruned      *prometheus.CounterVec
+	Notifications      *prometheus.CounterVec
+	OutboxMessages     *prometheus.GaugeVec
 	hostname           string
 	logger             *zap.Logger
 }
@@ -88,6 +95,50 @@ func NewPrometheusMetrics(logger *zap.Logger) *PrometheusMetrics {
 			Help:      "Total number of HTTP request errors",
 		}, []string{"hostname", "path", "method", "status", "error_type"}),
 
+		AuthFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "image_cleanup",
+			Name:      "http_auth_failures_total",
+			Help:      "Total number of rejected API authentication attempts",
+		}, []string{"hostname", "path", "reason"}),
+
+		HttpDuration: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "image_cleanup",
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by matched route template",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"hostname", "status", "method", "path"}),
+
+		// Route chưa được xác định khi request bắt đầu nên gauge này chỉ theo hostname
+		HttpInFlight: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "image_cleanup",
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests currently being served",
+		}, []string{"hostname"}),
+
+		DatabaseSize: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "image_cleanup",
+			Name:      "database_size_bytes",
+			Help:      "Size of the cleanup results database in bytes",
+		}, []string{"hostname"}),
+
+		ResultsPruned: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "image_cleanup",
+			Name:      "results_pruned_total",
+			Help:      "The total number of cleanup results removed by retention",
+		}, []string{"hostname"}),
+
+		Notifications: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "image_cleanup",
+			Name:      "notifications_total",
+			Help:      "Notification deliveries by channel and status (sent, skipped, queued, failed)",
+		}, []string{"hostname", "channel", "status"}),
+
+		OutboxMessages: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "image_cleanup",
+			Name:      "notification_outbox_messages",
+			Help:      "Notifications waiting in the outbox by status (pending, failed)",
+		}, []string{"hostname", "status"}),
+
 		hostname: hostname,
 		logger:   logger,
 	}
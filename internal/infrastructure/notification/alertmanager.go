@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/pkg/constants"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// alertmanagerAlertsPath is the alerts endpoint of the Alertmanager API v2
+const alertmanagerAlertsPath = "/api/v2/alerts"
+
+// AlertmanagerConfig configures the Alertmanager client
+type AlertmanagerConfig struct {
+	URLs    []string      // Alertmanager base URLs or full /api/v2/alerts endpoints; credentials may be given as user:password@
+	Timeout time.Duration // Per request
+}
+
+// AlertmanagerClient posts alerts to one or more Alertmanager instances
+type AlertmanagerClient struct {
+	endpoints []string
+	client    *http.Client
+	logger    *zap.Logger
+}
+
+// Verify that AlertmanagerClient implements AlertSender interface
+var _ notification.AlertSender = (*AlertmanagerClient)(nil)
+
+func NewAlertmanagerClient(config AlertmanagerConfig, logger *zap.Logger) *AlertmanagerClient {
+	if config.Timeout <= 0 {
+		config.Timeout = constants.NotificationTimeout
+	}
+	endpoints := make([]string, len(config.URLs))
+	for i, base := range config.URLs {
+		endpoints[i] = AlertmanagerEndpoint(base)
+	}
+	return &AlertmanagerClient{
+		endpoints: endpoints,
+		client:    &http.Client{Timeout: config.Timeout},
+		logger:    logger,
+	}
+}
+
+// AlertmanagerEndpoint returns the alerts endpoint of an Alertmanager base URL such as
+// http://alertmanager:9093; a URL that already ends in /api/v2/alerts is returned as is
+func AlertmanagerEndpoint(base string) string {
+	base = strings.TrimRight(base, "/")
+	if strings.HasSuffix(base, alertmanagerAlertsPath) {
+		return base
+	}
+	return base + alertmanagerAlertsPath
+}
+
+// SendAlerts posts the alerts to every Alertmanager. Replicas of a cluster do not share alerts,
+// so each of them gets all of them; one failing does not stop the others.
+func (c *AlertmanagerClient) SendAlerts(ctx context.Context, alerts []notification.Alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("failed to encode alerts: %w", err)
+	}
+
+	var errs []error
+	for _, endpoint := range c.endpoints {
+		if err := c.post(ctx, endpoint, body); err != nil {
+			errs = append(errs, fmt.Errorf("alertmanager %s: %w", redactURL(endpoint), err))
+			continue
+		}
+		c.logger.Debug("Sent alerts to Alertmanager",
+			zap.String("url", redactURL(endpoint)),
+			zap.Int("alerts", len(alerts)))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *AlertmanagerClient) post(ctx context.Context, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid alertmanager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constants.ServiceName)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// The URL may contain credentials, so only the underlying error is reported
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send alerts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("alertmanager returned status %d %s", resp.StatusCode, strings.TrimSpace(string(reason)))
+}
+
+// redactURL hides the password of a URL with credentials
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
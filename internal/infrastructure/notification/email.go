@@ -0,0 +1,349 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+	htmlTemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Supported SMTP_TLS modes
+const (
+	EmailTLSStartTLS = "starttls" // Plain connection upgraded with STARTTLS, usually port 587
+	EmailTLSImplicit = "tls"      // TLS from the first byte, usually port 465
+	EmailTLSNone     = "none"     // Only for local relays
+)
+
+// EmailConfig configures the SMTP connection and the envelope
+type EmailConfig struct {
+	Host     string
+	Port     string
+	Username string // Authentication is skipped when empty
+	Password string
+	TLSMode  string
+	From     string
+	To       []string
+	Timeout  time.Duration // For the whole SMTP conversation
+}
+
+// EmailNotifier sends HTML and plain text reports over SMTP
+type EmailNotifier struct {
+	config EmailConfig
+	logger *zap.Logger
+}
+
+// Verify that EmailNotifier implements ReportNotifier and DigestNotifier interfaces
+var (
+	_ notification.ReportNotifier = (*EmailNotifier)(nil)
+	_ notification.DigestNotifier = (*EmailNotifier)(nil)
+)
+
+func NewEmailNotifier(config EmailConfig, logger *zap.Logger) *EmailNotifier {
+	if config.Timeout <= 0 {
+		config.Timeout = constants.NotificationTimeout
+	}
+	return &EmailNotifier{
+		config: config,
+		logger: logger,
+	}
+}
+
+var emailFuncs = map[string]any{
+	"bytes":    helper.FormatBytes,
+	"ict":      helper.FormatICT,
+	"date":     func(t time.Time) string { return helper.TimeInICT(t).Format("2006-01-02") },
+	"duration": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"hostname": hostLabel,
+}
+
+var (
+	reportTextTemplate = textTemplate.Must(textTemplate.New("report").Funcs(emailFuncs).Parse(
+		`Image cleanup completed on {{hostname .Host}}
+
+Started:   {{ict .StartTime}}
+Finished:  {{ict .EndTime}}
+Duration:  {{duration .Duration}}
+
+Images:    {{.Total}}
+Removed:   {{.Removed}}
+Skipped:   {{.Skipped}}
+Failures:  {{.Failed}}
+Reclaimed: {{bytes .ReclaimedBytes}}
+{{- if .RequestID}}
+
+Request ID: {{.RequestID}}
+{{- end}}
+`))
+
+	reportHTMLTemplate = htmlTemplate.Must(htmlTemplate.New("report").Funcs(emailFuncs).Parse(
+		`<!DOCTYPE html>
+<html><body style="font-family: sans-serif; color: #222">
+<h2>Image cleanup completed on {{hostname .Host}}</h2>
+<table cellpadding="4" style="border-collapse: collapse">
+<tr><td>Started</td><td>{{ict .StartTime}}</td></tr>
+<tr><td>Finished</td><td>{{ict .EndTime}}</td></tr>
+<tr><td>Duration</td><td>{{duration .Duration}}</td></tr>
+<tr><td>Images</td><td>{{.Total}}</td></tr>
+<tr><td>Removed</td><td><b>{{.Removed}}</b></td></tr>
+<tr><td>Skipped</td><td>{{.Skipped}}</td></tr>
+<tr><td>Failures</td><td{{if .Failed}} style="color: #b00020"{{end}}>{{.Failed}}</td></tr>
+<tr><td>Reclaimed</td><td>{{bytes .ReclaimedBytes}}</td></tr>
+{{- if .RequestID}}
+<tr><td>Request ID</td><td><code>{{.RequestID}}</code></td></tr>
+{{- end}}
+</table>
+</body></html>
+`))
+
+	digestTextTemplate = textTemplate.Must(textTemplate.New("digest").Funcs(emailFuncs).Parse(
+		`Image cleanup summary for {{hostname .Host}}
+{{ict .From}} - {{ict .To}}
+
+Runs:          {{.Runs}}
+Images seen:   {{.Total}}
+Removed:       {{.Removed}}
+Skipped:       {{.Skipped}}
+Total runtime: {{duration .TotalDuration}}
+Longest run:   {{duration .LongestRun}}
+{{- if .Results}}
+
+Recent runs:
+{{- range .Results}}
+{{ict .StartTime}}  {{duration .Duration}}  removed {{.Removed}}, skipped {{.Skipped}}
+{{- end}}
+{{- else}}
+
+No cleanup ran in this period.
+{{- end}}
+`))
+
+	digestHTMLTemplate = htmlTemplate.Must(htmlTemplate.New("digest").Funcs(emailFuncs).Parse(
+		`<!DOCTYPE html>
+<html><body style="font-family: sans-serif; color: #222">
+<h2>Image cleanup summary for {{hostname .Host}}</h2>
+<p>{{date .From}} to {{date .To}}</p>
+<table cellpadding="4" style="border-collapse: collapse">
+<tr><td>Runs</td><td>{{.Runs}}</td></tr>
+<tr><td>Images seen</td><td>{{.Total}}</td></tr>
+<tr><td>Removed</td><td><b>{{.Removed}}</b></td></tr>
+<tr><td>Skipped</td><td>{{.Skipped}}</td></tr>
+<tr><td>Total runtime</td><td>{{duration .TotalDuration}}</td></tr>
+<tr><td>Longest run</td><td>{{duration .LongestRun}}</td></tr>
+</table>
+{{- if .Results}}
+<h3>Recent runs</h3>
+<table cellpadding="4" border="1" style="border-collapse: collapse">
+<tr><th>Started</th><th>Duration</th><th>Removed</th><th>Skipped</th></tr>
+{{- range .Results}}
+<tr><td>{{ict .StartTime}}</td><td>{{duration .Duration}}</td><td>{{.Removed}}</td><td>{{.Skipped}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No cleanup ran in this period.</p>
+{{- end}}
+</body></html>
+`))
+)
+
+// SendNotification sends a plain text message
+func (n *EmailNotifier) SendNotification(message string) error {
+	return n.send("Image cleanup notification", message, "")
+}
+
+// SendReport sends the report of one run
+func (n *EmailNotifier) SendReport(report notification.Report) error {
+	subject := fmt.Sprintf("Image cleanup on %s: %d removed, %d skipped", hostLabel(report.Host), report.Removed, report.Skipped)
+	if report.Failed > 0 {
+		subject += fmt.Sprintf(", %d failed", report.Failed)
+	}
+	return n.render(subject, reportTextTemplate, reportHTMLTemplate, report)
+}
+
+// SendDigest sends the summary of a period
+func (n *EmailNotifier) SendDigest(digest notification.Digest) error {
+	subject := fmt.Sprintf("Image cleanup summary for %s: %d runs, %d removed",
+		hostLabel(digest.Host), digest.Runs, digest.Removed)
+	return n.render(subject, digestTextTemplate, digestHTMLTemplate, digest)
+}
+
+func (n *EmailNotifier) render(subject string, text *textTemplate.Template, html *htmlTemplate.Template, data any) error {
+	var textBody, htmlBody strings.Builder
+	if err := text.Execute(&textBody, data); err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+	return n.send(subject, textBody.String(), htmlBody.String())
+}
+
+func (n *EmailNotifier) send(subject, text, html string) error {
+	message, err := buildEmail(n.config.From, n.config.To, subject, text, html)
+	if err != nil {
+		return err
+	}
+	if err := n.deliver(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	n.logger.Info("Successfully sent email notification",
+		zap.Strings("to", n.config.To),
+		zap.String("subject", subject))
+	return nil
+}
+
+// buildEmail returns a MIME message, multipart/alternative when an HTML body is given
+func buildEmail(from string, to []string, subject, text, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject), " ")),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + uuid.NewString() + "@" + constants.ServiceName + ">",
+		"MIME-Version: 1.0",
+	}
+
+	if html == "" {
+		headers = append(headers,
+			"Content-Type: text/plain; charset=utf-8",
+			"Content-Transfer-Encoding: quoted-printable")
+		buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	headers = append(headers, `Content-Type: multipart/alternative; boundary="`+parts.Boundary()+`"`)
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+	return qp.Close()
+}
+
+// deliver runs one SMTP conversation: TLS or STARTTLS, optional PLAIN auth, then the message
+func (n *EmailNotifier) deliver(message []byte) error {
+	cfg := n.config
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
+	tlsConfig := &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+
+	var conn net.Conn
+	var err error
+	if cfg.TLSMode == EmailTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if hostname, err := os.Hostname(); err == nil {
+		if err := client.Hello(hostname); err != nil {
+			return err
+		}
+	}
+
+	if cfg.TLSMode == EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// hostLabel returns the name a reader recognises the node by
+func hostLabel(host models.Host) string {
+	if host.NodeName != "" {
+		return host.NodeName
+	}
+	if host.Hostname != "" {
+		return host.Hostname
+	}
+	return "unknown host"
+}
@@ -0,0 +1,247 @@
+package notification
+
+import (
+	"bufio"
+	"encoding/base64"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// smtpSession is what the fake server saw during one conversation
+type smtpSession struct {
+	auth       string
+	from       string
+	recipients []string
+	data       string
+}
+
+// startFakeSMTP accepts a single conversation, advertising AUTH PLAIN without TLS
+func startFakeSMTP(t *testing.T) (string, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var session smtpSession
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				session.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+				reply("235 Authenticated")
+			case "MAIL":
+				session.from = line
+				reply("250 OK")
+			case "RCPT":
+				session.recipients = append(session.recipients, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				session.data = data.String()
+				reply("250 Queued")
+			case "QUIT":
+				reply("221 Bye")
+				sessions <- session
+				return
+			default:
+				reply("502 Unknown command")
+			}
+		}
+	}()
+	return ln.Addr().String(), sessions
+}
+
+func newTestEmail(t *testing.T, addr string) *EmailNotifier {
+	host, port, _ := net.SplitHostPort(addr)
+	return NewEmailNotifier(EmailConfig{
+		Host:     host,
+		Port:     port,
+		Username: "user",
+		Password: "pass",
+		TLSMode:  EmailTLSNone,
+		From:     "cleanup@example.com",
+		To:       []string{"ops@example.com", "dev@example.com"},
+		Timeout:  5 * time.Second,
+	}, zap.NewNop())
+}
+
+func receiveSession(t *testing.T, sessions <-chan smtpSession) smtpSession {
+	t.Helper()
+	select {
+	case session := <-sessions:
+		return session
+	case <-time.After(5 * time.Second):
+		t.Fatal("the SMTP conversation did not complete")
+		return smtpSession{}
+	}
+}
+
+// readAlternatives returns the decoded parts of a multipart/alternative message keyed by media type
+func readAlternatives(t *testing.T, msg *mail.Message) map[string]string {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q", msg.Header.Get("Content-Type"))
+	}
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("invalid quoted-printable body: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = string(body)
+	}
+	return parts
+}
+
+func TestEmailNotifierSendReport(t *testing.T) {
+	addr, sessions := startFakeSMTP(t)
+
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := notification.Report{
+		Host:           models.Host{Hostname: "host-1", NodeName: "node<1>"},
+		StartTime:      start,
+		EndTime:        start.Add(90 * time.Second),
+		Duration:       90 * time.Second,
+		Total:          5,
+		Removed:        3,
+		Skipped:        2,
+		Failed:         1,
+		ReclaimedBytes: 3 << 30,
+		RequestID:      "req-1",
+	}
+	if err := newTestEmail(t, addr).SendReport(report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	session := receiveSession(t, sessions)
+	credentials, _ := base64.StdEncoding.DecodeString(session.auth)
+	if string(credentials) != "\x00user\x00pass" {
+		t.Errorf("unexpected AUTH PLAIN credentials %q", credentials)
+	}
+	if session.from != "MAIL FROM:<cleanup@example.com>" || len(session.recipients) != 2 {
+		t.Errorf("unexpected envelope: %q %q", session.from, session.recipients)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(session.data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Image cleanup on node<1>: 3 removed, 2 skipped, 1 failed" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+
+	parts := readAlternatives(t, msg)
+	text, html := parts["text/plain"], parts["text/html"]
+	for _, want := range []string{"node<1>", "Removed:   3", "Reclaimed: 3.0 GiB", "Request ID: req-1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected the text part to contain %q, got:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(html, "node&lt;1&gt;") || strings.Contains(html, "node<1>") {
+		t.Errorf("expected the host to be escaped in the HTML part, got:\n%s", html)
+	}
+	if !strings.Contains(html, "<b>3</b>") {
+		t.Errorf("expected the removed count in the HTML part, got:\n%s", html)
+	}
+}
+
+func TestEmailNotifierSendDigest(t *testing.T) {
+	addr, sessions := startFakeSMTP(t)
+
+	to := time.Date(2025, 1, 8, 1, 0, 0, 0, time.UTC)
+	digest := notification.Digest{
+		Host:          models.Host{NodeName: "node-1"},
+		From:          to.Add(-7 * 24 * time.Hour),
+		To:            to,
+		Runs:          2,
+		Removed:       7,
+		TotalDuration: 3 * time.Minute,
+		LongestRun:    2 * time.Minute,
+		Results: []repositories.CleanupResult{
+			{StartTime: to.Add(-48 * time.Hour), Duration: time.Minute, Removed: 3},
+			{StartTime: to.Add(-24 * time.Hour), Duration: 2 * time.Minute, Removed: 4},
+		},
+	}
+	if err := newTestEmail(t, addr).SendDigest(digest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(receiveSession(t, sessions).data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	parts := readAlternatives(t, msg)
+	if !strings.Contains(parts["text/plain"], "Runs:          2") || !strings.Contains(parts["text/plain"], "removed 4") {
+		t.Errorf("unexpected text part:\n%s", parts["text/plain"])
+	}
+	if !strings.Contains(parts["text/html"], "2025-01-01 to 2025-01-08") {
+		t.Errorf("expected the period in the HTML part, got:\n%s", parts["text/html"])
+	}
+}
+
+func TestEmailNotifierRequiresStartTLS(t *testing.T) {
+	addr, _ := startFakeSMTP(t)
+	n := newTestEmail(t, addr)
+	n.config.TLSMode = EmailTLSStartTLS
+
+	err := n.SendNotification("hello")
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected a STARTTLS error, got %v", err)
+	}
+}
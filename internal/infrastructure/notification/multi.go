@@ -0,0 +1,214 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/notification"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Channel names, used in logs and metrics and as the prefix of the routing
+// settings (e.g. SLACK_ONLY_FAILURES)
+const (
+	ChannelTelegram = "telegram"
+	ChannelSlack    = "slack"
+	ChannelWebhook  = "webhook"
+	ChannelEmail    = "email"
+)
+
+// Channels lists every supported channel name
+var Channels = []string{ChannelTelegram, ChannelSlack, ChannelWebhook, ChannelEmail}
+
+// ChannelAlertmanager receives every run result to fire and resolve alerts, so it has a fixed
+// rule and is not part of Channels
+const ChannelAlertmanager = "alertmanager"
+
+// Delivery statuses, also used as the status label of image_cleanup_notifications_total
+const (
+	DeliverySent    = "sent"
+	DeliverySkipped = "skipped"
+	DeliveryFailed  = "failed"
+	DeliveryQueued  = "queued" // Failed, stored in the outbox for a later retry
+)
+
+// Channel is one notifier with the rule deciding which events it receives
+type Channel struct {
+	Name     string
+	Notifier notification.EventNotifier
+	Rule     notification.Rule
+}
+
+// Delivery is the outcome of sending one notification to one channel
+type Delivery struct {
+	Channel  string
+	Status   string
+	Reason   string // Why the channel was skipped, or the error of a queued delivery
+	Err      error
+	Duration time.Duration
+}
+
+// MultiNotifier sends every event to all channels in parallel.
+// A failing or slow channel does not affect the others; their errors are returned together,
+// unless an outbox is set to retry them later.
+type MultiNotifier struct {
+	channels []Channel
+	outbox   notification.Outbox
+	metrics  metrics.MetricsCollector
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// Verify that MultiNotifier implements EventNotifier and ChannelNotifier interfaces
+var (
+	_ notification.EventNotifier   = (*MultiNotifier)(nil)
+	_ notification.ChannelNotifier = (*MultiNotifier)(nil)
+)
+
+func NewMultiNotifier(metrics metrics.MetricsCollector, logger *zap.Logger, channels ...Channel) *MultiNotifier {
+	return &MultiNotifier{
+		channels: channels,
+		metrics:  metrics,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetOutbox stores failed deliveries in outbox instead of returning their errors
+func (m *MultiNotifier) SetOutbox(outbox notification.Outbox) {
+	m.outbox = outbox
+}
+
+// NotifyChannel sends the event to the named channel without applying its rule.
+// A non-empty target limits the delivery to that chat or URL of the channel.
+func (m *MultiNotifier) NotifyChannel(ctx context.Context, name, target string, event notification.Event) error {
+	for _, ch := range m.channels {
+		if ch.Name != name {
+			continue
+		}
+		if target == "" {
+			return ch.Notifier.Notify(ctx, event)
+		}
+		tn, ok := ch.Notifier.(notification.TargetNotifier)
+		if !ok {
+			return fmt.Errorf("channel %s has no targets", name)
+		}
+		return tn.NotifyTarget(ctx, target, event)
+	}
+	return fmt.Errorf("channel %s is not configured", name)
+}
+
+// Notify sends the event to every channel whose rule allows it
+func (m *MultiNotifier) Notify(ctx context.Context, event notification.Event) error {
+	return deliveryErrors(m.Dispatch(ctx, event))
+}
+
+// Dispatch sends the event to every channel whose rule allows it and returns one delivery per channel.
+// Every channel gets the same delivery ID, which the outbox keeps for the retries.
+func (m *MultiNotifier) Dispatch(ctx context.Context, event notification.Event) []Delivery {
+	now := m.now()
+	deliveryID := notification.DeliveryIDFromContext(ctx)
+	if deliveryID == "" {
+		deliveryID = uuid.NewString()
+		ctx = notification.WithDeliveryID(ctx, deliveryID)
+	}
+	deliveries := make([]Delivery, len(m.channels))
+
+	var wg sync.WaitGroup
+	for i, ch := range m.channels {
+		if ok, reason := ch.Rule.Allows(event, now); !ok {
+			deliveries[i] = Delivery{Channel: ch.Name, Status: DeliverySkipped, Reason: reason}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, ch Channel) {
+			defer wg.Done()
+			start := time.Now()
+			err := ch.Notifier.Notify(ctx, event)
+			deliveries[i] = Delivery{Channel: ch.Name, Status: DeliverySent, Err: err, Duration: time.Since(start)}
+			if err != nil {
+				deliveries[i].Status = DeliveryFailed
+			}
+		}(i, ch)
+	}
+	wg.Wait()
+
+	if m.outbox != nil {
+		// The event must be stored even when ctx was cancelled by a shutdown
+		queueCtx := context.WithoutCancel(ctx)
+		for i, d := range deliveries {
+			if d.Status != DeliveryFailed {
+				continue
+			}
+			if err := m.enqueue(queueCtx, d.Channel, deliveryID, event, d.Err); err != nil {
+				deliveries[i].Err = errors.Join(d.Err, err)
+				continue
+			}
+			deliveries[i].Status = DeliveryQueued
+			deliveries[i].Err = nil
+			deliveries[i].Reason = d.Err.Error()
+		}
+	}
+
+	for _, d := range deliveries {
+		m.metrics.IncNotifications(d.Channel, d.Status)
+		switch d.Status {
+		case DeliveryFailed:
+			m.logger.Error("Notification delivery failed",
+				zap.String("channel", d.Channel),
+				zap.String("event", string(event.Type())),
+				zap.Duration("duration", d.Duration),
+				zap.Error(d.Err))
+		case DeliveryQueued:
+			m.logger.Warn("Notification delivery failed, queued for retry",
+				zap.String("channel", d.Channel),
+				zap.String("event", string(event.Type())),
+				zap.Duration("duration", d.Duration),
+				zap.String("error", d.Reason))
+		case DeliverySkipped:
+			m.logger.Debug("Notification skipped by channel rule",
+				zap.String("channel", d.Channel),
+				zap.String("event", string(event.Type())),
+				zap.String("reason", d.Reason))
+		default:
+			m.logger.Debug("Notification delivered",
+				zap.String("channel", d.Channel),
+				zap.String("event", string(event.Type())),
+				zap.Duration("duration", d.Duration))
+		}
+	}
+	return deliveries
+}
+
+// enqueue stores one outbox message per failed chat or URL, so a retry does not resend the event
+// to the targets that already received it. Errors not tied to a target queue the whole channel.
+func (m *MultiNotifier) enqueue(ctx context.Context, channel, deliveryID string, event notification.Event, cause error) error {
+	targets, ok := notification.FailedTargets(cause)
+	if !ok {
+		return m.outbox.Enqueue(ctx, channel, "", deliveryID, event, cause)
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := m.outbox.Enqueue(ctx, channel, target.Target, deliveryID, event, target.Err); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func deliveryErrors(deliveries []Delivery) error {
+	var errs []error
+	for _, d := range deliveries {
+		if d.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.Channel, d.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
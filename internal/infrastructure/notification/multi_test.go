@@ -0,0 +1,296 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type recordingNotifier struct {
+	messages []string
+	err      error
+	delay    time.Duration
+}
+
+func (r *recordingNotifier) SendNotification(message string) error {
+	time.Sleep(r.delay)
+	r.messages = append(r.messages, message)
+	return r.err
+}
+
+// Notify records the event type, so the notifier can also be used as a channel directly
+func (r *recordingNotifier) Notify(ctx context.Context, event notification.Event) error {
+	return r.SendNotification(string(event.Type()))
+}
+
+// recordingMetrics only records notification deliveries
+type recordingMetrics struct {
+	metrics.MetricsCollector
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *recordingMetrics) IncNotifications(channel, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[channel+"/"+status]++
+}
+
+func TestMultiNotifier(t *testing.T) {
+	var reports int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reports++
+	}))
+	defer server.Close()
+
+	templates, err := LoadTemplates("", LanguageEnglish, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failing := &recordingNotifier{err: errors.New("telegram down")}
+	text := &recordingNotifier{}
+	multi := NewMultiNotifier(&recordingMetrics{}, zap.NewNop(),
+		Channel{Name: "telegram", Notifier: failing},
+		Channel{Name: "slack", Notifier: NewTemplatedNotifier(ChannelSlack, NewSlackNotifier(server.URL, zap.NewNop()), templates)},
+		Channel{Name: "text", Notifier: NewTemplatedNotifier(ChannelEmail, text, templates)},
+	)
+
+	err = multi.Notify(context.Background(), sampleRunFailed())
+	if err == nil || !strings.Contains(err.Error(), "telegram: telegram down") {
+		t.Fatalf("expected the failing channel's error, got %v", err)
+	}
+	if len(text.messages) != 1 || !strings.Contains(text.messages[0], "Image cleanup failed on node-1") {
+		t.Errorf("expected text channels to receive the rendered event despite the failure, got %q", text.messages)
+	}
+	if reports != 1 {
+		t.Errorf("expected Slack to receive the event, got %d requests", reports)
+	}
+}
+
+func TestMultiNotifierSendsInParallel(t *testing.T) {
+	var channels []Channel
+	for _, name := range []string{"a", "b", "c"} {
+		channels = append(channels, Channel{Name: name, Notifier: &recordingNotifier{delay: 200 * time.Millisecond}})
+	}
+	multi := NewMultiNotifier(&recordingMetrics{}, zap.NewNop(), channels...)
+
+	start := time.Now()
+	if err := multi.Notify(context.Background(), sampleReport()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("expected channels to be sent in parallel, took %v", elapsed)
+	}
+}
+
+func TestMultiNotifierRouting(t *testing.T) {
+	// 23:30 ICT
+	night := time.Date(2025, 1, 2, 16, 30, 0, 0, time.UTC)
+	quiet, err := notification.ParseQuietHours("22:00-07:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	channels := map[string]notification.Rule{
+		"all":      {},
+		"failures": {OnlyFailures: true},
+		"big":      {MinRemoved: 10},
+		"prod":     {Hosts: []string{"prod-*"}},
+		"quiet":    {QuietHours: quiet},
+		"started":  {Events: []notification.EventType{notification.EventRunStarted}},
+	}
+
+	tests := []struct {
+		name  string
+		event notification.Event
+		sent  []string
+	}{
+		{
+			name:  "small successful run at night",
+			event: notification.Report{Host: models.Host{NodeName: "dev-1"}, Removed: 2},
+			sent:  []string{"all"},
+		},
+		{
+			name:  "large run on a production node",
+			event: notification.Report{Host: models.Host{Hostname: "prod-7"}, Removed: 12},
+			sent:  []string{"all", "big", "prod"},
+		},
+		{
+			name:  "failures go through quiet hours",
+			event: notification.Report{Host: models.Host{NodeName: "dev-1"}, Removed: 1, Failed: 1},
+			sent:  []string{"all", "failures", "quiet"},
+		},
+		{
+			name:  "run started only reaches channels selecting it",
+			event: notification.RunStarted{Host: models.Host{NodeName: "dev-1"}},
+			sent:  []string{"started"},
+		},
+		{
+			name:  "failed run ignores the removal threshold",
+			event: notification.RunFailed{Host: models.Host{NodeName: "dev-1"}, Error: "runtime unavailable"},
+			sent:  []string{"all", "failures", "big", "quiet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorders := make(map[string]*recordingNotifier)
+			var list []Channel
+			for name, rule := range channels {
+				recorders[name] = &recordingNotifier{}
+				list = append(list, Channel{Name: name, Notifier: recorders[name], Rule: rule})
+			}
+			collected := &recordingMetrics{}
+			multi := NewMultiNotifier(collected, zap.NewNop(), list...)
+			multi.now = func() time.Time { return night }
+
+			deliveries := multi.Dispatch(context.Background(), tt.event)
+			if len(deliveries) != len(channels) {
+				t.Fatalf("expected one delivery per channel, got %d", len(deliveries))
+			}
+
+			want := make(map[string]bool)
+			for _, name := range tt.sent {
+				want[name] = true
+			}
+			for _, d := range deliveries {
+				wantStatus := DeliverySkipped
+				if want[d.Channel] {
+					wantStatus = DeliverySent
+				}
+				if d.Status != wantStatus {
+					t.Errorf("channel %s: expected %s, got %s (%s)", d.Channel, wantStatus, d.Status, d.Reason)
+				}
+				if d.Status == DeliverySkipped && d.Reason == "" {
+					t.Errorf("channel %s: expected a skip reason", d.Channel)
+				}
+				if got := len(recorders[d.Channel].messages); got != map[bool]int{true: 1}[want[d.Channel]] {
+					t.Errorf("channel %s: expected sent=%v, got %d messages", d.Channel, want[d.Channel], got)
+				}
+				if collected.counts[d.Channel+"/"+d.Status] != 1 {
+					t.Errorf("channel %s: expected the %s delivery to be counted, got %v", d.Channel, d.Status, collected.counts)
+				}
+			}
+		})
+	}
+}
+
+// recordingOutbox stores queued deliveries, failing when err is set
+type recordingOutbox struct {
+	queued      []string
+	targets     []string
+	deliveryIDs []string
+	err         error
+}
+
+func (o *recordingOutbox) Enqueue(ctx context.Context, channel, target, deliveryID string, event notification.Event, cause error) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.queued = append(o.queued, channel+"/"+string(event.Type())+": "+cause.Error())
+	o.targets = append(o.targets, target)
+	o.deliveryIDs = append(o.deliveryIDs, deliveryID)
+	return nil
+}
+
+func TestMultiNotifierQueuesFailedDeliveries(t *testing.T) {
+	collected := &recordingMetrics{}
+	outbox := &recordingOutbox{}
+	multi := NewMultiNotifier(collected, zap.NewNop(),
+		Channel{Name: "telegram", Notifier: &recordingNotifier{err: errors.New("telegram down")}},
+		Channel{Name: "slack", Notifier: &recordingNotifier{}},
+	)
+	multi.SetOutbox(outbox)
+
+	deliveries := multi.Dispatch(context.Background(), sampleReport())
+	if deliveries[0].Status != DeliveryQueued || deliveries[0].Err != nil || deliveries[0].Reason != "telegram down" {
+		t.Errorf("expected the failed delivery to be queued, got %+v", deliveries[0])
+	}
+	if deliveries[1].Status != DeliverySent {
+		t.Errorf("expected the working channel to be sent, got %+v", deliveries[1])
+	}
+	if len(outbox.queued) != 1 || outbox.queued[0] != "telegram/run_completed: telegram down" || outbox.targets[0] != "" {
+		t.Errorf("expected only the failed channel in the outbox, got %q", outbox.queued)
+	}
+	if collected.counts["telegram/queued"] != 1 {
+		t.Errorf("expected the queued delivery to be counted, got %v", collected.counts)
+	}
+
+	if err := multi.Notify(context.Background(), sampleReport()); err != nil {
+		t.Errorf("expected a queued delivery not to be an error, got %v", err)
+	}
+
+	outbox.err = errors.New("database is locked")
+	err := multi.Notify(context.Background(), sampleReport())
+	if err == nil || !strings.Contains(err.Error(), "telegram down") || !strings.Contains(err.Error(), "database is locked") {
+		t.Errorf("expected both errors when the outbox fails, got %v", err)
+	}
+
+	if err := multi.NotifyChannel(context.Background(), "slack", "", sampleRunStarted()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := multi.NotifyChannel(context.Background(), "email", "", sampleRunStarted()); err == nil {
+		t.Error("expected an error for an unknown channel")
+	}
+}
+
+func TestMultiNotifierQueuesFailedTargets(t *testing.T) {
+	good := &webhookReceiver{t: t, secret: "s3cret"}
+	goodServer := httptest.NewServer(good)
+	defer goodServer.Close()
+	flaky := &webhookReceiver{t: t, secret: "s3cret", statuses: []int{http.StatusNotFound}}
+	flakyServer := httptest.NewServer(flaky)
+	defer flakyServer.Close()
+
+	outbox := &recordingOutbox{}
+	multi := NewMultiNotifier(&recordingMetrics{}, zap.NewNop(),
+		Channel{Name: ChannelWebhook, Notifier: newTestWebhook([]string{goodServer.URL, flakyServer.URL})},
+		Channel{Name: ChannelSlack, Notifier: &recordingNotifier{}},
+	)
+	multi.SetOutbox(outbox)
+
+	event := sampleRunFailed()
+	deliveries := multi.Dispatch(context.Background(), event)
+	if deliveries[0].Status != DeliveryQueued {
+		t.Fatalf("expected the webhook delivery to be queued, got %+v", deliveries[0])
+	}
+	if len(outbox.targets) != 1 || outbox.targets[0] != flakyServer.URL || outbox.deliveryIDs[0] == "" {
+		t.Fatalf("expected only the failing URL in the outbox with a delivery ID, got %q %q", outbox.targets, outbox.deliveryIDs)
+	}
+	if len(good.events) != 1 || good.events[0].ID != outbox.deliveryIDs[0] {
+		t.Fatalf("expected the healthy URL to get the event with the queued delivery ID, got %+v", good.events)
+	}
+
+	// The retry from the outbox only reaches the failed URL and keeps the delivery ID
+	ctx := notification.WithDeliveryID(context.Background(), outbox.deliveryIDs[0])
+	if err := multi.NotifyChannel(ctx, ChannelWebhook, outbox.targets[0], event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(good.events) != 1 {
+		t.Errorf("expected the healthy URL not to receive the retry, got %d events", len(good.events))
+	}
+	if len(flaky.events) != 1 || flaky.events[0].ID != outbox.deliveryIDs[0] {
+		t.Errorf("expected the retry to carry the original delivery ID, got %+v", flaky.events)
+	}
+
+	if err := multi.NotifyChannel(ctx, ChannelWebhook, "https://example.invalid/hook", event); err == nil {
+		t.Error("expected an error for a URL that is no longer configured")
+	}
+	if err := multi.NotifyChannel(ctx, ChannelSlack, "x", event); err == nil {
+		t.Error("expected an error for a target of a channel without targets")
+	}
+}
@@ -0,0 +1,129 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SlackNotifier posts Block Kit messages to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+	logger     *zap.Logger
+}
+
+// Verify that SlackNotifier implements ReportNotifier interface
+var _ notification.ReportNotifier = (*SlackNotifier)(nil)
+
+func NewSlackNotifier(webhookURL string, logger *zap.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: constants.NotificationTimeout},
+		logger:     logger,
+	}
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	// Text is the fallback shown in notifications and by clients that cannot render blocks
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks,omitempty"`
+}
+
+// SendNotification posts a plain text message
+func (n *SlackNotifier) SendNotification(message string) error {
+	return n.post(slackMessage{Text: slackEscape(message)})
+}
+
+// SendReport posts the run as a Block Kit message with one field per figure
+func (n *SlackNotifier) SendReport(report notification.Report) error {
+	return n.post(slackReportMessage(report))
+}
+
+func slackReportMessage(report notification.Report) slackMessage {
+	host := hostLabel(report.Host)
+
+	title := "🔄 Image cleanup completed on " + host
+	if report.Failed > 0 {
+		title = "⚠️ Image cleanup on " + host + " completed with failures"
+	}
+
+	field := func(name, value string) slackText {
+		return slackText{Type: "mrkdwn", Text: "*" + name + "*\n" + slackEscape(value)}
+	}
+	hostDetail := host
+	if len(report.Host.IPv4) > 0 {
+		hostDetail += " (" + strings.Join(report.Host.IPv4, ", ") + ")"
+	}
+
+	footer := "Started " + helper.FormatICT(report.StartTime) + " · finished " + helper.FormatICT(report.EndTime)
+	if report.RequestID != "" {
+		footer += " · request ID " + report.RequestID
+	}
+
+	return slackMessage{
+		Text: fmt.Sprintf("%s: %d removed, %d skipped, %d failed",
+			title, report.Removed, report.Skipped, report.Failed),
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: title}},
+			{Type: "section", Fields: []slackText{
+				field("Host", hostDetail),
+				field("Duration", report.Duration.Round(time.Second).String()),
+				field("Removed", strconv.Itoa(report.Removed)+" of "+strconv.Itoa(report.Total)),
+				field("Skipped", strconv.Itoa(report.Skipped)),
+				field("Reclaimed", helper.FormatBytes(report.ReclaimedBytes)),
+				field("Failures", strconv.Itoa(report.Failed)),
+			}},
+			{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: slackEscape(footer)}}},
+		},
+	}
+}
+
+func (n *SlackNotifier) post(message slackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+
+	resp, err := n.client.Post(n.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Slack explains the rejection (e.g. invalid_blocks, no_service) in a short text body
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned non-OK status: %d %s", resp.StatusCode, strings.TrimSpace(string(reason)))
+	}
+
+	n.logger.Info("Successfully sent slack notification")
+	return nil
+}
+
+// slackEscape escapes the characters Slack treats as control sequences in message text
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
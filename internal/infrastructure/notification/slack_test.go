@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"encoding/json"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSlackNotifierSendReport(t *testing.T) {
+	var received slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected JSON content type, got %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := notification.Report{
+		Host:           models.Host{Hostname: "host-1", NodeName: "node<1>", IPv4: []string{"10.0.0.1"}},
+		StartTime:      start,
+		EndTime:        start.Add(90 * time.Second),
+		Duration:       90 * time.Second,
+		Total:          5,
+		Removed:        3,
+		Skipped:        2,
+		Failed:         1,
+		ReclaimedBytes: 3 << 30,
+		RequestID:      "req-1",
+	}
+
+	n := NewSlackNotifier(server.URL, zap.NewNop())
+	if err := n.SendReport(report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received.Blocks) != 3 || received.Blocks[0].Type != "header" {
+		t.Fatalf("unexpected blocks: %+v", received.Blocks)
+	}
+	if !strings.Contains(received.Blocks[0].Text.Text, "failures") {
+		t.Errorf("expected the header to flag failures, got %q", received.Blocks[0].Text.Text)
+	}
+
+	fields := make(map[string]string)
+	for _, f := range received.Blocks[1].Fields {
+		name, value, _ := strings.Cut(f.Text, "\n")
+		fields[strings.Trim(name, "*")] = value
+	}
+	expected := map[string]string{
+		"Host":      "node&lt;1&gt; (10.0.0.1)",
+		"Duration":  "1m30s",
+		"Removed":   "3 of 5",
+		"Skipped":   "2",
+		"Reclaimed": "3.0 GiB",
+		"Failures":  "1",
+	}
+	for name, want := range expected {
+		if fields[name] != want {
+			t.Errorf("field %s: expected %q, got %q", name, want, fields[name])
+		}
+	}
+	if !strings.Contains(received.Blocks[2].Elements[0].Text, "req-1") {
+		t.Errorf("expected the request ID in the context block, got %q", received.Blocks[2].Elements[0].Text)
+	}
+	if received.Text == "" {
+		t.Error("expected a fallback text")
+	}
+}
+
+func TestSlackNotifierReportsRejection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL, zap.NewNop()).SendNotification("hello")
+	if err == nil || !strings.Contains(err.Error(), "no_service") {
+		t.Fatalf("expected the Slack error to be returned, got %v", err)
+	}
+}
@@ -1,49 +1,359 @@
 package notification
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/pkg/constants"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
 
 	"go.uber.org/zap"
 )
 
+// telegramAPIBase is the public Bot API server
+const telegramAPIBase = "https://api.telegram.org"
+
+// telegramMessageLimit is the maximum message length in UTF-16 code units, the unit Telegram counts
+const telegramMessageLimit = 4096
+
+// Telegram parse modes (TELEGRAM_PARSE_MODE)
+const (
+	TelegramParseModeText       = "text"
+	TelegramParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// TelegramParseModes lists every supported parse mode
+var TelegramParseModes = []string{TelegramParseModeText, TelegramParseModeMarkdownV2}
+
+// TelegramConfig configures the Telegram notifier
+type TelegramConfig struct {
+	BotToken     string
+	Chats        []TelegramChat
+	ParseMode    string        // TelegramParseModeText or TelegramParseModeMarkdownV2
+	Timeout      time.Duration // Per request, ignored when Client is set
+	MaxRetries   int           // Extra attempts for network errors, 429 and 5xx
+	RetryBackoff time.Duration // Delay before the first retry of a network error or 5xx, doubled for each following one
+	APIBase      string        // Bot API server, defaults to https://api.telegram.org
+	Client       *http.Client  // Optional, e.g. one with a proxy
+}
+
+// TelegramChat is a chat the notifier posts to, optionally into a forum topic
+type TelegramChat struct {
+	ID       string // Numeric chat ID or @channelusername
+	ThreadID int    // message_thread_id of the topic, 0 for the main chat
+}
+
+func (c TelegramChat) String() string {
+	if c.ThreadID == 0 {
+		return c.ID
+	}
+	return c.ID + ":" + strconv.Itoa(c.ThreadID)
+}
+
+// ParseTelegramChats parses TELEGRAM_CHAT_ID entries of the form <chat_id>[:<thread_id>]
+func ParseTelegramChats(values []string) ([]TelegramChat, error) {
+	chats := make([]TelegramChat, 0, len(values))
+	for _, value := range values {
+		id, thread, hasThread := strings.Cut(value, ":")
+		chat := TelegramChat{ID: id}
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil && (!strings.HasPrefix(id, "@") || len(id) < 2) {
+			return nil, fmt.Errorf("invalid telegram chat %q: expected a numeric chat ID or @channelusername", value)
+		}
+		if hasThread {
+			threadID, err := strconv.Atoi(thread)
+			if err != nil || threadID <= 0 {
+				return nil, fmt.Errorf("invalid telegram chat %q: thread ID must be a positive number", value)
+			}
+			chat.ThreadID = threadID
+		}
+		chats = append(chats, chat)
+	}
+	return chats, nil
+}
+
+// TelegramNotifier sends events as text messages rendered from the notification templates
 type TelegramNotifier struct {
-	botToken string
-	chatID   string
-	logger   *zap.Logger
+	config    TelegramConfig
+	client    *http.Client
+	templates *Templates
+	logger    *zap.Logger
 }
 
-// Verify that TelegramNotifier implements Notifier interface
-var _ notification.Notifier = (*TelegramNotifier)(nil)
+// Verify that TelegramNotifier implements TargetNotifier and Notifier interfaces
+var (
+	_ notification.TargetNotifier = (*TelegramNotifier)(nil)
+	_ notification.Notifier       = (*TelegramNotifier)(nil)
+)
 
-func NewTelegramNotifier(botToken, chatID string, logger *zap.Logger) *TelegramNotifier {
+func NewTelegramNotifier(config TelegramConfig, templates *Templates, logger *zap.Logger) *TelegramNotifier {
+	if config.Timeout <= 0 {
+		config.Timeout = constants.NotificationTimeout
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = constants.TelegramRetryBackoff
+	}
+	if config.APIBase == "" {
+		config.APIBase = telegramAPIBase
+	}
+	if config.ParseMode == "" {
+		config.ParseMode = TelegramParseModeText
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
 	return &TelegramNotifier{
-		botToken: botToken,
-		chatID:   chatID,
-		logger:   logger,
+		config:    config,
+		client:    client,
+		templates: templates,
+		logger:    logger,
+	}
+}
+
+// Notify renders the event with the telegram template for its type and sends it to every chat
+func (n *TelegramNotifier) Notify(ctx context.Context, event notification.Event) error {
+	text, err := n.render(string(event.Type()), event)
+	if err != nil {
+		return err
 	}
+	return n.send(ctx, n.config.Chats, text)
 }
 
+// NotifyTarget sends the event to the one chat whose String() is target, e.g. to retry a failed chat
+func (n *TelegramNotifier) NotifyTarget(ctx context.Context, target string, event notification.Event) error {
+	for _, chat := range n.config.Chats {
+		if chat.String() != target {
+			continue
+		}
+		text, err := n.render(string(event.Type()), event)
+		if err != nil {
+			return err
+		}
+		return n.send(ctx, []TelegramChat{chat}, text)
+	}
+	return fmt.Errorf("telegram chat %s is not configured", target)
+}
+
+// SendNotification sends a plain text message
 func (n *TelegramNotifier) SendNotification(message string) error {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", n.botToken)
+	return n.send(context.Background(), n.config.Chats, n.escape(message))
+}
 
-	resp, err := http.PostForm(apiURL, url.Values{
-		"chat_id": {n.chatID},
-		"text":    {message},
-	})
+// render returns the message text in the configured parse mode. With MarkdownV2 a template
+// written for Telegram is MarkdownV2 source and sent as is; any other template is plain text
+// and gets escaped.
+func (n *TelegramNotifier) render(event string, data any) (string, error) {
+	if n.config.ParseMode == TelegramParseModeMarkdownV2 {
+		text, ok, err := n.templates.Render(ChannelTelegram, event, true, data)
+		if err != nil || ok {
+			return text, err
+		}
+	}
+
+	text, ok, err := n.templates.Render(ChannelTelegram, event, false, data)
 	if err != nil {
-		return fmt.Errorf("failed to send telegram message: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
+	if !ok {
+		return "", fmt.Errorf("no telegram template for %s events", event)
+	}
+	return n.escape(text), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API returned non-OK status: %d", resp.StatusCode)
+func (n *TelegramNotifier) escape(text string) string {
+	if n.config.ParseMode == TelegramParseModeMarkdownV2 {
+		return EscapeMarkdownV2(text)
 	}
+	return text
+}
 
-	n.logger.Info("Successfully sent telegram notification",
-		zap.String("chat_id", n.chatID))
+// send delivers the text to the chats, split into several messages when it is too long.
+// One failing chat does not stop the others; each failure is a TargetError so only that chat is retried.
+func (n *TelegramNotifier) send(ctx context.Context, chats []TelegramChat, text string) error {
+	parts := splitMessage(text, telegramMessageLimit)
+
+	var errs []error
+	for _, chat := range chats {
+		if err := n.sendTo(ctx, chat, parts); err != nil {
+			errs = append(errs, &notification.TargetError{Target: chat.String(), Err: fmt.Errorf("chat %s: %w", chat, err)})
+		}
+	}
+	return errors.Join(errs...)
+}
 
+func (n *TelegramNotifier) sendTo(ctx context.Context, chat TelegramChat, parts []string) error {
+	for i, part := range parts {
+		if err := n.sendMessage(ctx, chat, part); err != nil {
+			if len(parts) > 1 {
+				return fmt.Errorf("part %d of %d: %w", i+1, len(parts), err)
+			}
+			return err
+		}
+	}
+
+	n.logger.Info("Successfully sent telegram notification",
+		zap.String("chat_id", chat.String()),
+		zap.Int("parts", len(parts)))
 	return nil
 }
+
+// sendMessage sends one message, waiting for retry_after when rate limited and backing off
+// after network errors and 5xx
+func (n *TelegramNotifier) sendMessage(ctx context.Context, chat TelegramChat, text string) error {
+	backoff := n.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, retryAfter, err := n.post(ctx, chat, text)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.config.MaxRetries {
+			return err
+		}
+
+		wait := retryAfter
+		if wait == 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		n.logger.Warn("Telegram delivery failed, will retry",
+			zap.String("chat_id", chat.String()),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("wait", wait),
+			zap.Error(err))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (retry aborted: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// telegramResponse is the part of a Bot API response needed to report and retry errors
+type telegramResponse struct {
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// post makes one sendMessage call and reports whether a failure is worth retrying and how long
+// Telegram asked to wait
+func (n *TelegramNotifier) post(ctx context.Context, chat TelegramChat, text string) (bool, time.Duration, error) {
+	form := url.Values{
+		"chat_id": {chat.ID},
+		"text":    {text},
+	}
+	if chat.ThreadID != 0 {
+		form.Set("message_thread_id", strconv.Itoa(chat.ThreadID))
+	}
+	if n.config.ParseMode == TelegramParseModeMarkdownV2 {
+		form.Set("parse_mode", TelegramParseModeMarkdownV2)
+	}
+
+	apiURL := fmt.Sprintf("%s/bot%s/sendMessage", n.config.APIBase, n.config.BotToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to create telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The URL contains the bot token, so only the underlying error is reported
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return ctx.Err() == nil, 0, fmt.Errorf("failed to send telegram message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return false, 0, nil
+	}
+
+	var body telegramResponse
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = json.Unmarshal(raw, &body)
+	err = fmt.Errorf("telegram API returned status %d: %s", resp.StatusCode, body.Description)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter := time.Duration(body.Parameters.RetryAfter) * time.Second
+		if retryAfter == 0 {
+			if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+		// Waiting longer would hold up the other channels; the outbox retries the message later
+		if retryAfter > constants.TelegramMaxRetryAfter {
+			return false, 0, err
+		}
+		return true, retryAfter, err
+	case resp.StatusCode >= 500:
+		return true, 0, err
+	}
+	return false, 0, err
+}
+
+// markdownV2Escaper escapes every character that has a meaning in MarkdownV2
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, `_`, `\_`, `*`, `\*`, `[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`, `~`, `\~`,
+	"`", "\\`", `>`, `\>`, `#`, `\#`, `+`, `\+`, `-`, `\-`, `=`, `\=`, `|`, `\|`,
+	`{`, `\{`, `}`, `\}`, `.`, `\.`, `!`, `\!`,
+)
+
+// EscapeMarkdownV2 makes text display literally in a Telegram MarkdownV2 message
+func EscapeMarkdownV2(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
+
+// splitMessage cuts text into parts of at most limit UTF-16 code units, preferring line breaks.
+// A part never ends in the middle of a MarkdownV2 escape sequence.
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	for {
+		end := fitLength(text, limit)
+		if end == len(text) {
+			return append(parts, text)
+		}
+
+		cut := strings.LastIndexByte(text[:end], '\n')
+		if cut <= 0 {
+			cut = end
+			// Do not separate a backslash from the character it escapes
+			if backslashes := len(text[:cut]) - len(strings.TrimRight(text[:cut], `\`)); backslashes%2 == 1 {
+				cut--
+			}
+		}
+		parts = append(parts, strings.TrimRight(text[:cut], "\n"))
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+}
+
+// fitLength returns the length in bytes of the longest prefix of text within limit UTF-16 code units
+func fitLength(text string, limit int) int {
+	units := 0
+	for i, r := range text {
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		if units+n > limit {
+			return i
+		}
+		units += n
+	}
+	return len(text)
+}
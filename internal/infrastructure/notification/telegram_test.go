@@ -0,0 +1,311 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/notification"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"go.uber.org/zap"
+)
+
+// stubBotAPI records sendMessage calls and answers with the queued responses, then 200
+type stubBotAPI struct {
+	mu        sync.Mutex
+	received  []url.Values
+	responses []func(w http.ResponseWriter, form url.Values)
+}
+
+func newStubBotAPI(t *testing.T) (*stubBotAPI, *httptest.Server) {
+	stub := &stubBotAPI{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot123:abc/sendMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+
+		stub.mu.Lock()
+		stub.received = append(stub.received, r.PostForm)
+		var respond func(w http.ResponseWriter, form url.Values)
+		if len(stub.responses) > 0 {
+			respond, stub.responses = stub.responses[0], stub.responses[1:]
+		}
+		stub.mu.Unlock()
+
+		if respond != nil {
+			respond(w, r.PostForm)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{}}`)
+	}))
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (s *stubBotAPI) respond(status int, body string) {
+	s.responses = append(s.responses, func(w http.ResponseWriter, form url.Values) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+}
+
+func newTestTelegramNotifier(t *testing.T, server *httptest.Server, templates *Templates, config TelegramConfig) *TelegramNotifier {
+	if templates == nil {
+		var err error
+		if templates, err = LoadTemplates("", LanguageEnglish, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	config.BotToken = "123:abc"
+	config.APIBase = server.URL
+	config.RetryBackoff = time.Millisecond
+	if config.Chats == nil {
+		config.Chats = []TelegramChat{{ID: "-10042"}}
+	}
+	return NewTelegramNotifier(config, templates, zap.NewNop())
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	stub, server := newStubBotAPI(t)
+	n := newTestTelegramNotifier(t, server, nil, TelegramConfig{})
+
+	if err := n.Notify(context.Background(), sampleRunStarted()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stub.received) != 1 || stub.received[0].Get("chat_id") != "-10042" || stub.received[0].Has("parse_mode") ||
+		!strings.HasPrefix(stub.received[0].Get("text"), "▶️ Image cleanup started on node-1 at 2025-01-01 07:00:00 ICT") {
+		t.Fatalf("expected the rendered run started message as plain text, got %v", stub.received)
+	}
+
+	stub.respond(http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`)
+	err := n.Notify(context.Background(), sampleReport())
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("expected the API status and description in the error, got %v", err)
+	}
+	if len(stub.received) != 2 {
+		t.Errorf("expected a 400 not to be retried, got %d requests", len(stub.received))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := n.Notify(ctx, sampleReport()); err == nil {
+		t.Error("expected a cancelled context to abort the send")
+	}
+	if len(stub.received) != 2 {
+		t.Errorf("expected no request after cancellation, got %d requests", len(stub.received))
+	}
+}
+
+func TestTelegramNotifierRetries(t *testing.T) {
+	t.Run("rate limited", func(t *testing.T) {
+		stub, server := newStubBotAPI(t)
+		n := newTestTelegramNotifier(t, server, nil, TelegramConfig{MaxRetries: 3})
+
+		stub.respond(http.StatusTooManyRequests, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+		start := time.Now()
+		if err := n.Notify(context.Background(), sampleReport()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("expected to wait retry_after before retrying, took %v", elapsed)
+		}
+		if len(stub.received) != 2 {
+			t.Errorf("expected one retry, got %d requests", len(stub.received))
+		}
+	})
+
+	t.Run("retry_after too long", func(t *testing.T) {
+		stub, server := newStubBotAPI(t)
+		n := newTestTelegramNotifier(t, server, nil, TelegramConfig{MaxRetries: 3})
+
+		stub.respond(http.StatusTooManyRequests, `{"ok":false,"error_code":429,"parameters":{"retry_after":3600}}`)
+		if err := n.Notify(context.Background(), sampleReport()); err == nil || !strings.Contains(err.Error(), "429") {
+			t.Errorf("expected the rate limit error, got %v", err)
+		}
+		if len(stub.received) != 1 {
+			t.Errorf("expected no retry, got %d requests", len(stub.received))
+		}
+	})
+
+	t.Run("server errors", func(t *testing.T) {
+		stub, server := newStubBotAPI(t)
+		n := newTestTelegramNotifier(t, server, nil, TelegramConfig{MaxRetries: 2})
+
+		for i := 0; i < 3; i++ {
+			stub.respond(http.StatusBadGateway, `{"ok":false,"error_code":502,"description":"Bad Gateway"}`)
+		}
+		if err := n.Notify(context.Background(), sampleReport()); err == nil || !strings.Contains(err.Error(), "502") {
+			t.Errorf("expected the last error after the retries, got %v", err)
+		}
+		if len(stub.received) != 3 {
+			t.Errorf("expected 2 retries, got %d requests", len(stub.received))
+		}
+	})
+
+	t.Run("token not leaked", func(t *testing.T) {
+		n := NewTelegramNotifier(TelegramConfig{BotToken: "123:secret", Chats: []TelegramChat{{ID: "1"}}, APIBase: "http://127.0.0.1:1"}, nil, zap.NewNop())
+		if err := n.SendNotification("hello"); err == nil || strings.Contains(err.Error(), "secret") {
+			t.Errorf("expected a network error without the bot token, got %v", err)
+		}
+	})
+}
+
+func TestTelegramNotifierChats(t *testing.T) {
+	stub, server := newStubBotAPI(t)
+	n := newTestTelegramNotifier(t, server, nil, TelegramConfig{
+		Chats: []TelegramChat{{ID: "-1001"}, {ID: "-1002", ThreadID: 7}, {ID: "@ops"}},
+	})
+
+	stub.respond(http.StatusForbidden, `{"ok":false,"error_code":403,"description":"Forbidden: bot was kicked"}`)
+	err := n.Notify(context.Background(), sampleRunFailed())
+	if err == nil || !strings.Contains(err.Error(), "chat -1001") {
+		t.Errorf("expected the failing chat in the error, got %v", err)
+	}
+	if len(stub.received) != 3 {
+		t.Fatalf("expected every chat to be sent to despite the failure, got %d requests", len(stub.received))
+	}
+	if got := stub.received[1]; got.Get("chat_id") != "-1002" || got.Get("message_thread_id") != "7" {
+		t.Errorf("expected the topic of the second chat, got %v", got)
+	}
+	if got := stub.received[2]; got.Get("chat_id") != "@ops" || got.Has("message_thread_id") {
+		t.Errorf("expected the main chat of the channel, got %v", got)
+	}
+}
+
+func TestTelegramNotifierTargets(t *testing.T) {
+	stub, server := newStubBotAPI(t)
+	n := newTestTelegramNotifier(t, server, nil, TelegramConfig{
+		Chats: []TelegramChat{{ID: "-1001"}, {ID: "-1002", ThreadID: 7}},
+	})
+
+	stub.respond(http.StatusOK, `{"ok":true,"result":{}}`)
+	stub.respond(http.StatusForbidden, `{"ok":false,"error_code":403,"description":"Forbidden: bot was kicked"}`)
+	err := n.Notify(context.Background(), sampleRunFailed())
+	targets, ok := notification.FailedTargets(err)
+	if !ok || len(targets) != 1 || targets[0].Target != "-1002:7" {
+		t.Fatalf("expected only the failing chat as a target, got %v (%v)", targets, err)
+	}
+
+	// Retrying the target only sends to that chat
+	if err := n.NotifyTarget(context.Background(), targets[0].Target, sampleRunFailed()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stub.received) != 3 || stub.received[2].Get("chat_id") != "-1002" || stub.received[2].Get("message_thread_id") != "7" {
+		t.Errorf("expected the retry to reach only the failed chat, got %v", stub.received)
+	}
+	if err := n.NotifyTarget(context.Background(), "-1003", sampleRunFailed()); err == nil {
+		t.Error("expected an error for a chat that is not configured")
+	}
+}
+
+func TestTelegramNotifierMarkdownV2(t *testing.T) {
+	dir := t.TempDir()
+	custom := "*Cleanup failed* on {{escapeMarkdown (host .Host)}}: {{escapeMarkdown .Error}}"
+	if err := os.WriteFile(filepath.Join(dir, "telegram.run_failed.tmpl"), []byte(custom), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	templates, err := LoadTemplates(dir, LanguageEnglish, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stub, server := newStubBotAPI(t)
+	n := newTestTelegramNotifier(t, server, templates, TelegramConfig{ParseMode: TelegramParseModeMarkdownV2})
+
+	if err := n.Notify(context.Background(), sampleDiskPressure()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	failed := sampleRunFailed()
+	failed.Error = "containerd.sock not found (exit 1)"
+	if err := n.Notify(context.Background(), failed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	generic := stub.received[0]
+	if generic.Get("parse_mode") != "MarkdownV2" || !strings.Contains(generic.Get("text"), `90\.0% full \(90\.0 GiB`) {
+		t.Errorf("expected the built-in text to be escaped, got %q", generic.Get("text"))
+	}
+	if got := stub.received[1].Get("text"); got != `*Cleanup failed* on node\-1: containerd\.sock not found \(exit 1\)` {
+		t.Errorf("expected the Telegram template to be sent as MarkdownV2, got %q", got)
+	}
+}
+
+func TestTelegramNotifierSplitsLongMessages(t *testing.T) {
+	stub, server := newStubBotAPI(t)
+	n := newTestTelegramNotifier(t, server, nil, TelegramConfig{ParseMode: TelegramParseModeMarkdownV2})
+
+	var lines []string
+	for i := 0; i < 600; i++ {
+		lines = append(lines, fmt.Sprintf("🗑 image-%03d.tar", i))
+	}
+	if err := n.SendNotification(strings.Join(lines, "\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stub.received) < 3 {
+		t.Fatalf("expected the message to be split, got %d requests", len(stub.received))
+	}
+	var joined []string
+	for _, form := range stub.received {
+		text := form.Get("text")
+		if units := len(utf16.Encode([]rune(text))); units > telegramMessageLimit {
+			t.Errorf("expected parts within %d UTF-16 units, got %d", telegramMessageLimit, units)
+		}
+		joined = append(joined, text)
+	}
+	if got := strings.Join(joined, "\n"); got != EscapeMarkdownV2(strings.Join(lines, "\n")) {
+		t.Error("expected the parts to add up to the whole message, split at line breaks")
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"short", "hello", 10, []string{"hello"}},
+		{"line breaks", "aaa\nbbb\nccc", 8, []string{"aaa\nbbb", "ccc"}},
+		{"long line", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"escape sequence", `abc\.def`, 4, []string{"abc", `\.de`, "f"}},
+		{"surrogate pairs", "😀😀😀", 4, []string{"😀😀", "😀"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTelegramChats(t *testing.T) {
+	chats, err := ParseTelegramChats([]string{"-10042", "-1001234:15", "@ops"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []TelegramChat{{ID: "-10042"}, {ID: "-1001234", ThreadID: 15}, {ID: "@ops"}}
+	if fmt.Sprint(chats) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, chats)
+	}
+
+	for _, value := range []string{"ops", "@", "-100:topic", "-100:0", ""} {
+		if _, err := ParseTelegramChats([]string{value}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
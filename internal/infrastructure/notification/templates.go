@@ -0,0 +1,352 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/pkg/helper"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Built-in template languages (NOTIFY_LANGUAGE)
+const (
+	LanguageEnglish    = "en"
+	LanguageVietnamese = "vi"
+)
+
+// Languages lists every built-in template language
+var Languages = []string{LanguageEnglish, LanguageVietnamese}
+
+// EventDigest is the template name of the periodic summary, data is notification.Digest.
+// Every other template is named after a notification.EventType and receives that event.
+const EventDigest = "digest"
+
+// templateEvents maps each template event to sample data used to validate templates at startup
+var templateEvents = map[string]any{
+	string(notification.EventRunStarted):   sampleRunStarted(),
+	string(notification.EventRunCompleted): sampleReport(),
+	string(notification.EventRunFailed):    sampleRunFailed(),
+	string(notification.EventDiskPressure): sampleDiskPressure(),
+	string(notification.EventImageDeleted): sampleImageDeleted(),
+	EventDigest:                            sampleDigest(),
+}
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// Templates renders notification text from Go text/template templates.
+//
+// A template is looked up as "<channel>.<event>", then "<event>", then the built-in
+// template of the configured language. Channels with their own layout (Slack, email) only
+// use a template written for them, so a generic template never replaces a richer format.
+// Webhooks send typed JSON events and never use templates.
+type Templates struct {
+	templates map[string]*template.Template // Keyed by "<channel>.<event>" or "<event>"
+	builtin   map[string]*template.Template // Keyed by event
+}
+
+// LoadTemplates parses the built-in templates for language and every *.tmpl file in dir,
+// and renders each against sample data so that mistakes fail at startup. An empty timezone
+// formats times in ICT.
+func LoadTemplates(dir, language, timezone string) (*Templates, error) {
+	if !slices.Contains(Languages, language) {
+		return nil, fmt.Errorf("unsupported language %q (expected %s)", language, strings.Join(Languages, " or "))
+	}
+	funcs, err := templateFuncs(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{
+		templates: make(map[string]*template.Template),
+		builtin:   make(map[string]*template.Template),
+	}
+
+	entries, err := builtinTemplates.ReadDir("templates/" + language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built-in templates: %w", err)
+	}
+	for _, entry := range entries {
+		event := strings.TrimSuffix(entry.Name(), ".tmpl")
+		content, err := builtinTemplates.ReadFile("templates/" + language + "/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read built-in template %s: %w", entry.Name(), err)
+		}
+		if t.builtin[event], err = parseTemplate(event, event, string(content), funcs); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return t, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates in %s: %w", dir, err)
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".tmpl")
+		event := name
+		if channel, channelEvent, ok := strings.Cut(name, "."); ok {
+			if !slices.Contains(Channels, channel) {
+				return nil, fmt.Errorf("template %s: unknown channel %q (expected one of %s)", file, channel, strings.Join(Channels, ", "))
+			}
+			if channel == ChannelWebhook {
+				return nil, fmt.Errorf("template %s: webhooks send typed JSON events and do not use templates", file)
+			}
+			event = channelEvent
+		}
+		if _, ok := templateEvents[event]; !ok {
+			return nil, fmt.Errorf("template %s: unknown event %q (expected one of %s)", file, event, strings.Join(templateEventNames(), ", "))
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		if t.templates[name], err = parseTemplate(file, event, string(content), funcs); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func templateEventNames() []string {
+	names := make([]string, 0, len(templateEvents))
+	for name := range templateEvents {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func parseTemplate(name, event, content string, funcs template.FuncMap) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	// Fields are only resolved when the template runs, so try it on sample data
+	if err := tmpl.Execute(&bytes.Buffer{}, templateEvents[event]); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Names returns the custom templates that were loaded
+func (t *Templates) Names() []string {
+	names := make([]string, 0, len(t.templates))
+	for name := range t.templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Render returns the text for event on channel. ok is false when the channel should use its
+// own layout: it has one (native) and no template was written for that channel.
+func (t *Templates) Render(channel, event string, native bool, data any) (text string, ok bool, err error) {
+	tmpl := t.templates[channel+"."+event]
+	if tmpl == nil && !native {
+		tmpl = t.templates[event]
+		if tmpl == nil {
+			tmpl = t.builtin[event]
+		}
+	}
+	if tmpl == nil {
+		return "", false, nil
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", false, fmt.Errorf("failed to render %s template: %w", event, err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), true, nil
+}
+
+// templateFuncs returns the helpers available to templates
+func templateFuncs(timezone string) (template.FuncMap, error) {
+	inZone := helper.TimeInICT
+	zoneLayout := "2006-01-02 15:04:05 ICT"
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		inZone = func(t time.Time) time.Time { return t.In(loc) }
+		zoneLayout = "2006-01-02 15:04:05 MST"
+	}
+
+	return template.FuncMap{
+		// {{bytes .ReclaimedBytes}} -> "1.5 GiB"
+		"bytes": helper.FormatBytes,
+		// {{duration .Duration}} -> "1m30s"
+		"duration": func(d time.Duration) string { return d.Round(time.Second).String() },
+		// {{time .StartTime}} in NOTIFY_TIMEZONE
+		"time": func(t time.Time) string { return inZone(t).Format(zoneLayout) },
+		// {{formatTime "02/01 15:04" .StartTime}} in NOTIFY_TIMEZONE
+		"formatTime": func(layout string, t time.Time) string { return inZone(t).Format(layout) },
+		// {{timeIn "Europe/Berlin" .StartTime}}
+		"timeIn": func(name string, t time.Time) (string, error) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				return "", err
+			}
+			return t.In(loc).Format("2006-01-02 15:04:05 MST"), nil
+		},
+		// {{host .Host}} -> node name, or hostname when unset
+		"host": hostLabel,
+		"join": strings.Join,
+		// {{escapeMarkdown .Error}} in telegram.<event>.tmpl with TELEGRAM_PARSE_MODE=MarkdownV2
+		"escapeMarkdown": EscapeMarkdownV2,
+	}, nil
+}
+
+var (
+	sampleStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampleHost  = models.Host{Hostname: "node-1", NodeName: "node-1", IPv4: []string{"10.0.0.1"}, Labels: map[string]string{"env": "prod"}}
+)
+
+func sampleRunStarted() notification.RunStarted {
+	return notification.RunStarted{Host: sampleHost, StartTime: sampleStart, RequestID: "sample"}
+}
+
+func sampleRunFailed() notification.RunFailed {
+	return notification.RunFailed{Host: sampleHost, StartTime: sampleStart, Error: "sample", RequestID: "sample"}
+}
+
+func sampleDiskPressure() notification.DiskPressure {
+	return notification.DiskPressure{
+		Host:             sampleHost,
+		Path:             "/var/lib/containerd",
+		UsedBytes:        90 << 30,
+		TotalBytes:       100 << 30,
+		UsedPercent:      90,
+		ThresholdPercent: 85,
+	}
+}
+
+func sampleImageDeleted() notification.ImageDeleted {
+	return notification.ImageDeleted{
+		Host:      sampleHost,
+		ImageID:   "sha256:sample",
+		Tags:      []string{"registry.local/app:1.0"},
+		SizeBytes: 512 << 20,
+		RequestID: "sample",
+	}
+}
+
+func sampleReport() notification.Report {
+	start := sampleStart
+	host := sampleHost
+	return notification.Report{
+		Host:           host,
+		HostInfo:       host.String(),
+		StartTime:      start,
+		EndTime:        start.Add(time.Minute),
+		Duration:       time.Minute,
+		Total:          3,
+		Removed:        2,
+		Skipped:        1,
+		Failed:         1,
+		ReclaimedBytes: 1 << 30,
+		RequestID:      "sample",
+		Message:        "sample",
+	}
+}
+
+func sampleDigest() notification.Digest {
+	report := sampleReport()
+	return notification.Digest{
+		Host:          report.Host,
+		From:          report.StartTime.Add(-7 * 24 * time.Hour),
+		To:            report.StartTime,
+		Runs:          1,
+		Total:         report.Total,
+		Removed:       report.Removed,
+		Skipped:       report.Skipped,
+		TotalDuration: report.Duration,
+		LongestRun:    report.Duration,
+		Results: []repositories.CleanupResult{{
+			ID:         "sample",
+			HostInfo:   report.HostInfo,
+			Host:       report.Host,
+			StartTime:  report.StartTime,
+			EndTime:    report.EndTime,
+			Duration:   report.Duration,
+			TotalCount: report.Total,
+			Removed:    report.Removed,
+			Skipped:    report.Skipped,
+		}},
+	}
+}
+
+// TemplatedNotifier adapts a text-only channel to typed events: every event is rendered
+// from Templates, except run reports on channels with their own layout (Slack, email)
+type TemplatedNotifier struct {
+	channel   string
+	next      notification.Notifier
+	templates *Templates
+}
+
+// Verify that TemplatedNotifier implements EventNotifier and DigestNotifier interfaces
+var (
+	_ notification.EventNotifier  = (*TemplatedNotifier)(nil)
+	_ notification.DigestNotifier = (*TemplatedNotifier)(nil)
+)
+
+func NewTemplatedNotifier(channel string, next notification.Notifier, templates *Templates) *TemplatedNotifier {
+	return &TemplatedNotifier{
+		channel:   channel,
+		next:      next,
+		templates: templates,
+	}
+}
+
+// Notify sends the rendered event, or lets the channel format a run report itself when no template applies.
+// The wrapped channel cannot be interrupted, so ctx is only checked before sending.
+func (n *TemplatedNotifier) Notify(ctx context.Context, event notification.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	report, isReport := event.(notification.Report)
+	_, native := n.next.(notification.ReportNotifier)
+	native = native && isReport
+	text, ok, err := n.templates.Render(n.channel, string(event.Type()), native, event)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return n.next.SendNotification(text)
+	}
+	if !native {
+		return fmt.Errorf("channel %s has no %s template", n.channel, event.Type())
+	}
+	return notification.Send(n.next, report)
+}
+
+// SendDigest sends the rendered digest, or the channel's own digest when no template applies
+func (n *TemplatedNotifier) SendDigest(digest notification.Digest) error {
+	dn, native := n.next.(notification.DigestNotifier)
+	text, ok, err := n.templates.Render(n.channel, EventDigest, native, digest)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return n.next.SendNotification(text)
+	}
+	if !native {
+		return fmt.Errorf("channel %s cannot send digests without a %s template", n.channel, EventDigest)
+	}
+	return dn.SendDigest(digest)
+}
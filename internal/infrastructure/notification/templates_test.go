@@ -0,0 +1,203 @@
+package notification
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/pkg/helper"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const runCompleted = string(notification.EventRunCompleted)
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	report := sampleReport()
+	report.Failed = 0
+	report.ReclaimedBytes = 0
+
+	en, err := LoadTemplates("", LanguageEnglish, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, ok, err := en.Render(ChannelTelegram, runCompleted, false, report)
+	if err != nil || !ok {
+		t.Fatalf("expected the built-in template to render, got ok=%v err=%v", ok, err)
+	}
+	want := helper.FormatCleanupMessage(report.HostInfo, report.StartTime, report.EndTime, report.Duration,
+		report.Total, report.Removed, report.Skipped, report.RequestID)
+	if text != want {
+		t.Errorf("expected the English template to match the default message\nwant:\n%s\ngot:\n%s", want, text)
+	}
+
+	vi, err := LoadTemplates("", LanguageVietnamese, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, _, err = vi.Render(ChannelTelegram, runCompleted, false, sampleReport())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Đã xóa: 2", "Xóa lỗi: 1", "Giải phóng: 1.0 GiB", "2025-01-01 07:00:00 ICT"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected the Vietnamese template to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	// Every event has a built-in template in every language
+	for _, language := range Languages {
+		templates, err := LoadTemplates("", language, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, event := range notification.EventTypes {
+			if text, ok, err := templates.Render(ChannelTelegram, string(event), false, templateEvents[string(event)]); err != nil || !ok || text == "" {
+				t.Errorf("%s: expected a built-in %s template, got %q (ok=%v, err=%v)", language, event, text, ok, err)
+			}
+		}
+	}
+
+	en, _ = LoadTemplates("", LanguageEnglish, "")
+	text, _, _ = en.Render(ChannelTelegram, string(notification.EventDiskPressure), false, sampleDiskPressure())
+	if want := "/var/lib/containerd is 90.0% full (90.0 GiB of 100.0 GiB) after cleanup, above the 85% threshold."; !strings.Contains(text, want) {
+		t.Errorf("expected the disk pressure message to contain %q, got:\n%s", want, text)
+	}
+}
+
+func TestCustomTemplates(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"run_completed.tmpl":       `{{host .Host}} removed {{.Removed}} at {{time .StartTime}}`,
+		"slack.run_completed.tmpl": `*{{.Removed}}* removed, {{bytes .ReclaimedBytes}} in {{duration .Duration}} ({{timeIn "UTC" .StartTime}})`,
+	})
+	templates, err := LoadTemplates(dir, LanguageEnglish, "Europe/Berlin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := sampleReport()
+
+	tests := []struct {
+		name    string
+		channel string
+		native  bool
+		want    string
+		wantOK  bool
+	}{
+		{name: "generic template for text channels", channel: ChannelTelegram, want: "node-1 removed 2 at 2025-01-01 01:00:00 CET", wantOK: true},
+		{name: "channel template overrides the native layout", channel: ChannelSlack, native: true, want: "*2* removed, 1.0 GiB in 1m0s (2025-01-01 00:00:00 UTC)", wantOK: true},
+		{name: "native layout wins over generic templates", channel: ChannelEmail, native: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, ok, err := templates.Render(tt.channel, runCompleted, tt.native, report)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantOK || text != tt.want {
+				t.Errorf("expected %q (ok=%v), got %q (ok=%v)", tt.want, tt.wantOK, text, ok)
+			}
+		})
+	}
+}
+
+func TestTemplatesAreValidatedAtLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		language string
+		timezone string
+		wantErr  string
+	}{
+		{name: "unknown field", files: map[string]string{"run_completed.tmpl": "{{.Removd}}"}, wantErr: "Removd"},
+		{name: "syntax error", files: map[string]string{"run_completed.tmpl": "{{if .Removed}}"}, wantErr: "invalid template"},
+		{name: "field of another event", files: map[string]string{"email.digest.tmpl": "{{.RequestID}}"}, wantErr: "RequestID"},
+		{name: "unknown event", files: map[string]string{"started.tmpl": "x"}, wantErr: `unknown event "started"`},
+		{name: "unknown channel", files: map[string]string{"pager.run_completed.tmpl": "x"}, wantErr: `unknown channel "pager"`},
+		{name: "webhook template", files: map[string]string{"webhook.run_failed.tmpl": "x"}, wantErr: "do not use templates"},
+		{name: "unknown zone in template", files: map[string]string{"run_completed.tmpl": `{{timeIn "Mars/Base" .StartTime}}`}, wantErr: "Mars/Base"},
+		{name: "unsupported language", language: "fr", wantErr: "unsupported language"},
+		{name: "unknown timezone", timezone: "Nowhere/City", wantErr: "invalid timezone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language := tt.language
+			if language == "" {
+				language = LanguageEnglish
+			}
+			_, err := LoadTemplates(writeTemplates(t, tt.files), language, tt.timezone)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// reportCounter is a text notifier that can also format reports itself
+type reportCounter struct {
+	*recordingNotifier
+	reports *int
+}
+
+func (r reportCounter) SendReport(report notification.Report) error {
+	*r.reports++
+	return nil
+}
+
+func TestTemplatedNotifier(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{"email.digest.tmpl": "{{.Runs}} runs"})
+	templates, err := LoadTemplates(dir, LanguageVietnamese, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := &recordingNotifier{}
+	telegram := NewTemplatedNotifier(ChannelTelegram, text, templates)
+	if err := telegram.Notify(context.Background(), sampleReport()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(text.messages) != 1 || !strings.Contains(text.messages[0], "Đã dọn dẹp image") {
+		t.Errorf("expected the Vietnamese report, got %q", text.messages)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := telegram.Notify(ctx, sampleReport()); err == nil || len(text.messages) != 1 {
+		t.Errorf("expected a cancelled context to stop the send, got %v and %d messages", err, len(text.messages))
+	}
+	if err := telegram.SendDigest(sampleDigest()); err == nil {
+		t.Error("expected an error for a digest on a channel without a digest template")
+	}
+
+	email := &recordingNotifier{}
+	if err := NewTemplatedNotifier(ChannelEmail, email, templates).SendDigest(sampleDigest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(email.messages) != 1 || email.messages[0] != "1 runs" {
+		t.Errorf("expected the email digest template, got %q", email.messages)
+	}
+
+	// Slack keeps its Block Kit layout for reports without a slack.run_completed template,
+	// other events are rendered from the built-in templates
+	var received int
+	slack := &recordingNotifier{}
+	n := NewTemplatedNotifier(ChannelSlack, reportCounter{recordingNotifier: slack, reports: &received}, templates)
+	if err := n.Notify(context.Background(), sampleReport()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := n.Notify(context.Background(), sampleImageDeleted()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received != 1 || len(slack.messages) != 1 || !strings.Contains(slack.messages[0], "Đã xóa registry.local/app:1.0 (512.0 MiB)") {
+		t.Errorf("expected the native report and a rendered image event, got %d reports and %q", received, slack.messages)
+	}
+}
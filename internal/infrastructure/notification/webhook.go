@@ -0,0 +1,308 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/pkg/constants"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Webhook event types
+const (
+	WebhookEventCleanupStarted   = "cleanup.started"
+	WebhookEventCleanupCompleted = "cleanup.completed"
+	WebhookEventCleanupFailed    = "cleanup.failed"
+	WebhookEventDiskPressure     = "disk.pressure"
+	WebhookEventImageDeleted     = "image.deleted"
+	WebhookEventMessage          = "message"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookHeaderEvent     = "X-Image-Cleanup-Event"
+	WebhookHeaderDelivery  = "X-Image-Cleanup-Delivery"
+	WebhookHeaderTimestamp = "X-Image-Cleanup-Timestamp"
+	WebhookHeaderSignature = "X-Image-Cleanup-Signature"
+)
+
+// WebhookConfig configures the webhook notifier
+type WebhookConfig struct {
+	URLs         []string
+	Secret       string        // HMAC-SHA256 key used to sign every delivery
+	Timeout      time.Duration // Per attempt
+	MaxRetries   int           // Extra attempts after the first one for network errors, 429 and 5xx
+	RetryBackoff time.Duration // Delay before the first retry, doubled for each following one
+}
+
+// WebhookNotifier POSTs signed JSON events to one or more URLs
+type WebhookNotifier struct {
+	config WebhookConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// Verify that WebhookNotifier implements TargetNotifier and ReportNotifier interfaces
+var (
+	_ notification.TargetNotifier = (*WebhookNotifier)(nil)
+	_ notification.ReportNotifier = (*WebhookNotifier)(nil)
+)
+
+func NewWebhookNotifier(config WebhookConfig, logger *zap.Logger) *WebhookNotifier {
+	if config.Timeout <= 0 {
+		config.Timeout = constants.NotificationTimeout
+	}
+	return &WebhookNotifier{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: logger,
+	}
+}
+
+// WebhookEvent is the JSON body of a delivery; only the fields of its type are set
+type WebhookEvent struct {
+	ID        string        `json:"id"`
+	Type      string        `json:"type"`
+	Time      time.Time     `json:"time"`
+	Host      *models.Host  `json:"host,omitempty"`
+	StartTime *time.Time    `json:"start_time,omitempty"` // cleanup.started and cleanup.failed
+	RequestID string        `json:"request_id,omitempty"` // cleanup.started, cleanup.failed and image.deleted
+	Error     string        `json:"error,omitempty"`      // cleanup.failed
+	Run       *WebhookRun   `json:"run,omitempty"`        // cleanup.completed
+	Disk      *WebhookDisk  `json:"disk,omitempty"`       // disk.pressure
+	Image     *WebhookImage `json:"image,omitempty"`      // image.deleted
+	Message   string        `json:"message,omitempty"`
+}
+
+// WebhookRun describes a cleanup run
+type WebhookRun struct {
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	DurationMs     int64     `json:"duration_ms"`
+	Total          int       `json:"total"`
+	Removed        int       `json:"removed"`
+	Skipped        int       `json:"skipped"`
+	Failed         int       `json:"failed"`
+	ReclaimedBytes uint64    `json:"reclaimed_bytes"`
+	RequestID      string    `json:"request_id,omitempty"`
+}
+
+// WebhookDisk describes the filesystem of a disk.pressure event
+type WebhookDisk struct {
+	Path             string  `json:"path"`
+	UsedBytes        uint64  `json:"used_bytes"`
+	TotalBytes       uint64  `json:"total_bytes"`
+	UsedPercent      float64 `json:"used_percent"`
+	ThresholdPercent float64 `json:"threshold_percent"`
+}
+
+// WebhookImage describes the image of an image.deleted event
+type WebhookImage struct {
+	ID        string   `json:"id"`
+	Tags      []string `json:"tags"`
+	SizeBytes uint64   `json:"size_bytes"`
+}
+
+// Notify delivers the event as its typed JSON event to every URL; ctx cancels the requests and the
+// retry backoff. The delivery ID set with notification.WithDeliveryID becomes the event id.
+func (n *WebhookNotifier) Notify(ctx context.Context, event notification.Event) error {
+	return n.notify(ctx, n.config.URLs, event)
+}
+
+// NotifyTarget delivers the event to the one configured URL target, e.g. to retry a failed URL
+func (n *WebhookNotifier) NotifyTarget(ctx context.Context, target string, event notification.Event) error {
+	if !slices.Contains(n.config.URLs, target) {
+		return fmt.Errorf("webhook %s is not configured", target)
+	}
+	return n.notify(ctx, []string{target}, event)
+}
+
+func (n *WebhookNotifier) notify(ctx context.Context, urls []string, event notification.Event) error {
+	webhookEvent, err := newWebhookEvent(event)
+	if err != nil {
+		return err
+	}
+	if id := notification.DeliveryIDFromContext(ctx); id != "" {
+		webhookEvent.ID = id
+	}
+	return n.deliver(ctx, urls, webhookEvent)
+}
+
+// SendNotification delivers a plain text message as a "message" event
+func (n *WebhookNotifier) SendNotification(message string) error {
+	return n.deliver(context.Background(), n.config.URLs, WebhookEvent{
+		ID:      uuid.NewString(),
+		Type:    WebhookEventMessage,
+		Time:    time.Now().UTC(),
+		Message: message,
+	})
+}
+
+// SendReport delivers the run as a "cleanup.completed" event
+func (n *WebhookNotifier) SendReport(report notification.Report) error {
+	return n.Notify(context.Background(), report)
+}
+
+// newWebhookEvent maps a domain event to the JSON body of its webhook event type
+func newWebhookEvent(event notification.Event) (WebhookEvent, error) {
+	host := event.Origin()
+	webhookEvent := WebhookEvent{
+		ID:   uuid.NewString(),
+		Time: time.Now().UTC(),
+		Host: &host,
+	}
+
+	switch e := event.(type) {
+	case notification.RunStarted:
+		start := e.StartTime.UTC()
+		webhookEvent.Type = WebhookEventCleanupStarted
+		webhookEvent.StartTime = &start
+		webhookEvent.RequestID = e.RequestID
+	case notification.Report:
+		webhookEvent.Type = WebhookEventCleanupCompleted
+		webhookEvent.Run = &WebhookRun{
+			StartTime:      e.StartTime.UTC(),
+			EndTime:        e.EndTime.UTC(),
+			DurationMs:     e.Duration.Milliseconds(),
+			Total:          e.Total,
+			Removed:        e.Removed,
+			Skipped:        e.Skipped,
+			Failed:         e.Failed,
+			ReclaimedBytes: e.ReclaimedBytes,
+			RequestID:      e.RequestID,
+		}
+	case notification.RunFailed:
+		start := e.StartTime.UTC()
+		webhookEvent.Type = WebhookEventCleanupFailed
+		webhookEvent.StartTime = &start
+		webhookEvent.RequestID = e.RequestID
+		webhookEvent.Error = e.Error
+	case notification.DiskPressure:
+		webhookEvent.Type = WebhookEventDiskPressure
+		webhookEvent.Disk = &WebhookDisk{
+			Path:             e.Path,
+			UsedBytes:        e.UsedBytes,
+			TotalBytes:       e.TotalBytes,
+			UsedPercent:      e.UsedPercent,
+			ThresholdPercent: e.ThresholdPercent,
+		}
+	case notification.ImageDeleted:
+		webhookEvent.Type = WebhookEventImageDeleted
+		webhookEvent.RequestID = e.RequestID
+		webhookEvent.Image = &WebhookImage{ID: e.ImageID, Tags: e.Tags, SizeBytes: e.SizeBytes}
+	default:
+		return WebhookEvent{}, fmt.Errorf("webhooks do not support %s events", event.Type())
+	}
+	return webhookEvent, nil
+}
+
+// deliver sends the event to the URLs; one failing URL does not stop the others and each failure
+// is a TargetError so only that URL is retried
+func (n *WebhookNotifier) deliver(ctx context.Context, urls []string, event WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	var errs []error
+	for _, url := range urls {
+		if err := n.deliverTo(ctx, url, event, body); err != nil {
+			errs = append(errs, &notification.TargetError{Target: url, Err: fmt.Errorf("webhook %s: %w", url, err)})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *WebhookNotifier) deliverTo(ctx context.Context, url string, event WebhookEvent, body []byte) error {
+	backoff := n.config.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= n.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+
+		var retry bool
+		retry, err = n.post(ctx, url, event, body)
+		if err == nil {
+			n.logger.Info("Successfully sent webhook notification",
+				zap.String("url", url),
+				zap.String("event", event.Type),
+				zap.String("delivery", event.ID),
+				zap.Int("attempt", attempt+1))
+			return nil
+		}
+		if !retry {
+			return err
+		}
+
+		n.logger.Warn("Webhook delivery failed, will retry",
+			zap.String("url", url),
+			zap.String("delivery", event.ID),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err))
+	}
+	return err
+}
+
+// post makes one attempt and reports whether a failure is worth retrying
+func (n *WebhookNotifier) post(ctx context.Context, url string, event WebhookEvent, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	// The timestamp is signed with the body so receivers can reject replayed deliveries
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constants.ServiceName)
+	req.Header.Set(WebhookHeaderEvent, event.Type)
+	req.Header.Set(WebhookHeaderDelivery, event.ID)
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+SignWebhook(n.config.Secret, timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// A cancelled ctx fails every further attempt, so it is not retried
+		return ctx.Err() == nil, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("webhook returned status %d %s", resp.StatusCode, strings.TrimSpace(string(reason)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
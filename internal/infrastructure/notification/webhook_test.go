@@ -0,0 +1,241 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// webhookReceiver verifies signatures like a real receiver and answers with the queued status codes
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	statuses []int
+	events   []WebhookEvent
+	attempts int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+
+	body, _ := io.ReadAll(req.Body)
+	signature := strings.TrimPrefix(req.Header.Get(WebhookHeaderSignature), "sha256=")
+	expected := SignWebhook(r.secret, req.Header.Get(WebhookHeaderTimestamp), body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		r.t.Errorf("invalid signature %q", signature)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		r.t.Errorf("invalid event: %v", err)
+	}
+	if req.Header.Get(WebhookHeaderEvent) != event.Type || req.Header.Get(WebhookHeaderDelivery) != event.ID {
+		r.t.Errorf("event headers do not match the body: %v", req.Header)
+	}
+	r.events = append(r.events, event)
+}
+
+func newTestWebhook(urls []string) *WebhookNotifier {
+	return NewWebhookNotifier(WebhookConfig{
+		URLs:         urls,
+		Secret:       "s3cret",
+		Timeout:      time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, zap.NewNop())
+}
+
+func TestWebhookNotifierSendReport(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "s3cret"}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := notification.Report{
+		Host:           models.Host{Hostname: "host-1", NodeName: "node-1"},
+		StartTime:      start,
+		EndTime:        start.Add(time.Minute),
+		Duration:       time.Minute,
+		Total:          4,
+		Removed:        2,
+		Skipped:        2,
+		Failed:         1,
+		ReclaimedBytes: 2048,
+		RequestID:      "req-1",
+		Message:        "ignored by webhooks",
+	}
+
+	if err := newTestWebhook([]string{server.URL}).SendReport(report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(receiver.events) != 1 {
+		t.Fatalf("expected one event, got %d", len(receiver.events))
+	}
+	event := receiver.events[0]
+	if event.Type != WebhookEventCleanupCompleted || event.ID == "" || event.Message != "" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.Host == nil || event.Host.NodeName != "node-1" {
+		t.Errorf("expected host identity, got %+v", event.Host)
+	}
+	run := event.Run
+	if run == nil || run.DurationMs != 60000 || run.Removed != 2 || run.Failed != 1 || run.ReclaimedBytes != 2048 || run.RequestID != "req-1" {
+		t.Errorf("unexpected run: %+v", run)
+	}
+	if !run.StartTime.Equal(start) {
+		t.Errorf("expected start time %v, got %v", start, run.StartTime)
+	}
+}
+
+func TestWebhookNotifierRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "recovers after server errors", statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}, wantAttempts: 3},
+		{name: "gives up after max retries", statuses: []int{500, 500, 500, 500}, wantErr: true, wantAttempts: 3},
+		{name: "client errors are not retried", statuses: []int{http.StatusBadRequest}, wantErr: true, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{t: t, secret: "s3cret", statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			err := newTestWebhook([]string{server.URL}).SendNotification("hello")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if receiver.attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, receiver.attempts)
+			}
+			if !tt.wantErr && (len(receiver.events) != 1 || receiver.events[0].Message != "hello") {
+				t.Errorf("expected the message event to be delivered, got %+v", receiver.events)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierIsolatesURLs(t *testing.T) {
+	good := &webhookReceiver{t: t, secret: "s3cret"}
+	goodServer := httptest.NewServer(good)
+	defer goodServer.Close()
+
+	badServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer badServer.Close()
+
+	err := newTestWebhook([]string{badServer.URL, goodServer.URL}).SendNotification("hello")
+	if err == nil || !strings.Contains(err.Error(), badServer.URL) {
+		t.Fatalf("expected an error naming the failing URL, got %v", err)
+	}
+	if len(good.events) != 1 {
+		t.Errorf("expected the healthy URL to receive the event, got %d", len(good.events))
+	}
+}
+
+func TestWebhookNotifierTypedEvents(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "s3cret"}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	host := models.Host{Hostname: "host-1", NodeName: "node-1"}
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []notification.Event{
+		notification.RunStarted{Host: host, StartTime: start, RequestID: "req-1"},
+		notification.RunFailed{Host: host, StartTime: start, Error: "crictl not found", RequestID: "req-1"},
+		notification.DiskPressure{Host: host, Path: "/var/lib/containerd", UsedBytes: 90, TotalBytes: 100, UsedPercent: 90, ThresholdPercent: 85},
+		notification.ImageDeleted{Host: host, ImageID: "sha256:abc", Tags: []string{"app:v1"}, SizeBytes: 1024, RequestID: "req-1"},
+	}
+
+	webhook := newTestWebhook([]string{server.URL})
+	for _, event := range events {
+		if err := webhook.Notify(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error for %s: %v", event.Type(), err)
+		}
+	}
+
+	if len(receiver.events) != len(events) {
+		t.Fatalf("expected %d events, got %d", len(events), len(receiver.events))
+	}
+	for i, want := range []string{WebhookEventCleanupStarted, WebhookEventCleanupFailed, WebhookEventDiskPressure, WebhookEventImageDeleted} {
+		event := receiver.events[i]
+		if event.Type != want || event.Message != "" || event.Host == nil || event.Host.NodeName != "node-1" {
+			t.Errorf("expected a %s event with the host, got %+v", want, event)
+		}
+	}
+
+	started, failed, pressure, deleted := receiver.events[0], receiver.events[1], receiver.events[2], receiver.events[3]
+	if started.StartTime == nil || !started.StartTime.Equal(start) || started.RequestID != "req-1" {
+		t.Errorf("unexpected cleanup.started event: %+v", started)
+	}
+	if failed.Error != "crictl not found" || failed.RequestID != "req-1" || failed.Run != nil {
+		t.Errorf("unexpected cleanup.failed event: %+v", failed)
+	}
+	if disk := pressure.Disk; disk == nil || disk.Path != "/var/lib/containerd" || disk.UsedPercent != 90 || disk.ThresholdPercent != 85 {
+		t.Errorf("unexpected disk.pressure event: %+v", pressure.Disk)
+	}
+	if image := deleted.Image; image == nil || image.ID != "sha256:abc" || image.SizeBytes != 1024 || len(image.Tags) != 1 || deleted.RequestID != "req-1" {
+		t.Errorf("unexpected image.deleted event: %+v", deleted.Image)
+	}
+}
+
+func TestWebhookNotifierStopsRetryingWhenCancelled(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "s3cret", statuses: []int{500, 500, 500}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(WebhookConfig{
+		URLs:         []string{server.URL},
+		Secret:       "s3cret",
+		Timeout:      time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Hour,
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := webhook.Notify(ctx, notification.RunFailed{Error: "boom"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to stop the backoff, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected Notify to return soon after the deadline, took %v", elapsed)
+	}
+	if receiver.attempts != 1 {
+		t.Errorf("expected a single attempt before cancellation, got %d", receiver.attempts)
+	}
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"bufio"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// LoadAPIKeyFile đọc API key tĩnh từ file, mỗi dòng có dạng
+//
+//	<name>:<scope,scope>:<sha256 hex của key>
+//
+// Dòng trống và dòng bắt đầu bằng # được bỏ qua.
+func LoadAPIKeyFile(path string) ([]models.APIKey, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open api key file: %w", err)
+	}
+	defer file.Close()
+
+	var keys []models.APIKey
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected name:scopes:sha256", path, lineNo)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			return nil, fmt.Errorf("%s:%d: name is required", path, lineNo)
+		}
+
+		scopes, err := models.ParseScopes(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+
+		hash := strings.ToLower(strings.TrimSpace(parts[2]))
+		if !sha256HexPattern.MatchString(hash) {
+			return nil, fmt.Errorf("%s:%d: key hash must be 64 hex characters (sha256)", path, lineNo)
+		}
+
+		keys = append(keys, models.APIKey{
+			ID:     models.FileAPIKeyIDPrefix + name,
+			Name:   name,
+			Hash:   hash,
+			Scopes: scopes,
+			Source: models.APIKeySourceFile,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read api key file: %w", err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// orphanRemovalGrace là thời gian giữ sự kiện xóa chưa có kết quả lần chạy tương ứng.
+// Sự kiện được lưu trước kết quả, nên chỉ những dòng cũ hơn khoảng này mới chắc chắn là mồ côi.
+const orphanRemovalGrace = time.Hour
+
+// saveRemovals lưu các sự kiện xóa image trong một transaction, dùng chung cho SQLite và PostgreSQL
+func saveRemovals(ctx context.Context, db *sql.DB, d dialect, removals []repositories.ImageRemoval) error {
+	if len(removals) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin removal transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, rebind(d, `
+		INSERT INTO image_removals
+		(id, run_id, request_id, image_id, tags, size_bytes, outcome, error, removed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`))
+	if err != nil {
+		return fmt.Errorf("failed to prepare removal insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, removal := range removals {
+		tags, err := json.Marshal(nonNilStrings(removal.Tags))
+		if err != nil {
+			return fmt.Errorf("failed to encode tags of image %s: %w", removal.ImageID, err)
+		}
+		if removal.Time.IsZero() {
+			removal.Time = time.Now()
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			uuid.New().String(),
+			removal.RunID,
+			removal.RequestID,
+			removal.ImageID,
+			string(tags),
+			int64(removal.SizeBytes),
+			removal.Outcome,
+			removal.Error,
+			timeValue(d, removal.Time),
+		); err != nil {
+			return fmt.Errorf("failed to save removal of image %s: %w", removal.ImageID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit removals: %w", err)
+	}
+	return nil
+}
+
+// streamRemovals duyệt sự kiện xóa của các lần chạy khớp query. Bộ lọc của ResultQuery áp dụng
+// lên cleanup_results, nên chỉ sự kiện của lần chạy đã lưu kết quả mới được trả về.
+func streamRemovals(ctx context.Context, db *sql.DB, d dialect, query repositories.ResultQuery, fn func(repositories.ImageRemoval) error) error {
+	where, args := resultQueryClause(d, query)
+
+	rows, err := db.QueryContext(ctx, rebind(d, `
+		SELECT r.run_id, r.request_id, c.hostname, c.node_name, r.image_id, r.tags,
+			r.size_bytes, r.outcome, r.error, r.removed_at
+		FROM image_removals r
+		JOIN cleanup_results c ON c.id = r.run_id
+		`+where+`
+		ORDER BY c.start_time ASC, r.removed_at ASC, r.id ASC
+	`), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query removals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var removal repositories.ImageRemoval
+		var tags string
+		var size int64
+		var removedAt any
+
+		if err := rows.Scan(&removal.RunID, &removal.RequestID, &removal.Hostname, &removal.NodeName, &removal.ImageID, &tags,
+			&size, &removal.Outcome, &removal.Error, &removedAt); err != nil {
+			return fmt.Errorf("failed to scan removal: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tags), &removal.Tags); err != nil {
+			return fmt.Errorf("failed to decode tags of image %s: %w", removal.ImageID, err)
+		}
+		removal.SizeBytes = uint64(size)
+		if removal.Time, err = parseTimeValue(removedAt); err != nil {
+			return fmt.Errorf("failed to parse removal time of image %s: %w", removal.ImageID, err)
+		}
+
+		if err := fn(removal); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+	return nil
+}
+
+// pruneOrphanRemovals xóa sự kiện xóa image mà lần chạy đã bị prune hoặc không bao giờ được lưu
+func pruneOrphanRemovals(ctx context.Context, db *sql.DB, d dialect) error {
+	_, err := db.ExecContext(ctx, rebind(d, `
+		DELETE FROM image_removals
+		WHERE removed_at < ?
+		  AND run_id NOT IN (SELECT id FROM cleanup_results)
+	`), timeValue(d, time.Now().Add(-orphanRemovalGrace)))
+	if err != nil {
+		return fmt.Errorf("failed to prune orphaned removals: %w", err)
+	}
+	return nil
+}
+
+// timeValue trả về giá trị lưu cột TIMESTAMP: chuỗi RFC3339 UTC với SQLite, time.Time UTC với PostgreSQL
+func timeValue(d dialect, t time.Time) any {
+	if d == dialectSQLite {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.UTC()
+}
+
+// parseTimeValue đọc cột TIMESTAMP được scan vào any theo cả hai dạng của timeValue
+func parseTimeValue(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339, v)
+	case []byte:
+		return time.Parse(time.RFC3339, string(v))
+	default:
+		return time.Time{}, fmt.Errorf("unexpected time value %T", value)
+	}
+}
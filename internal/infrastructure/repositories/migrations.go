@@ -0,0 +1,343 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// dialect xác định loại database mà migration đang chạy
+type dialect string
+
+const (
+	dialectSQLite   dialect = "sqlite"
+	dialectPostgres dialect = "postgres"
+)
+
+// postgresMigrationLockID là key cho pg_advisory_xact_lock, tránh nhiều node migrate cùng lúc
+const postgresMigrationLockID = 727465
+
+// migration mô tả một bước thay đổi schema. statements chạy cho mọi dialect,
+// sqlite/postgres chỉ chạy cho dialect tương ứng khi cú pháp khác nhau.
+type migration struct {
+	version    int
+	name       string
+	statements []string
+	sqlite     []string
+	postgres   []string
+}
+
+func (m migration) statementsFor(d dialect) []string {
+	stmts := append([]string{}, m.statements...)
+	switch d {
+	case dialectSQLite:
+		stmts = append(stmts, m.sqlite...)
+	case dialectPostgres:
+		stmts = append(stmts, m.postgres...)
+	}
+	return stmts
+}
+
+// migrations là danh sách migration dùng chung cho SQLite và PostgreSQL, theo thứ tự version
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_cleanup_results",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS cleanup_results (
+				id TEXT PRIMARY KEY,
+				host_info TEXT NOT NULL,
+				start_time TIMESTAMP NOT NULL,
+				end_time TIMESTAMP NOT NULL,
+				duration_ms INTEGER NOT NULL,
+				total_count INTEGER NOT NULL,
+				removed INTEGER NOT NULL,
+				skipped INTEGER NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_cleanup_results_start_time ON cleanup_results(start_time)`,
+			`CREATE INDEX IF NOT EXISTS idx_cleanup_results_created_at ON cleanup_results(created_at)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "add_host_identity_columns",
+		statements: []string{
+			`ALTER TABLE cleanup_results ADD COLUMN hostname TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cleanup_results ADD COLUMN node_name TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cleanup_results ADD COLUMN machine_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cleanup_results ADD COLUMN runtime_version TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cleanup_results ADD COLUMN ipv4 TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE cleanup_results ADD COLUMN ipv6 TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE cleanup_results ADD COLUMN labels TEXT NOT NULL DEFAULT '{}'`,
+			`CREATE INDEX IF NOT EXISTS idx_cleanup_results_hostname ON cleanup_results(hostname, start_time)`,
+			`CREATE INDEX IF NOT EXISTS idx_cleanup_results_node_name ON cleanup_results(node_name, start_time)`,
+		},
+		// Lấy hostname từ chuỗi "Host: <name>\nIP(s): ..." của các kết quả cũ
+		sqlite: []string{
+			`UPDATE cleanup_results
+			SET hostname = TRIM(SUBSTR(host_info, 7, INSTR(host_info || char(10), char(10)) - 7)),
+				node_name = TRIM(SUBSTR(host_info, 7, INSTR(host_info || char(10), char(10)) - 7))
+			WHERE host_info LIKE 'Host: %'`,
+		},
+		postgres: []string{
+			`UPDATE cleanup_results
+			SET hostname = TRIM(split_part(substring(host_info from 7), E'\n', 1)),
+				node_name = TRIM(split_part(substring(host_info from 7), E'\n', 1))
+			WHERE host_info LIKE 'Host: %'`,
+		},
+	},
+	{
+		version: 3,
+		name:    "create_api_keys",
+		// API key là dữ liệu riêng của từng node nên chỉ nằm trong SQLite local
+		sqlite: []string{
+			`CREATE TABLE IF NOT EXISTS api_keys (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				prefix TEXT NOT NULL,
+				key_hash TEXT NOT NULL UNIQUE,
+				scopes TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				last_used_at TIMESTAMP,
+				revoked_at TIMESTAMP
+			)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "create_schedules",
+		// Lịch chạy thuộc về từng node nên cũng chỉ nằm trong SQLite local
+		sqlite: []string{
+			`CREATE TABLE IF NOT EXISTS schedules (
+				name TEXT PRIMARY KEY,
+				expression TEXT NOT NULL,
+				paused INTEGER NOT NULL DEFAULT 0,
+				updated_at TIMESTAMP NOT NULL,
+				updated_by TEXT NOT NULL DEFAULT ''
+			)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "create_audit_log",
+		// Audit log ghi lại thao tác trên node này; trigger chặn UPDATE/DELETE để bảng chỉ ghi thêm
+		sqlite: []string{
+			`CREATE TABLE IF NOT EXISTS audit_log (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				time TIMESTAMP NOT NULL,
+				actor TEXT NOT NULL,
+				api_key_id TEXT NOT NULL DEFAULT '',
+				source_ip TEXT NOT NULL,
+				request_id TEXT NOT NULL DEFAULT '',
+				method TEXT NOT NULL,
+				route TEXT NOT NULL,
+				path TEXT NOT NULL,
+				params TEXT NOT NULL DEFAULT '{}',
+				status INTEGER NOT NULL,
+				outcome TEXT NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				duration_ms INTEGER NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_log_time ON audit_log(time)`,
+			`CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_log is append-only');
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_log is append-only');
+			END`,
+		},
+	},
+	{
+		version: 6,
+		name:    "add_request_id_column",
+		// Request ID của lần chạy do API kích hoạt, rỗng với lần chạy theo lịch
+		statements: []string{
+			`ALTER TABLE cleanup_results ADD COLUMN request_id TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 7,
+		name:    "create_notification_outbox",
+		// Thông báo chờ gửi lại thuộc về node đã tạo ra nó nên chỉ nằm trong SQLite local
+		sqlite: []string{
+			`CREATE TABLE IF NOT EXISTS notification_outbox (
+				id TEXT PRIMARY KEY,
+				channel TEXT NOT NULL,
+				event_type TEXT NOT NULL,
+				payload TEXT NOT NULL,
+				status TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt TIMESTAMP NOT NULL,
+				last_error TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON notification_outbox(status, next_attempt)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "add_outbox_target_and_delivery_id",
+		// Mỗi đích lỗi của kênh là một dòng riêng; delivery_id được dùng lại ở mọi lần gửi lại
+		sqlite: []string{
+			`ALTER TABLE notification_outbox ADD COLUMN target TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE notification_outbox ADD COLUMN delivery_id TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 9,
+		name:    "create_image_removals",
+		// Sự kiện xóa từng image nằm cùng store với cleanup_results để export được theo lần chạy
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS image_removals (
+				id TEXT PRIMARY KEY,
+				run_id TEXT NOT NULL,
+				request_id TEXT NOT NULL DEFAULT '',
+				image_id TEXT NOT NULL,
+				tags TEXT NOT NULL DEFAULT '[]',
+				size_bytes BIGINT NOT NULL,
+				outcome TEXT NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				removed_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_image_removals_run_id ON image_removals(run_id, removed_at)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "create_audit_archives",
+		// audit_log vẫn không cho sửa, nhưng bản ghi đã được archive ra file (ghi lại trong audit_archives)
+		// thì được xóa để bảng không lớn mãi; audit_archives cũng chỉ cho ghi thêm
+		sqlite: []string{
+			`CREATE TABLE IF NOT EXISTS audit_archives (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				archived_at TIMESTAMP NOT NULL,
+				before TIMESTAMP NOT NULL,
+				up_to_id INTEGER NOT NULL,
+				entries INTEGER NOT NULL,
+				path TEXT NOT NULL,
+				sha256 TEXT NOT NULL
+			)`,
+			`CREATE TRIGGER IF NOT EXISTS audit_archives_no_update BEFORE UPDATE ON audit_archives
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_archives is append-only');
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS audit_archives_no_delete BEFORE DELETE ON audit_archives
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_archives is append-only');
+			END`,
+			`DROP TRIGGER IF EXISTS audit_log_no_delete`,
+			`CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+			WHEN NOT EXISTS (SELECT 1 FROM audit_archives WHERE OLD.id <= up_to_id AND OLD.time < before)
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_log is append-only, archive entries before deleting them');
+			END`,
+		},
+	},
+}
+
+// LatestSchemaVersion trả về version schema mới nhất mà binary này hỗ trợ
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion đọc version schema hiện tại của database, 0 nếu chưa migrate
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate áp dụng các migration chưa chạy trong một transaction. Với PostgreSQL, advisory lock
+// được lấy trước cả khi tạo bảng schema_migrations để các node khởi động cùng lúc trên database
+// trống không tranh nhau tạo bảng.
+func migrate(ctx context.Context, db *sql.DB, d dialect, logger *zap.Logger) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if d == dialectPostgres {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, postgresMigrationLockID); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if current > LatestSchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, LatestSchemaVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		for _, stmt := range m.statementsFor(d) {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			rebind(d, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`),
+			m.version, m.name); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+
+		logger.Info("Applied database migration",
+			zap.String("dialect", string(d)),
+			zap.Int("version", m.version),
+			zap.String("name", m.name))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+
+	return nil
+}
+
+// rebind chuyển placeholder "?" sang "$n" cho PostgreSQL
+func rebind(d dialect, query string) string {
+	if d != dialectPostgres {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Đảm bảo PostgresCleanupResultRepository implement CleanupResultRepository và MaintenanceRepository
+var (
+	_ repositories.CleanupResultRepository = (*PostgresCleanupResultRepository)(nil)
+	_ repositories.MaintenanceRepository   = (*PostgresCleanupResultRepository)(nil)
+)
+
+type PostgresCleanupResultRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPostgresCleanupResultRepository tạo instance mới của repository trên database đã được migrate
+func NewPostgresCleanupResultRepository(db *sql.DB, logger *zap.Logger) *PostgresCleanupResultRepository {
+	return &PostgresCleanupResultRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveResult lưu kết quả cleanup vào database
+func (r *PostgresCleanupResultRepository) SaveResult(ctx context.Context, result repositories.CleanupResult) error {
+	if result.ID == "" {
+		result.ID = uuid.New().String()
+	}
+
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+
+	host, err := encodeHostColumns(result.Host)
+	if err != nil {
+		return fmt.Errorf("failed to save cleanup result: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO cleanup_results
+		(`+resultColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`,
+		result.ID,
+		result.HostInfo,
+		result.StartTime.UTC(), // Cột TIMESTAMP không lưu timezone, luôn ghi theo UTC
+		result.EndTime.UTC(),
+		result.Duration.Milliseconds(),
+		result.TotalCount,
+		result.Removed,
+		result.Skipped,
+		result.CreatedAt.UTC(),
+		result.Host.Hostname,
+		result.Host.NodeName,
+		result.Host.MachineID,
+		result.Host.RuntimeVersion,
+		host.ipv4,
+		host.ipv6,
+		host.labels,
+		result.RequestID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save cleanup result: %w", err)
+	}
+
+	r.logger.Info("Cleanup result saved to PostgreSQL",
+		zap.String("id", result.ID),
+		zap.String("request_id", result.RequestID))
+
+	return nil
+}
+
+// GetLatestResult lấy kết quả cleanup gần nhất của nodeName, hoặc của mọi node khi nodeName rỗng
+func (r *PostgresCleanupResultRepository) GetLatestResult(ctx context.Context, nodeName string) (*repositories.CleanupResult, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+resultColumns+`
+		FROM cleanup_results
+		WHERE $1 = '' OR node_name = $1
+		ORDER BY start_time DESC
+		LIMIT 1
+	`, nodeName)
+
+	return r.scanResult(row)
+}
+
+// GetResultByID lấy kết quả cleanup theo ID
+func (r *PostgresCleanupResultRepository) GetResultByID(ctx context.Context, id string) (*repositories.CleanupResult, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+resultColumns+`
+		FROM cleanup_results
+		WHERE id = $1
+	`, id)
+
+	return r.scanResult(row)
+}
+
+// GetResults lấy danh sách kết quả cleanup khớp query, có phân trang
+func (r *PostgresCleanupResultRepository) GetResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
+	where, args := resultQueryClause(dialectPostgres, query)
+
+	rows, err := r.db.QueryContext(ctx, rebind(dialectPostgres, `
+		SELECT `+resultColumns+`
+		FROM cleanup_results
+		`+where+`
+		ORDER BY start_time DESC
+		LIMIT ? OFFSET ?
+	`), append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []repositories.CleanupResult
+	for rows.Next() {
+		result, err := r.scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// StreamResults duyệt các kết quả trong khoảng thời gian của query, từng dòng một
+func (r *PostgresCleanupResultRepository) StreamResults(ctx context.Context, query repositories.ResultQuery, fn func(repositories.CleanupResult) error) error {
+	where, args := resultQueryClause(dialectPostgres, query)
+
+	rows, err := r.db.QueryContext(ctx, rebind(dialectPostgres, `
+		SELECT `+resultColumns+`
+		FROM cleanup_results
+		`+where+`
+		ORDER BY start_time ASC
+	`), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query results: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result, err := r.scanResult(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*result); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// SaveRemovals lưu các sự kiện xóa image của một lần chạy
+func (r *PostgresCleanupResultRepository) SaveRemovals(ctx context.Context, removals []repositories.ImageRemoval) error {
+	return saveRemovals(ctx, r.db, dialectPostgres, removals)
+}
+
+// StreamRemovals duyệt các sự kiện xóa image của những lần chạy khớp query, từng dòng một
+func (r *PostgresCleanupResultRepository) StreamRemovals(ctx context.Context, query repositories.ResultQuery, fn func(repositories.ImageRemoval) error) error {
+	return streamRemovals(ctx, r.db, dialectPostgres, query, fn)
+}
+
+// PruneResults xóa các kết quả cũ hơn MaxAge và giữ lại tối đa MaxRows kết quả mới nhất.
+// Bảng được nhiều node dùng chung, nên khi policy có NodeName chỉ lịch sử của node đó bị xóa.
+func (r *PostgresCleanupResultRepository) PruneResults(ctx context.Context, policy repositories.RetentionPolicy) (int64, error) {
+	var pruned int64
+
+	if policy.MaxAge > 0 {
+		cutoff := time.Now().Add(-policy.MaxAge).UTC()
+		res, err := r.db.ExecContext(ctx, `
+			DELETE FROM cleanup_results
+			WHERE start_time < $1
+			  AND ($2 = '' OR node_name = $2)
+		`, cutoff, policy.NodeName)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune results by age: %w", err)
+		}
+		affected, _ := res.RowsAffected()
+		pruned += affected
+	}
+
+	if policy.MaxRows > 0 {
+		res, err := r.db.ExecContext(ctx, `
+			DELETE FROM cleanup_results
+			WHERE id IN (
+				SELECT id FROM (
+					SELECT id, ROW_NUMBER() OVER (ORDER BY start_time DESC) AS position
+					FROM cleanup_results
+					WHERE $2 = '' OR node_name = $2
+				) ranked
+				WHERE position > $1
+			)
+		`, policy.MaxRows, policy.NodeName)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune results by row count: %w", err)
+		}
+		affected, _ := res.RowsAffected()
+		pruned += affected
+	}
+
+	// Sự kiện xóa image đi theo lần chạy của nó
+	if err := pruneOrphanRemovals(ctx, r.db, dialectPostgres); err != nil {
+		return pruned, err
+	}
+
+	return pruned, nil
+}
+
+// Optimize chạy VACUUM ANALYZE trên bảng kết quả; PostgreSQL không có WAL checkpoint ở mức client
+func (r *PostgresCleanupResultRepository) Optimize(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, "VACUUM ANALYZE cleanup_results"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// DatabaseSize trả về kích thước của database hiện tại
+func (r *PostgresCleanupResultRepository) DatabaseSize(ctx context.Context) (int64, error) {
+	var size int64
+	if err := r.db.QueryRowContext(ctx, "SELECT pg_database_size(current_database())").Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to read database size: %w", err)
+	}
+	return size, nil
+}
+
+// Ping kiểm tra kết nối và đọc thử bảng kết quả, phát hiện cả trường hợp database bị khóa
+func (r *PostgresCleanupResultRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM cleanup_results LIMIT 1").Scan(&exists)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read cleanup results: %w", err)
+	}
+	return nil
+}
+
+// scanResult đọc một kết quả; PostgreSQL trả về time.Time trực tiếp nên không cần parse chuỗi
+func (r *PostgresCleanupResultRepository) scanResult(row rowScanner) (*repositories.CleanupResult, error) {
+	var result repositories.CleanupResult
+	var durationMs int64
+	var hostCols hostColumns
+
+	err := row.Scan(
+		&result.ID,
+		&result.HostInfo,
+		&result.StartTime,
+		&result.EndTime,
+		&durationMs,
+		&result.TotalCount,
+		&result.Removed,
+		&result.Skipped,
+		&result.CreatedAt,
+		&result.Host.Hostname,
+		&result.Host.NodeName,
+		&result.Host.MachineID,
+		&result.Host.RuntimeVersion,
+		&hostCols.ipv4,
+		&hostCols.ipv6,
+		&hostCols.labels,
+		&result.RequestID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, repositories.ErrNoResults
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan result: %w", err)
+	}
+
+	if err := hostCols.decodeInto(&result.Host); err != nil {
+		r.logger.Warn("Failed to decode host columns", zap.Error(err), zap.String("id", result.ID))
+	}
+
+	result.Duration = time.Duration(durationMs) * time.Millisecond
+	return &result, nil
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+// resultColumns là danh sách cột của cleanup_results theo thứ tự scan
+const resultColumns = `id, host_info, start_time, end_time, duration_ms, total_count, removed, skipped, created_at,
+	hostname, node_name, machine_id, runtime_version, ipv4, ipv6, labels, request_id`
+
+// hostColumns là giá trị JSON của các cột host dạng danh sách/map
+type hostColumns struct {
+	ipv4   string
+	ipv6   string
+	labels string
+}
+
+func encodeHostColumns(h models.Host) (hostColumns, error) {
+	ipv4, err := json.Marshal(nonNilStrings(h.IPv4))
+	if err != nil {
+		return hostColumns{}, fmt.Errorf("failed to encode ipv4: %w", err)
+	}
+	ipv6, err := json.Marshal(nonNilStrings(h.IPv6))
+	if err != nil {
+		return hostColumns{}, fmt.Errorf("failed to encode ipv6: %w", err)
+	}
+	labels := h.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return hostColumns{}, fmt.Errorf("failed to encode labels: %w", err)
+	}
+	return hostColumns{ipv4: string(ipv4), ipv6: string(ipv6), labels: string(labelsJSON)}, nil
+}
+
+func (c hostColumns) decodeInto(h *models.Host) error {
+	if err := json.Unmarshal([]byte(c.ipv4), &h.IPv4); err != nil {
+		return fmt.Errorf("failed to decode ipv4: %w", err)
+	}
+	if err := json.Unmarshal([]byte(c.ipv6), &h.IPv6); err != nil {
+		return fmt.Errorf("failed to decode ipv6: %w", err)
+	}
+	if err := json.Unmarshal([]byte(c.labels), &h.Labels); err != nil {
+		return fmt.Errorf("failed to decode labels: %w", err)
+	}
+	return nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// resultQueryClause tạo mệnh đề WHERE với placeholder "?" (gọi rebind cho PostgreSQL).
+// SQLite lưu thời gian dạng chuỗi RFC3339 UTC nên so sánh chuỗi; PostgreSQL dùng TIMESTAMP.
+func resultQueryClause(d dialect, query repositories.ResultQuery) (string, []any) {
+	var conditions []string
+	var args []any
+
+	timeArg := func(t time.Time) any {
+		if d == dialectSQLite {
+			return t.UTC().Format(time.RFC3339)
+		}
+		return t.UTC()
+	}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "start_time >= ?")
+		args = append(args, timeArg(query.From))
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "start_time < ?")
+		args = append(args, timeArg(query.To))
+	}
+	if query.Hostname != "" {
+		conditions = append(conditions, "hostname = ?")
+		args = append(args, query.Hostname)
+	}
+	if query.NodeName != "" {
+		conditions = append(conditions, "node_name = ?")
+		args = append(args, query.NodeName)
+	}
+	if query.MachineID != "" {
+		conditions = append(conditions, "machine_id = ?")
+		args = append(args, query.MachineID)
+	}
+
+	// Sắp xếp key để câu query ổn định
+	keys := make([]string, 0, len(query.Labels))
+	for key := range query.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if d == dialectSQLite {
+			conditions = append(conditions, "json_extract(labels, ?) = ?")
+			args = append(args, fmt.Sprintf(`$."%s"`, strings.ReplaceAll(key, `"`, `\"`)), query.Labels[key])
+		} else {
+			conditions = append(conditions, "(labels::jsonb ->> ?) = ?")
+			args = append(args, key, query.Labels[key])
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
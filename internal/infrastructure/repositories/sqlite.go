@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // Pure Go SQLite driver - không yêu cầu CGO
+)
+
+// OpenSQLiteDatabase mở database SQLite, thiết lập pragma và chạy migration
+func OpenSQLiteDatabase(dbPath string, logger *zap.Logger) (*sql.DB, error) {
+	// Đảm bảo thư mục tồn tại
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// Mở kết nối đến database với các tùy chọn cho modernc.org/sqlite. busy_timeout được đặt qua DSN
+	// để áp dụng cho mọi kết nối trong pool: ghi đồng thời (audit log, outbox, kết quả cleanup)
+	// chờ lock thay vì lỗi SQLITE_BUSY ngay lập tức.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Kiểm tra kết nối
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Thiết lập các tùy chọn SQLite quan trọng
+	_, err = db.Exec("PRAGMA journal_mode=WAL;") // Write-Ahead Logging mode
+	if err != nil {
+		logger.Warn("Failed to enable WAL mode", zap.Error(err))
+	}
+
+	_, err = db.Exec("PRAGMA foreign_keys=ON;") // Bật ràng buộc khóa ngoại
+	if err != nil {
+		logger.Warn("Failed to enable foreign keys", zap.Error(err))
+	}
+
+	// Khởi tạo schema
+	if err := migrate(context.Background(), db, dialectSQLite, logger); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return db, nil
+}
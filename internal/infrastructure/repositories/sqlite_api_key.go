@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Đảm bảo SQLiteAPIKeyRepository implement APIKeyRepository
+var _ repositories.APIKeyRepository = (*SQLiteAPIKeyRepository)(nil)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at`
+
+type SQLiteAPIKeyRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSQLiteAPIKeyRepository tạo repository API key trên database SQLite local đã được migrate
+func NewSQLiteAPIKeyRepository(db *sql.DB, logger *zap.Logger) *SQLiteAPIKeyRepository {
+	return &SQLiteAPIKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateKey lưu API key mới
+func (r *SQLiteAPIKeyRepository) CreateKey(ctx context.Context, key models.APIKey) error {
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		models.FormatScopes(key.Scopes),
+		key.CreatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	r.logger.Info("API key created",
+		zap.String("id", key.ID),
+		zap.String("name", key.Name),
+		zap.String("scopes", models.FormatScopes(key.Scopes)))
+
+	return nil
+}
+
+// FindKeyByHash tìm API key theo hash
+func (r *SQLiteAPIKeyRepository) FindKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE key_hash = ?
+	`, hash)
+
+	return r.scanKey(row)
+}
+
+// ListKeys trả về tất cả API key theo thứ tự tạo
+func (r *SQLiteAPIKeyRepository) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := r.scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeKey đánh dấu key đã bị thu hồi
+func (r *SQLiteAPIKeyRepository) RevokeKey(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys
+		SET revoked_at = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return repositories.ErrAPIKeyNotFound
+	}
+
+	r.logger.Info("API key revoked", zap.String("id", id))
+	return nil
+}
+
+// TouchKey cập nhật last_used_at
+func (r *SQLiteAPIKeyRepository) TouchKey(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys
+		SET last_used_at = ?
+		WHERE id = ?
+	`, usedAt.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+	return nil
+}
+
+// scanKey đọc một API key từ sql.Row hoặc sql.Rows
+func (r *SQLiteAPIKeyRepository) scanKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes, createdAtStr string
+	var lastUsedAt, revokedAt sql.NullString
+
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &createdAtStr, &lastUsedAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, repositories.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan api key: %w", err)
+	}
+
+	key.Source = models.APIKeySourceDatabase
+
+	key.Scopes, err = models.ParseScopes(scopes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scopes for api key %s: %w", key.ID, err)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+	if err != nil {
+		r.logger.Warn("Failed to parse created at time", zap.Error(err), zap.String("value", createdAtStr))
+	}
+	key.CreatedAt = createdAt
+
+	key.LastUsedAt = r.parseNullTime(lastUsedAt)
+	key.RevokedAt = r.parseNullTime(revokedAt)
+
+	return &key, nil
+}
+
+func (r *SQLiteAPIKeyRepository) parseNullTime(value sql.NullString) *time.Time {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value.String)
+	if err != nil {
+		r.logger.Warn("Failed to parse time", zap.Error(err), zap.String("value", value.String))
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSQLiteAPIKeyRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	db, err := OpenSQLiteDatabase(filepath.Join(t.TempDir(), "cleanup.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSQLiteAPIKeyRepository(db, logger)
+
+	key := models.APIKey{
+		ID:     "key-1",
+		Name:   "ci",
+		Prefix: "icu_abcdefgh",
+		Hash:   strings.Repeat("a", 64),
+		Scopes: []models.Scope{models.ScopeRead, models.ScopeTrigger},
+	}
+	if err := repo.CreateKey(ctx, key); err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+
+	found, err := repo.FindKeyByHash(ctx, key.Hash)
+	if err != nil {
+		t.Fatalf("failed to find key: %v", err)
+	}
+	if found.Name != "ci" || !found.HasScope(models.ScopeTrigger) || found.HasScope(models.ScopeAdmin) {
+		t.Errorf("unexpected key: %+v", found)
+	}
+	if found.Source != models.APIKeySourceDatabase || found.LastUsedAt != nil || found.Revoked() {
+		t.Errorf("unexpected key state: %+v", found)
+	}
+
+	if _, err := repo.FindKeyByHash(ctx, strings.Repeat("b", 64)); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err := repo.TouchKey(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("failed to touch key: %v", err)
+	}
+
+	if err := repo.RevokeKey(ctx, key.ID); err != nil {
+		t.Fatalf("failed to revoke key: %v", err)
+	}
+	if err := repo.RevokeKey(ctx, key.ID); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("expected ErrAPIKeyNotFound when revoking twice, got %v", err)
+	}
+
+	keys, err := repo.ListKeys(ctx)
+	if err != nil {
+		t.Fatalf("failed to list keys: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+	if !keys[0].Revoked() {
+		t.Error("expected key to be revoked")
+	}
+	if keys[0].LastUsedAt == nil || !keys[0].LastUsedAt.Equal(usedAt) {
+		t.Errorf("expected last used at %v, got %v", usedAt, keys[0].LastUsedAt)
+	}
+}
+
+func TestLoadAPIKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	hash := strings.Repeat("c", 64)
+
+	valid := filepath.Join(dir, "keys")
+	content := "# ops keys\n\nprometheus:read:" + hash + "\n"
+	if err := os.WriteFile(valid, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadAPIKeyFile(valid)
+	if err != nil {
+		t.Fatalf("failed to load key file: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "prometheus" || keys[0].Hash != hash || keys[0].Source != models.APIKeySourceFile {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+
+	for name, line := range map[string]string{
+		"missing field": "ops:read",
+		"bad scope":     "ops:write:" + hash,
+		"bad hash":      "ops:read:not-a-hash",
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-"))
+		if err := os.WriteFile(path, []byte(line+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadAPIKeyFile(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Đảm bảo SQLiteAuditRepository implement AuditRepository
+var _ repositories.AuditRepository = (*SQLiteAuditRepository)(nil)
+
+const auditColumns = `id, time, actor, api_key_id, source_ip, request_id, method, route, path, params, status, outcome, error, duration_ms`
+
+type SQLiteAuditRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSQLiteAuditRepository tạo repository audit log trên database SQLite local đã được migrate
+func NewSQLiteAuditRepository(db *sql.DB, logger *zap.Logger) *SQLiteAuditRepository {
+	return &SQLiteAuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AppendEntry ghi thêm một bản ghi audit
+func (r *SQLiteAuditRepository) AppendEntry(ctx context.Context, entry models.AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	params, err := json.Marshal(entry.Params)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit params: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO audit_log (time, actor, api_key_id, source_ip, request_id, method, route, path, params, status, outcome, error, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		entry.Time.UTC().Format(time.RFC3339),
+		entry.Actor,
+		entry.APIKeyID,
+		entry.SourceIP,
+		entry.RequestID,
+		entry.Method,
+		entry.Route,
+		entry.Path,
+		string(params),
+		entry.Status,
+		entry.Outcome,
+		entry.Error,
+		entry.DurationMs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries trả về các bản ghi audit theo bộ lọc, mới nhất trước
+func (r *SQLiteAuditRepository) ListEntries(ctx context.Context, query repositories.AuditQuery, limit, offset int) ([]models.AuditEntry, error) {
+	where, args := auditQueryClause(query)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+auditColumns+`
+		FROM audit_log`+where+`
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry, err := r.scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// StreamEntriesBefore duyệt các bản ghi cũ hơn before theo thứ tự ghi, từng dòng một
+func (r *SQLiteAuditRepository) StreamEntriesBefore(ctx context.Context, before time.Time, fn func(models.AuditEntry) error) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+auditColumns+`
+		FROM audit_log
+		WHERE time < ?
+		ORDER BY id ASC
+	`, before.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := r.scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteArchived lưu archive rồi xóa các bản ghi nó chứa; trigger audit_log_no_delete chỉ cho
+// xóa bản ghi đã có trong audit_archives nên hai bước phải nằm trong cùng transaction
+func (r *SQLiteAuditRepository) DeleteArchived(ctx context.Context, archive models.AuditArchive) (int64, error) {
+	if archive.ArchivedAt.IsZero() {
+		archive.ArchivedAt = time.Now()
+	}
+	before := archive.Before.UTC().Format(time.RFC3339)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin archive transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_archives (archived_at, before, up_to_id, entries, path, sha256)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		archive.ArchivedAt.UTC().Format(time.RFC3339),
+		before,
+		archive.UpToID,
+		archive.Entries,
+		archive.Path,
+		archive.SHA256,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record audit archive: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM audit_log WHERE id <= ? AND time < ?`, archive.UpToID, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived audit entries: %w", err)
+	}
+	deleted, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit audit archive: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// scanEntry đọc một bản ghi audit theo thứ tự auditColumns
+func (r *SQLiteAuditRepository) scanEntry(row rowScanner) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var timeStr, params string
+
+	err := row.Scan(&entry.ID, &timeStr, &entry.Actor, &entry.APIKeyID, &entry.SourceIP, &entry.RequestID,
+		&entry.Method, &entry.Route, &entry.Path, &params, &entry.Status, &entry.Outcome, &entry.Error, &entry.DurationMs)
+	if err != nil {
+		return entry, fmt.Errorf("failed to scan audit entry: %w", err)
+	}
+
+	entry.Time, err = time.Parse(time.RFC3339, timeStr)
+	if err != nil {
+		r.logger.Warn("Failed to parse audit time", zap.Error(err), zap.String("value", timeStr))
+	}
+	if err := json.Unmarshal([]byte(params), &entry.Params); err != nil {
+		r.logger.Warn("Failed to decode audit params", zap.Error(err), zap.Int64("id", entry.ID))
+	}
+
+	return entry, nil
+}
+
+// auditQueryClause tạo mệnh đề WHERE; thời gian lưu dạng chuỗi RFC3339 UTC nên so sánh chuỗi
+func auditQueryClause(query repositories.AuditQuery) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, query.From.UTC().Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "time < ?")
+		args = append(args, query.To.UTC().Format(time.RFC3339))
+	}
+	if query.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, query.Actor)
+	}
+	if query.Method != "" {
+		conditions = append(conditions, "method = ?")
+		args = append(args, strings.ToUpper(query.Method))
+	}
+	if query.Outcome != "" {
+		conditions = append(conditions, "outcome = ?")
+		args = append(args, query.Outcome)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "\n\t\tWHERE " + strings.Join(conditions, " AND "), args
+}
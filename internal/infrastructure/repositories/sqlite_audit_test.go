@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSQLiteAuditRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	db, err := OpenSQLiteDatabase(filepath.Join(t.TempDir(), "cleanup.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSQLiteAuditRepository(db, logger)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	entries := []models.AuditEntry{
+		{Time: now.Add(-2 * time.Hour), Actor: "api_key:ops", APIKeyID: "key-1", SourceIP: "10.0.0.1", RequestID: "req-1",
+			Method: "POST", Route: "/api/v1/cleanup", Path: "/api/v1/cleanup", Status: 202, Outcome: models.AuditOutcomeSuccess},
+		{Time: now.Add(-time.Hour), Actor: "ip:10.0.0.2", SourceIP: "10.0.0.2", Method: "POST",
+			Route: "/api/v1/schedules/:name/pause", Path: "/api/v1/schedules/cleanup/pause", Status: 401,
+			Outcome: models.AuditOutcomeFailure, Error: "Missing bearer token",
+			Params: models.AuditParams{Path: map[string]string{"name": "cleanup"}}},
+		{Time: now, Actor: "api_key:ops", APIKeyID: "key-1", SourceIP: "10.0.0.1", Method: "PUT",
+			Route: "/api/v1/schedules/:name", Path: "/api/v1/schedules/cleanup", Status: 200, Outcome: models.AuditOutcomeSuccess,
+			Params: models.AuditParams{Path: map[string]string{"name": "cleanup"}, Body: `{"expression":"0 2 * * *"}`}},
+	}
+	for _, entry := range entries {
+		if err := repo.AppendEntry(ctx, entry); err != nil {
+			t.Fatalf("failed to append entry: %v", err)
+		}
+	}
+
+	all, err := repo.ListEntries(ctx, repositories.AuditQuery{}, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list entries: %v", err)
+	}
+	if len(all) != 3 || all[0].Method != "PUT" || all[2].RequestID != "req-1" {
+		t.Fatalf("expected 3 entries newest first, got %+v", all)
+	}
+	if all[0].Params.Path["name"] != "cleanup" || all[0].Params.Body != `{"expression":"0 2 * * *"}` || !all[0].Time.Equal(now) {
+		t.Errorf("unexpected entry: %+v", all[0])
+	}
+
+	tests := []struct {
+		name     string
+		query    repositories.AuditQuery
+		expected int
+	}{
+		{"by actor", repositories.AuditQuery{Actor: "api_key:ops"}, 2},
+		{"by outcome", repositories.AuditQuery{Outcome: models.AuditOutcomeFailure}, 1},
+		{"by method", repositories.AuditQuery{Method: "put"}, 1},
+		{"by time range", repositories.AuditQuery{From: now.Add(-90 * time.Minute), To: now}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.ListEntries(ctx, tt.query, 10, 0)
+			if err != nil {
+				t.Fatalf("failed to list entries: %v", err)
+			}
+			if len(got) != tt.expected {
+				t.Errorf("expected %d entries, got %d", tt.expected, len(got))
+			}
+		})
+	}
+
+	// Bảng chỉ cho ghi thêm
+	if _, err := db.ExecContext(ctx, `UPDATE audit_log SET actor = 'someone-else'`); err == nil {
+		t.Error("expected UPDATE on audit_log to fail")
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM audit_log`); err == nil {
+		t.Error("expected DELETE on audit_log to fail")
+	}
+	if got, _ := repo.ListEntries(ctx, repositories.AuditQuery{}, 10, 0); len(got) != 3 {
+		t.Errorf("expected audit entries to be unchanged, got %d", len(got))
+	}
+
+	// Archive hai bản ghi cũ nhất rồi xóa chúng, bản ghi mới hơn phải được giữ lại
+	before := now.Add(-30 * time.Minute)
+	var archived []models.AuditEntry
+	err = repo.StreamEntriesBefore(ctx, before, func(entry models.AuditEntry) error {
+		archived = append(archived, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to stream entries: %v", err)
+	}
+	if len(archived) != 2 || archived[0].RequestID != "req-1" || archived[1].Status != 401 {
+		t.Fatalf("expected the 2 oldest entries in id order, got %+v", archived)
+	}
+
+	deleted, err := repo.DeleteArchived(ctx, models.AuditArchive{
+		Before:  before,
+		UpToID:  archived[1].ID,
+		Entries: len(archived),
+		Path:    "/backups/audit.ndjson",
+		SHA256:  "abc",
+	})
+	if err != nil {
+		t.Fatalf("failed to delete archived entries: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted entries, got %d", deleted)
+	}
+	remaining, _ := repo.ListEntries(ctx, repositories.AuditQuery{}, 10, 0)
+	if len(remaining) != 1 || remaining[0].Method != "PUT" {
+		t.Errorf("expected only the newest entry to remain, got %+v", remaining)
+	}
+
+	// Bản ghi chưa archive vẫn không xóa được, và audit_archives cũng chỉ cho ghi thêm
+	if _, err := db.ExecContext(ctx, `DELETE FROM audit_log`); err == nil {
+		t.Error("expected DELETE of unarchived entries to fail")
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM audit_archives`); err == nil {
+		t.Error("expected DELETE on audit_archives to fail")
+	}
+}
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Đảm bảo SQLiteBackupRepository implement BackupRepository
+var _ repositories.BackupRepository = (*SQLiteBackupRepository)(nil)
+
+type SQLiteBackupRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSQLiteBackupRepository tạo repository sao lưu cho database SQLite đang mở
+func NewSQLiteBackupRepository(db *sql.DB, logger *zap.Logger) *SQLiteBackupRepository {
+	return &SQLiteBackupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Backup dùng VACUUM INTO để tạo bản sao nhất quán, an toàn khi đang bật WAL
+func (r *SQLiteBackupRepository) Backup(ctx context.Context, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup file already exists: %s", destPath)
+	}
+
+	if _, err := r.db.ExecContext(ctx, "VACUUM INTO ?", destPath); err != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	r.logger.Info("SQLite database backed up",
+		zap.String("path", destPath))
+
+	return nil
+}
+
+// ValidateSQLiteBackup kiểm tra tính toàn vẹn và version schema của file backup,
+// trả về version schema nếu file có thể restore bởi binary này
+func ValidateSQLiteBackup(ctx context.Context, backupPath string) (int, error) {
+	if _, err := os.Stat(backupPath); err != nil {
+		return 0, fmt.Errorf("backup file not accessible: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+backupPath+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check;").Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("backup integrity check failed: %s", integrity)
+	}
+
+	version, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("backup has no readable schema version: %w", err)
+	}
+	if version < 1 {
+		return 0, fmt.Errorf("backup has no applied migrations")
+	}
+	if version > LatestSchemaVersion() {
+		return version, fmt.Errorf("backup schema version %d is newer than supported version %d", version, LatestSchemaVersion())
+	}
+
+	return version, nil
+}
+
+// sqliteSidecarSuffixes là các file WAL và shared memory đi kèm file database
+var sqliteSidecarSuffixes = []string{"-wal", "-shm"}
+
+// renameFile được thay trong test để giả lập rename lỗi
+var renameFile = os.Rename
+
+// RestoreSQLiteDatabase thay database tại dbPath bằng file backup sau khi đã kiểm tra.
+// Service phải được dừng trước khi restore; người gọi giữ LockSQLiteDatabase trong lúc restore. Database cũ được giữ lại với hậu tố .pre-restore-<timestamp>,
+// cùng file -wal và -shm của nó để không mất các thay đổi chưa được checkpoint.
+// Nếu không thay được file mới vào, database cũ được đưa trở lại chỗ cũ.
+func RestoreSQLiteDatabase(ctx context.Context, backupPath, dbPath string, logger *zap.Logger) (string, error) {
+	version, err := ValidateSQLiteBackup(ctx, backupPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Copy vào file tạm cùng thư mục để rename là thao tác nguyên tử
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(backupPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to stage backup: %w", err)
+	}
+
+	// Chuyển database cũ sang chỗ khác cùng WAL và shared memory; WAL của database cũ
+	// không hợp lệ với file mới nhưng vẫn cần để mở lại database cũ đầy đủ
+	previousPath := fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().UTC().Format("20060102-150405"))
+	var moved []string
+	rollback := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			suffix := moved[i]
+			if err := renameFile(previousPath+suffix, dbPath+suffix); err != nil {
+				logger.Error("Failed to move the previous database back",
+					zap.String("path", previousPath+suffix),
+					zap.Error(err))
+			}
+		}
+		os.Remove(tmpPath)
+	}
+
+	for _, suffix := range append([]string{""}, sqliteSidecarSuffixes...) {
+		if _, err := os.Stat(dbPath + suffix); os.IsNotExist(err) {
+			continue
+		}
+		if err := renameFile(dbPath+suffix, previousPath+suffix); err != nil {
+			rollback()
+			return "", fmt.Errorf("failed to move current database file %s aside: %w", dbPath+suffix, err)
+		}
+		moved = append(moved, suffix)
+	}
+
+	if err := renameFile(tmpPath, dbPath); err != nil {
+		rollback()
+		return "", fmt.Errorf("failed to swap in restored database: %w", err)
+	}
+
+	if len(moved) == 0 {
+		previousPath = ""
+	}
+
+	logger.Info("SQLite database restored",
+		zap.String("backup", backupPath),
+		zap.String("path", dbPath),
+		zap.String("previous", previousPath),
+		zap.Int("schema_version", version))
+
+	return previousPath, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
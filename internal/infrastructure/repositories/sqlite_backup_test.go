@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/repositories"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSQLiteBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "cleanup.db")
+	backupPath := filepath.Join(dir, "backups", "cleanup-backup.db")
+
+	db, err := OpenSQLiteDatabase(dbPath, logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	resultRepo := NewSQLiteCleanupResultRepository(db, logger)
+	if err := resultRepo.SaveResult(ctx, repositories.CleanupResult{ID: "before-backup", StartTime: time.Now(), EndTime: time.Now()}); err != nil {
+		t.Fatalf("failed to save result: %v", err)
+	}
+
+	if err := NewSQLiteBackupRepository(db, logger).Backup(ctx, backupPath); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if err := NewSQLiteBackupRepository(db, logger).Backup(ctx, backupPath); err == nil {
+		t.Error("expected error when backup file already exists")
+	}
+
+	if err := resultRepo.SaveResult(ctx, repositories.CleanupResult{ID: "after-backup", StartTime: time.Now(), EndTime: time.Now()}); err != nil {
+		t.Fatalf("failed to save result: %v", err)
+	}
+	db.Close()
+
+	version, err := ValidateSQLiteBackup(ctx, backupPath)
+	if err != nil {
+		t.Fatalf("backup validation failed: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("expected backup schema version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	previous, err := RestoreSQLiteDatabase(ctx, backupPath, dbPath, logger)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if _, err := os.Stat(previous); err != nil {
+		t.Errorf("expected previous database to be kept at %s: %v", previous, err)
+	}
+
+	db, err = OpenSQLiteDatabase(dbPath, logger)
+	if err != nil {
+		t.Fatalf("failed to open restored database: %v", err)
+	}
+	defer db.Close()
+
+	resultRepo = NewSQLiteCleanupResultRepository(db, logger)
+	if _, err := resultRepo.GetResultByID(ctx, "before-backup"); err != nil {
+		t.Errorf("expected result saved before backup to be restored: %v", err)
+	}
+	if _, err := resultRepo.GetResultByID(ctx, "after-backup"); err == nil {
+		t.Error("expected result saved after backup to be gone after restore")
+	}
+}
+
+func TestSQLiteRestoreKeepsUncheckpointedChanges(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "cleanup.db")
+	backupPath := filepath.Join(dir, "cleanup-backup.db")
+
+	db, err := OpenSQLiteDatabase(dbPath, logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := NewSQLiteBackupRepository(db, logger).Backup(ctx, backupPath); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if err := NewSQLiteCleanupResultRepository(db, logger).SaveResult(ctx, repositories.CleanupResult{ID: "in-wal", StartTime: time.Now(), EndTime: time.Now()}); err != nil {
+		t.Fatalf("failed to save result: %v", err)
+	}
+
+	// Chụp lại database cùng WAL khi process còn mở, như sau khi service bị kill
+	crashedPath := filepath.Join(dir, "crashed.db")
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := copyFile(dbPath+suffix, crashedPath+suffix); err != nil {
+			t.Fatalf("failed to copy %s: %v", suffix, err)
+		}
+	}
+	if info, err := os.Stat(crashedPath + "-wal"); err != nil || info.Size() == 0 {
+		t.Fatalf("expected the change to be in the WAL file: %v", err)
+	}
+
+	previous, err := RestoreSQLiteDatabase(ctx, backupPath, crashedPath, logger)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(crashedPath + suffix); !os.IsNotExist(err) {
+			t.Errorf("expected no %s file next to the restored database, got %v", suffix, err)
+		}
+	}
+
+	previousDB, err := OpenSQLiteDatabase(previous, logger)
+	if err != nil {
+		t.Fatalf("failed to open previous database: %v", err)
+	}
+	defer previousDB.Close()
+	if _, err := NewSQLiteCleanupResultRepository(previousDB, logger).GetResultByID(ctx, "in-wal"); err != nil {
+		t.Errorf("expected the uncheckpointed result to be kept with the previous database: %v", err)
+	}
+}
+
+func TestSQLiteRestoreRollsBackFailedSwap(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "cleanup.db")
+	backupPath := filepath.Join(dir, "cleanup-backup.db")
+
+	db, err := OpenSQLiteDatabase(backupPath, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	files := map[string]string{"": "current", "-wal": "wal", "-shm": "shm"}
+	for suffix, content := range files {
+		if err := os.WriteFile(dbPath+suffix, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	renameFile = func(oldPath, newPath string) error {
+		if oldPath == dbPath+".restore" {
+			return errors.New("disk quota exceeded")
+		}
+		return os.Rename(oldPath, newPath)
+	}
+	defer func() { renameFile = os.Rename }()
+
+	if _, err := RestoreSQLiteDatabase(ctx, backupPath, dbPath, logger); err == nil {
+		t.Fatal("expected the failed swap to be reported")
+	}
+
+	for suffix, content := range files {
+		data, err := os.ReadFile(dbPath + suffix)
+		if err != nil || string(data) != content {
+			t.Errorf("expected %q back at %s, got %q, %v", content, dbPath+suffix, data, err)
+		}
+	}
+	leftovers, _ := filepath.Glob(dbPath + ".*")
+	if len(leftovers) != 0 {
+		t.Errorf("expected no staged or moved files to be left, got %v", leftovers)
+	}
+}
+
+func TestValidateSQLiteBackupRejectsInvalidFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if _, err := ValidateSQLiteBackup(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected error for missing backup file")
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateSQLiteBackup(ctx, garbage); err == nil {
+		t.Error("expected error for non-SQLite file")
+	}
+
+	newer := filepath.Join(dir, "newer.db")
+	db, err := OpenSQLiteDatabase(newer, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, LatestSchemaVersion()+1, "from_the_future"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if _, err := ValidateSQLiteBackup(ctx, newer); err == nil {
+		t.Error("expected error for backup with newer schema version")
+	}
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDatabaseLocked được trả về khi một process khác (thường là service) đang giữ database SQLite
+var ErrDatabaseLocked = errors.New("database is in use by another process")
+
+// sqliteLockPath là file lock đi kèm database, nằm cạnh file -wal và -shm
+func sqliteLockPath(dbPath string) string {
+	return dbPath + ".lock"
+}
+
+// lockedError mô tả process đang giữ lock, pid rỗng khi không đọc được
+func lockedError(dbPath, pid string) error {
+	if pid == "" {
+		return fmt.Errorf("%w: %s", ErrDatabaseLocked, sqliteLockPath(dbPath))
+	}
+	return fmt.Errorf("%w: %s is held by pid %s", ErrDatabaseLocked, sqliteLockPath(dbPath), pid)
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// LockSQLiteDatabase lấy flock độc quyền trên <dbPath>.lock và ghi PID của process vào đó.
+// Service giữ lock suốt thời gian chạy để restore biết database đang được mở; lock tự nhả khi
+// process kết thúc. Trả về ErrDatabaseLocked nếu process khác đang giữ lock.
+func LockSQLiteDatabase(dbPath string) (func() error, error) {
+	path := sqliteLockPath(dbPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		defer file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			data, _ := io.ReadAll(io.LimitReader(file, 32))
+			return nil, lockedError(dbPath, strings.TrimSpace(string(data)))
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	// File lock không bị xóa khi nhả: xóa file trong lúc process khác chờ lock sẽ tách hai lock ra
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return func() error {
+		file.Truncate(0)
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to unlock %s: %w", path, err)
+		}
+		return file.Close()
+	}, nil
+}
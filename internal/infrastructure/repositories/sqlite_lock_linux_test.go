@@ -0,0 +1,40 @@
+//go:build linux
+
+package repositories
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLockSQLiteDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "data", "cleanup.db")
+
+	unlock, err := LockSQLiteDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("failed to lock database: %v", err)
+	}
+
+	// flock trên file descriptor khác xung đột cả trong cùng process, giống service và lệnh restore
+	_, err = LockSQLiteDatabase(dbPath)
+	if !errors.Is(err, ErrDatabaseLocked) {
+		t.Fatalf("expected ErrDatabaseLocked while the lock is held, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "pid "+strconv.Itoa(os.Getpid())) {
+		t.Errorf("expected the holder pid in the error, got %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("failed to unlock database: %v", err)
+	}
+
+	unlock, err = LockSQLiteDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("expected the lock to be free after unlock, got %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package repositories
+
+// LockSQLiteDatabase chỉ được hỗ trợ trên Linux, nơi service chạy; trên nền tảng khác không có lock
+func LockSQLiteDatabase(dbPath string) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Đảm bảo SQLiteOutboxRepository implement OutboxRepository
+var _ repositories.OutboxRepository = (*SQLiteOutboxRepository)(nil)
+
+const outboxColumns = `id, channel, target, delivery_id, event_type, payload, status, attempts, next_attempt, last_error, created_at, updated_at`
+
+type SQLiteOutboxRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSQLiteOutboxRepository tạo repository outbox thông báo trên database SQLite local đã được migrate
+func NewSQLiteOutboxRepository(db *sql.DB, logger *zap.Logger) *SQLiteOutboxRepository {
+	return &SQLiteOutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Enqueue thêm một thông báo mới, tạo ID và thời gian khi chưa có
+func (r *SQLiteOutboxRepository) Enqueue(ctx context.Context, message models.OutboxMessage) error {
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = now
+	}
+	if message.UpdatedAt.IsZero() {
+		message.UpdatedAt = now
+	}
+	if message.Status == "" {
+		message.Status = models.OutboxPending
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_outbox (`+outboxColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		message.ID,
+		message.Channel,
+		message.Target,
+		message.DeliveryID,
+		message.EventType,
+		string(message.Payload),
+		message.Status,
+		message.Attempts,
+		formatOutboxTime(message.NextAttempt),
+		message.LastError,
+		formatOutboxTime(message.CreatedAt),
+		formatOutboxTime(message.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s notification for %s: %w", message.EventType, message.Channel, err)
+	}
+
+	return nil
+}
+
+// Due trả về các thông báo pending đã đến lượt gửi, cũ nhất trước
+func (r *SQLiteOutboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	return r.query(ctx, `
+		SELECT `+outboxColumns+`
+		FROM notification_outbox
+		WHERE status = ? AND next_attempt <= ?
+		ORDER BY next_attempt ASC, created_at ASC
+		LIMIT ?
+	`, models.OutboxPending, formatOutboxTime(now), limit)
+}
+
+// Update lưu trạng thái và thông tin lần thử của thông báo
+func (r *SQLiteOutboxRepository) Update(ctx context.Context, message models.OutboxMessage) error {
+	if message.UpdatedAt.IsZero() {
+		message.UpdatedAt = time.Now()
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notification_outbox
+		SET status = ?, attempts = ?, next_attempt = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		message.Status,
+		message.Attempts,
+		formatOutboxTime(message.NextAttempt),
+		message.LastError,
+		formatOutboxTime(message.UpdatedAt),
+		message.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message %s: %w", message.ID, err)
+	}
+	return requireOutboxMessage(result, message.ID)
+}
+
+// Delete xóa một thông báo theo ID
+func (r *SQLiteOutboxRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notification_outbox WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox message %s: %w", id, err)
+	}
+	return requireOutboxMessage(result, id)
+}
+
+// Get trả về một thông báo theo ID
+func (r *SQLiteOutboxRepository) Get(ctx context.Context, id string) (*models.OutboxMessage, error) {
+	messages, err := r.query(ctx, `
+		SELECT `+outboxColumns+`
+		FROM notification_outbox
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, repositories.ErrOutboxMessageNotFound
+	}
+	return &messages[0], nil
+}
+
+// List trả về các thông báo theo trạng thái, mới nhất trước
+func (r *SQLiteOutboxRepository) List(ctx context.Context, status string, limit, offset int) ([]models.OutboxMessage, error) {
+	where := ""
+	args := []any{}
+	if status != "" {
+		where = "\n\t\tWHERE status = ?"
+		args = append(args, status)
+	}
+	args = append(args, limit, offset)
+
+	return r.query(ctx, `
+		SELECT `+outboxColumns+`
+		FROM notification_outbox`+where+`
+		ORDER BY created_at DESC, id ASC
+		LIMIT ? OFFSET ?
+	`, args...)
+}
+
+// Stats đếm số thông báo pending và failed
+func (r *SQLiteOutboxRepository) Stats(ctx context.Context) (models.OutboxStats, error) {
+	var stats models.OutboxStats
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0)
+		FROM notification_outbox
+	`, models.OutboxPending, models.OutboxFailed).Scan(&stats.Pending, &stats.Failed)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count outbox messages: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *SQLiteOutboxRepository) query(ctx context.Context, query string, args ...any) ([]models.OutboxMessage, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.OutboxMessage{}
+	for rows.Next() {
+		var message models.OutboxMessage
+		var payload, nextAttemptStr, createdAtStr, updatedAtStr string
+
+		err := rows.Scan(&message.ID, &message.Channel, &message.Target, &message.DeliveryID, &message.EventType, &payload, &message.Status,
+			&message.Attempts, &nextAttemptStr, &message.LastError, &createdAtStr, &updatedAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+
+		message.Payload = []byte(payload)
+		message.NextAttempt = r.parseTime(nextAttemptStr)
+		message.CreatedAt = r.parseTime(createdAtStr)
+		message.UpdatedAt = r.parseTime(updatedAtStr)
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *SQLiteOutboxRepository) parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		r.logger.Warn("Failed to parse outbox time", zap.Error(err), zap.String("value", value))
+	}
+	return t
+}
+
+// formatOutboxTime lưu thời gian dạng chuỗi RFC3339 UTC để so sánh chuỗi trong Due
+func formatOutboxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// requireOutboxMessage trả về ErrOutboxMessageNotFound khi câu lệnh không chạm tới dòng nào
+func requireOutboxMessage(result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", repositories.ErrOutboxMessageNotFound, id)
+	}
+	return nil
+}
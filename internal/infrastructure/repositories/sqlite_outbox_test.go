@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSQLiteOutboxRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	db, err := OpenSQLiteDatabase(filepath.Join(t.TempDir(), "cleanup.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSQLiteOutboxRepository(db, logger)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	messages := []models.OutboxMessage{
+		{ID: "due", Channel: "telegram", Target: "-1001:42", DeliveryID: "delivery-1", EventType: "run_completed", Payload: []byte(`{"removed":3}`), Attempts: 1,
+			NextAttempt: now.Add(-time.Minute), LastError: "connection refused", CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "later", Channel: "slack", EventType: "run_failed", Payload: []byte(`{}`), Attempts: 1,
+			NextAttempt: now.Add(time.Hour), CreatedAt: now.Add(-time.Minute)},
+		{ID: "dead", Channel: "webhook", EventType: "run_completed", Payload: []byte(`{}`), Attempts: 10,
+			Status: models.OutboxFailed, NextAttempt: now.Add(-time.Hour), CreatedAt: now},
+	}
+	for _, message := range messages {
+		if err := repo.Enqueue(ctx, message); err != nil {
+			t.Fatalf("failed to enqueue message: %v", err)
+		}
+	}
+
+	due, err := repo.Due(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("failed to read due messages: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != "due" {
+		t.Fatalf("expected only the pending message past its next attempt, got %+v", due)
+	}
+	if string(due[0].Payload) != `{"removed":3}` || due[0].Status != models.OutboxPending ||
+		due[0].LastError != "connection refused" || !due[0].NextAttempt.Equal(now.Add(-time.Minute)) ||
+		due[0].Target != "-1001:42" || due[0].DeliveryID != "delivery-1" {
+		t.Errorf("unexpected message: %+v", due[0])
+	}
+
+	stats, err := repo.Stats(ctx)
+	if err != nil {
+		t.Fatalf("failed to count messages: %v", err)
+	}
+	if stats != (models.OutboxStats{Pending: 2, Failed: 1}) {
+		t.Errorf("expected 2 pending and 1 failed, got %+v", stats)
+	}
+
+	message := due[0]
+	message.Status = models.OutboxFailed
+	message.Attempts = 2
+	message.LastError = "timeout"
+	if err := repo.Update(ctx, message); err != nil {
+		t.Fatalf("failed to update message: %v", err)
+	}
+	got, err := repo.Get(ctx, "due")
+	if err != nil {
+		t.Fatalf("failed to get message: %v", err)
+	}
+	if got.Status != models.OutboxFailed || got.Attempts != 2 || got.LastError != "timeout" {
+		t.Errorf("expected the update to be stored, got %+v", got)
+	}
+
+	failed, err := repo.List(ctx, models.OutboxFailed, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list messages: %v", err)
+	}
+	if len(failed) != 2 || failed[0].ID != "dead" || failed[1].ID != "due" {
+		t.Errorf("expected both failed messages newest first, got %+v", failed)
+	}
+	page, err := repo.List(ctx, "", 1, 1)
+	if err != nil {
+		t.Fatalf("failed to list messages: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != "later" {
+		t.Errorf("expected the second newest message, got %+v", page)
+	}
+
+	if err := repo.Delete(ctx, "due"); err != nil {
+		t.Fatalf("failed to delete message: %v", err)
+	}
+	if _, err := repo.Get(ctx, "due"); !errors.Is(err, repositories.ErrOutboxMessageNotFound) {
+		t.Errorf("expected ErrOutboxMessageNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(ctx, "due"); !errors.Is(err, repositories.ErrOutboxMessageNotFound) {
+		t.Errorf("expected ErrOutboxMessageNotFound deleting twice, got %v", err)
+	}
+	if err := repo.Update(ctx, models.OutboxMessage{ID: "missing"}); !errors.Is(err, repositories.ErrOutboxMessageNotFound) {
+		t.Errorf("expected ErrOutboxMessageNotFound updating an unknown message, got %v", err)
+	}
+}
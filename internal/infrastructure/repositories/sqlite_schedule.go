@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Đảm bảo SQLiteScheduleRepository implement ScheduleRepository
+var _ repositories.ScheduleRepository = (*SQLiteScheduleRepository)(nil)
+
+type SQLiteScheduleRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSQLiteScheduleRepository tạo repository lịch chạy trên database SQLite local đã được migrate
+func NewSQLiteScheduleRepository(db *sql.DB, logger *zap.Logger) *SQLiteScheduleRepository {
+	return &SQLiteScheduleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListSchedules trả về tất cả lịch đã lưu theo tên
+func (r *SQLiteScheduleRepository) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT name, expression, paused, updated_at, updated_by
+		FROM schedules
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []models.Schedule
+	for rows.Next() {
+		var schedule models.Schedule
+		var updatedAtStr string
+
+		if err := rows.Scan(&schedule.Name, &schedule.Expression, &schedule.Paused, &updatedAtStr, &schedule.UpdatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+
+		updatedAt, err := time.Parse(time.RFC3339, updatedAtStr)
+		if err != nil {
+			r.logger.Warn("Failed to parse updated at time", zap.Error(err), zap.String("value", updatedAtStr))
+		}
+		schedule.UpdatedAt = updatedAt
+
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// SaveSchedule tạo mới hoặc cập nhật lịch theo tên
+func (r *SQLiteScheduleRepository) SaveSchedule(ctx context.Context, schedule models.Schedule) error {
+	if schedule.UpdatedAt.IsZero() {
+		schedule.UpdatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO schedules (name, expression, paused, updated_at, updated_by)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			expression = excluded.expression,
+			paused = excluded.paused,
+			updated_at = excluded.updated_at,
+			updated_by = excluded.updated_by
+	`,
+		schedule.Name,
+		schedule.Expression,
+		schedule.Paused,
+		schedule.UpdatedAt.UTC().Format(time.RFC3339),
+		schedule.UpdatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save schedule %s: %w", schedule.Name, err)
+	}
+
+	return nil
+}
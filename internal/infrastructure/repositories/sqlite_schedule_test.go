@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSQLiteScheduleRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	db, err := OpenSQLiteDatabase(filepath.Join(t.TempDir(), "cleanup.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSQLiteScheduleRepository(db, logger)
+
+	schedules, err := repo.ListSchedules(ctx)
+	if err != nil {
+		t.Fatalf("failed to list schedules: %v", err)
+	}
+	if len(schedules) != 0 {
+		t.Fatalf("expected no schedules, got %d", len(schedules))
+	}
+
+	updatedAt := time.Now().UTC().Truncate(time.Second)
+	if err := repo.SaveSchedule(ctx, models.Schedule{
+		Name:       models.ScheduleCleanup,
+		Expression: "0 2 * * *",
+		Paused:     true,
+		UpdatedAt:  updatedAt,
+		UpdatedBy:  "api_key:ops",
+	}); err != nil {
+		t.Fatalf("failed to save schedule: %v", err)
+	}
+
+	// Lưu lần hai cùng tên phải cập nhật thay vì tạo bản ghi mới
+	if err := repo.SaveSchedule(ctx, models.Schedule{
+		Name:       models.ScheduleCleanup,
+		Expression: "0 3 * * *",
+		Paused:     false,
+		UpdatedAt:  updatedAt.Add(time.Minute),
+		UpdatedBy:  "ip:10.0.0.1",
+	}); err != nil {
+		t.Fatalf("failed to update schedule: %v", err)
+	}
+
+	schedules, err = repo.ListSchedules(ctx)
+	if err != nil {
+		t.Fatalf("failed to list schedules: %v", err)
+	}
+	if len(schedules) != 1 {
+		t.Fatalf("expected 1 schedule, got %d", len(schedules))
+	}
+
+	got := schedules[0]
+	if got.Expression != "0 3 * * *" || got.Paused || got.UpdatedBy != "ip:10.0.0.1" {
+		t.Errorf("unexpected schedule: %+v", got)
+	}
+	if !got.UpdatedAt.Equal(updatedAt.Add(time.Minute)) {
+		t.Errorf("expected updated at %v, got %v", updatedAt.Add(time.Minute), got.UpdatedAt)
+	}
+}
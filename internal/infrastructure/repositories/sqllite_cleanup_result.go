@@ -4,92 +4,31 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"go-image-cleanup/internal/domain/models"
 	"go-image-cleanup/internal/domain/repositories"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	_ "modernc.org/sqlite" // Pure Go SQLite driver - không yêu cầu CGO
 )
 
-// Đảm bảo SQLiteCleanupResultRepository implement CleanupResultRepository
-var _ repositories.CleanupResultRepository = (*SQLiteCleanupResultRepository)(nil)
+// Đảm bảo SQLiteCleanupResultRepository implement CleanupResultRepository và MaintenanceRepository
+var (
+	_ repositories.CleanupResultRepository = (*SQLiteCleanupResultRepository)(nil)
+	_ repositories.MaintenanceRepository   = (*SQLiteCleanupResultRepository)(nil)
+)
 
 type SQLiteCleanupResultRepository struct {
 	db     *sql.DB
 	logger *zap.Logger
 }
 
-// NewSQLiteCleanupResultRepository tạo instance mới của repository
-func NewSQLiteCleanupResultRepository(dbPath string, logger *zap.Logger) (*SQLiteCleanupResultRepository, error) {
-	// Đảm bảo thư mục tồn tại
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
-	}
-
-	// Mở kết nối đến database với các tùy chọn cho modernc.org/sqlite
-	db, err := sql.Open("sqlite", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Kiểm tra kết nối
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// Thiết lập các tùy chọn SQLite quan trọng
-	_, err = db.Exec("PRAGMA journal_mode=WAL;") // Write-Ahead Logging mode
-	if err != nil {
-		logger.Warn("Failed to enable WAL mode", zap.Error(err))
-	}
-
-	_, err = db.Exec("PRAGMA foreign_keys=ON;") // Bật ràng buộc khóa ngoại
-	if err != nil {
-		logger.Warn("Failed to enable foreign keys", zap.Error(err))
-	}
-
-	repo := &SQLiteCleanupResultRepository{
+// NewSQLiteCleanupResultRepository tạo instance mới của repository trên database đã được migrate
+func NewSQLiteCleanupResultRepository(db *sql.DB, logger *zap.Logger) *SQLiteCleanupResultRepository {
+	return &SQLiteCleanupResultRepository{
 		db:     db,
 		logger: logger,
 	}
-
-	// Khởi tạo schema
-	if err := repo.initSchema(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
-	}
-
-	return repo, nil
-}
-
-// Khởi tạo schema database
-func (r *SQLiteCleanupResultRepository) initSchema() error {
-	_, err := r.db.Exec(`
-		CREATE TABLE IF NOT EXISTS cleanup_results (
-			id TEXT PRIMARY KEY,
-			host_info TEXT NOT NULL,
-			start_time TIMESTAMP NOT NULL,
-			end_time TIMESTAMP NOT NULL,
-			duration_ms INTEGER NOT NULL,
-			total_count INTEGER NOT NULL,
-			removed INTEGER NOT NULL,
-			skipped INTEGER NOT NULL,
-			created_at TIMESTAMP NOT NULL
-		);
-		CREATE INDEX IF NOT EXISTS idx_cleanup_results_start_time ON cleanup_results(start_time);
-		CREATE INDEX IF NOT EXISTS idx_cleanup_results_created_at ON cleanup_results(created_at);
-	`)
-	return err
-}
-
-// Close đóng kết nối database
-func (r *SQLiteCleanupResultRepository) Close() error {
-	return r.db.Close()
 }
 
 // SaveResult lưu kết quả cleanup vào database
@@ -104,10 +43,15 @@ func (r *SQLiteCleanupResultRepository) SaveResult(ctx context.Context, result r
 		result.CreatedAt = time.Now()
 	}
 
-	_, err := r.db.ExecContext(ctx, `
+	host, err := encodeHostColumns(result.Host)
+	if err != nil {
+		return fmt.Errorf("failed to save cleanup result: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
 		INSERT INTO cleanup_results
-		(id, host_info, start_time, end_time, duration_ms, total_count, removed, skipped, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(`+resultColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		result.ID,
 		result.HostInfo,
@@ -118,6 +62,14 @@ func (r *SQLiteCleanupResultRepository) SaveResult(ctx context.Context, result r
 		result.Removed,
 		result.Skipped,
 		result.CreatedAt.UTC().Format(time.RFC3339),
+		result.Host.Hostname,
+		result.Host.NodeName,
+		result.Host.MachineID,
+		result.Host.RuntimeVersion,
+		host.ipv4,
+		host.ipv6,
+		host.labels,
+		result.RequestID,
 	)
 
 	if err != nil {
@@ -125,19 +77,21 @@ func (r *SQLiteCleanupResultRepository) SaveResult(ctx context.Context, result r
 	}
 
 	r.logger.Info("Cleanup result saved to SQLite",
-		zap.String("id", result.ID))
+		zap.String("id", result.ID),
+		zap.String("request_id", result.RequestID))
 
 	return nil
 }
 
-// GetLatestResult lấy kết quả cleanup gần nhất
-func (r *SQLiteCleanupResultRepository) GetLatestResult(ctx context.Context) (*repositories.CleanupResult, error) {
+// GetLatestResult lấy kết quả cleanup gần nhất của nodeName, hoặc của mọi node khi nodeName rỗng
+func (r *SQLiteCleanupResultRepository) GetLatestResult(ctx context.Context, nodeName string) (*repositories.CleanupResult, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, host_info, start_time, end_time, duration_ms, total_count, removed, skipped, created_at
+		SELECT `+resultColumns+`
 		FROM cleanup_results
+		WHERE ?1 = '' OR node_name = ?1
 		ORDER BY start_time DESC
 		LIMIT 1
-	`)
+	`, nodeName)
 
 	return r.scanResult(row)
 }
@@ -145,7 +99,7 @@ func (r *SQLiteCleanupResultRepository) GetLatestResult(ctx context.Context) (*r
 // GetResultByID lấy kết quả cleanup theo ID
 func (r *SQLiteCleanupResultRepository) GetResultByID(ctx context.Context, id string) (*repositories.CleanupResult, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, host_info, start_time, end_time, duration_ms, total_count, removed, skipped, created_at
+		SELECT `+resultColumns+`
 		FROM cleanup_results
 		WHERE id = ?
 	`, id)
@@ -153,14 +107,17 @@ func (r *SQLiteCleanupResultRepository) GetResultByID(ctx context.Context, id st
 	return r.scanResult(row)
 }
 
-// GetResults lấy danh sách kết quả cleanup có phân trang
-func (r *SQLiteCleanupResultRepository) GetResults(ctx context.Context, limit, offset int) ([]repositories.CleanupResult, error) {
+// GetResults lấy danh sách kết quả cleanup khớp query, có phân trang
+func (r *SQLiteCleanupResultRepository) GetResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
+	where, args := resultQueryClause(dialectSQLite, query)
+
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, host_info, start_time, end_time, duration_ms, total_count, removed, skipped, created_at
+		SELECT `+resultColumns+`
 		FROM cleanup_results
+		`+where+`
 		ORDER BY start_time DESC
 		LIMIT ? OFFSET ?
-	`, limit, offset)
+	`, append(args, limit, offset)...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query results: %w", err)
@@ -169,63 +126,69 @@ func (r *SQLiteCleanupResultRepository) GetResults(ctx context.Context, limit, o
 
 	var results []repositories.CleanupResult
 	for rows.Next() {
-		var id, hostInfo string
-		var startTimeStr, endTimeStr, createdAtStr string
-		var durationMs, totalCount, removed, skipped int64
-
-		err := rows.Scan(&id, &hostInfo, &startTimeStr, &endTimeStr, &durationMs, &totalCount, &removed, &skipped, &createdAtStr)
+		result, err := r.scanResult(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return nil, err
 		}
+		results = append(results, *result)
+	}
 
-		// Parse time strings
-		startTime, err := time.Parse(time.RFC3339, startTimeStr)
-		if err != nil {
-			r.logger.Warn("Failed to parse start time", zap.Error(err), zap.String("value", startTimeStr))
-			startTime = time.Time{}
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
 
-		endTime, err := time.Parse(time.RFC3339, endTimeStr)
-		if err != nil {
-			r.logger.Warn("Failed to parse end time", zap.Error(err), zap.String("value", endTimeStr))
-			endTime = time.Time{}
-		}
+	return results, nil
+}
 
-		createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+// StreamResults duyệt các kết quả trong khoảng thời gian của query, từng dòng một
+func (r *SQLiteCleanupResultRepository) StreamResults(ctx context.Context, query repositories.ResultQuery, fn func(repositories.CleanupResult) error) error {
+	where, args := resultQueryClause(dialectSQLite, query)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+resultColumns+`
+		FROM cleanup_results
+		`+where+`
+		ORDER BY start_time ASC
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query results: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result, err := r.scanResult(rows)
 		if err != nil {
-			r.logger.Warn("Failed to parse created at time", zap.Error(err), zap.String("value", createdAtStr))
-			createdAt = time.Time{}
+			return err
+		}
+		if err := fn(*result); err != nil {
+			return err
 		}
-
-		results = append(results, repositories.CleanupResult{
-			ID:         id,
-			HostInfo:   hostInfo,
-			StartTime:  startTime,
-			EndTime:    endTime,
-			Duration:   time.Duration(durationMs) * time.Millisecond,
-			TotalCount: int(totalCount),
-			Removed:    int(removed),
-			Skipped:    int(skipped),
-			CreatedAt:  createdAt,
-		})
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+		return fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return results, nil
+	return nil
 }
 
-// scanResult đọc một kết quả từ sql.Row
-func (r *SQLiteCleanupResultRepository) scanResult(row *sql.Row) (*repositories.CleanupResult, error) {
-	var id, hostInfo string
+// rowScanner là phần chung của *sql.Row và *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanResult đọc một kết quả từ sql.Row hoặc sql.Rows
+func (r *SQLiteCleanupResultRepository) scanResult(row rowScanner) (*repositories.CleanupResult, error) {
+	var id, hostInfo, requestID string
 	var startTimeStr, endTimeStr, createdAtStr string
 	var durationMs, totalCount, removed, skipped int64
+	var host models.Host
+	var hostCols hostColumns
 
-	err := row.Scan(&id, &hostInfo, &startTimeStr, &endTimeStr, &durationMs, &totalCount, &removed, &skipped, &createdAtStr)
+	err := row.Scan(&id, &hostInfo, &startTimeStr, &endTimeStr, &durationMs, &totalCount, &removed, &skipped, &createdAtStr,
+		&host.Hostname, &host.NodeName, &host.MachineID, &host.RuntimeVersion, &hostCols.ipv4, &hostCols.ipv6, &hostCols.labels, &requestID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("no cleanup results found")
+		return nil, repositories.ErrNoResults
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan result: %w", err)
@@ -250,9 +213,14 @@ func (r *SQLiteCleanupResultRepository) scanResult(row *sql.Row) (*repositories.
 		createdAt = time.Time{}
 	}
 
+	if err := hostCols.decodeInto(&host); err != nil {
+		r.logger.Warn("Failed to decode host columns", zap.Error(err), zap.String("id", id))
+	}
+
 	return &repositories.CleanupResult{
 		ID:         id,
 		HostInfo:   hostInfo,
+		Host:       host,
 		StartTime:  startTime,
 		EndTime:    endTime,
 		Duration:   time.Duration(durationMs) * time.Millisecond,
@@ -260,5 +228,102 @@ func (r *SQLiteCleanupResultRepository) scanResult(row *sql.Row) (*repositories.
 		Removed:    int(removed),
 		Skipped:    int(skipped),
 		CreatedAt:  createdAt,
+		RequestID:  requestID,
 	}, nil
 }
+
+// SaveRemovals lưu các sự kiện xóa image của một lần chạy
+func (r *SQLiteCleanupResultRepository) SaveRemovals(ctx context.Context, removals []repositories.ImageRemoval) error {
+	return saveRemovals(ctx, r.db, dialectSQLite, removals)
+}
+
+// StreamRemovals duyệt các sự kiện xóa image của những lần chạy khớp query, từng dòng một
+func (r *SQLiteCleanupResultRepository) StreamRemovals(ctx context.Context, query repositories.ResultQuery, fn func(repositories.ImageRemoval) error) error {
+	return streamRemovals(ctx, r.db, dialectSQLite, query, fn)
+}
+
+// PruneResults xóa các kết quả cũ hơn MaxAge và giữ lại tối đa MaxRows kết quả mới nhất;
+// policy có NodeName thì chỉ xóa lịch sử của node đó
+func (r *SQLiteCleanupResultRepository) PruneResults(ctx context.Context, policy repositories.RetentionPolicy) (int64, error) {
+	var pruned int64
+
+	if policy.MaxAge > 0 {
+		cutoff := time.Now().Add(-policy.MaxAge).UTC().Format(time.RFC3339)
+		res, err := r.db.ExecContext(ctx, `
+			DELETE FROM cleanup_results
+			WHERE start_time < ?1
+			  AND (?2 = '' OR node_name = ?2)
+		`, cutoff, policy.NodeName)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune results by age: %w", err)
+		}
+		affected, _ := res.RowsAffected()
+		pruned += affected
+	}
+
+	if policy.MaxRows > 0 {
+		res, err := r.db.ExecContext(ctx, `
+			DELETE FROM cleanup_results
+			WHERE (?2 = '' OR node_name = ?2)
+			  AND id NOT IN (
+				SELECT id FROM cleanup_results
+				WHERE ?2 = '' OR node_name = ?2
+				ORDER BY start_time DESC
+				LIMIT ?1
+			)
+		`, policy.MaxRows, policy.NodeName)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune results by row count: %w", err)
+		}
+		affected, _ := res.RowsAffected()
+		pruned += affected
+	}
+
+	// Sự kiện xóa image đi theo lần chạy của nó
+	if err := pruneOrphanRemovals(ctx, r.db, dialectSQLite); err != nil {
+		return pruned, err
+	}
+
+	return pruned, nil
+}
+
+// Optimize checkpoint WAL vào file chính rồi VACUUM để thu hồi dung lượng
+func (r *SQLiteCleanupResultRepository) Optimize(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE);"); err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, "VACUUM;"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+
+	return nil
+}
+
+// DatabaseSize trả về kích thước database dựa trên page_count * page_size
+func (r *SQLiteCleanupResultRepository) DatabaseSize(ctx context.Context) (int64, error) {
+	var pageCount, pageSize int64
+
+	if err := r.db.QueryRowContext(ctx, "PRAGMA page_count;").Scan(&pageCount); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := r.db.QueryRowContext(ctx, "PRAGMA page_size;").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+
+	return pageCount * pageSize, nil
+}
+
+// Ping kiểm tra kết nối và đọc thử bảng kết quả, phát hiện cả trường hợp database bị khóa
+func (r *SQLiteCleanupResultRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM cleanup_results LIMIT 1").Scan(&exists)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read cleanup results: %w", err)
+	}
+	return nil
+}
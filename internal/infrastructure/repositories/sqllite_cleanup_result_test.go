@@ -0,0 +1,310 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// cleanupResultStore là repository được kiểm tra bởi testCleanupResultRepository
+type cleanupResultStore interface {
+	repositories.CleanupResultRepository
+	repositories.MaintenanceRepository
+}
+
+func TestSQLiteCleanupResultRepository(t *testing.T) {
+	logger := zap.NewNop()
+
+	db, err := OpenSQLiteDatabase(filepath.Join(t.TempDir(), "cleanup.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	version, err := SchemaVersion(context.Background(), db)
+	if err != nil {
+		t.Fatalf("failed to read schema version: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("expected schema version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	testCleanupResultRepository(t, NewSQLiteCleanupResultRepository(db, logger))
+}
+
+func TestSQLiteMigrationBackfillsHostname(t *testing.T) {
+	logger := zap.NewNop()
+	path := filepath.Join(t.TempDir(), "cleanup.db")
+
+	// Tạo database ở schema version 1 như các bản cài đặt cũ
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := append([]string{}, migrations[0].statements...)
+	stmts = append(stmts,
+		`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)`,
+		`INSERT INTO schema_migrations (version, name) VALUES (1, 'create_cleanup_results')`,
+		`INSERT INTO cleanup_results (id, host_info, start_time, end_time, duration_ms, total_count, removed, skipped, created_at)
+		VALUES ('legacy', 'Host: worker-1' || char(10) || 'IP(s): 10.0.0.5', '2024-01-01T00:00:00Z', '2024-01-01T00:01:00Z', 60000, 1, 1, 0, '2024-01-01T00:01:00Z')`,
+	)
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to prepare legacy schema: %v", err)
+		}
+	}
+	db.Close()
+
+	db, err = OpenSQLiteDatabase(path, logger)
+	if err != nil {
+		t.Fatalf("failed to migrate legacy database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := NewSQLiteCleanupResultRepository(db, logger).GetResultByID(context.Background(), "legacy")
+	if err != nil {
+		t.Fatalf("failed to read legacy result: %v", err)
+	}
+	if result.Host.Hostname != "worker-1" || result.Host.NodeName != "worker-1" {
+		t.Errorf("expected hostname backfilled to worker-1, got %+v", result.Host)
+	}
+}
+
+func TestSQLiteMigrationsAreIdempotent(t *testing.T) {
+	logger := zap.NewNop()
+	path := filepath.Join(t.TempDir(), "cleanup.db")
+
+	for i := 0; i < 2; i++ {
+		db, err := OpenSQLiteDatabase(path, logger)
+		if err != nil {
+			t.Fatalf("open #%d failed: %v", i+1, err)
+		}
+		db.Close()
+	}
+}
+
+// testCleanupResultRepository kiểm tra hành vi chung mà mọi implementation phải đáp ứng
+func testCleanupResultRepository(t *testing.T, repo cleanupResultStore) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatalf("failed to ping repository: %v", err)
+	}
+
+	if _, err := repo.GetLatestResult(ctx, ""); !errors.Is(err, repositories.ErrNoResults) {
+		t.Fatalf("expected ErrNoResults for empty repository, got %v", err)
+	}
+
+	hostA := models.Host{Hostname: "host-a", NodeName: "node-a", MachineID: "m-a", IPv4: []string{"10.0.0.1"}, Labels: map[string]string{"env": "prod", "zone": "a"}}
+	hostB := models.Host{Hostname: "host-b", NodeName: "node-b", MachineID: "m-b", IPv6: []string{"fd00::1"}, Labels: map[string]string{"env": "staging"}}
+
+	results := []repositories.CleanupResult{
+		{ID: "old", HostInfo: "host-a", Host: hostA, StartTime: now.Add(-48 * time.Hour), EndTime: now.Add(-48*time.Hour + time.Minute), Duration: time.Minute, TotalCount: 3, Removed: 2, Skipped: 1},
+		{ID: "mid", HostInfo: "host-a", Host: hostA, StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-2*time.Hour + time.Minute), Duration: time.Minute, TotalCount: 2, Removed: 1, Skipped: 1},
+		{ID: "new", HostInfo: "host-b", Host: hostB, StartTime: now, EndTime: now.Add(time.Minute), Duration: time.Minute, TotalCount: 1, Removed: 1, Skipped: 0, RequestID: "req-new"},
+	}
+	for _, result := range results {
+		if err := repo.SaveResult(ctx, result); err != nil {
+			t.Fatalf("failed to save result %s: %v", result.ID, err)
+		}
+	}
+
+	latest, err := repo.GetLatestResult(ctx, "")
+	if err != nil {
+		t.Fatalf("failed to get latest result: %v", err)
+	}
+	if latest.ID != "new" || latest.HostInfo != "host-b" {
+		t.Errorf("expected latest result new/host-b, got %s/%s", latest.ID, latest.HostInfo)
+	}
+	if !latest.StartTime.Equal(now) {
+		t.Errorf("expected start time %v, got %v", now, latest.StartTime)
+	}
+	if latest.Duration != time.Minute {
+		t.Errorf("expected duration %v, got %v", time.Minute, latest.Duration)
+	}
+	if latest.RequestID != "req-new" {
+		t.Errorf("expected request ID req-new, got %q", latest.RequestID)
+	}
+	if latest.Host.NodeName != "node-b" || len(latest.Host.IPv6) != 1 || latest.Host.Labels["env"] != "staging" {
+		t.Errorf("unexpected host for latest result: %+v", latest.Host)
+	}
+
+	// Store dùng chung: lần chạy gần nhất của từng node
+	latestA, err := repo.GetLatestResult(ctx, "node-a")
+	if err != nil || latestA.ID != "mid" {
+		t.Errorf("expected the latest result of node-a to be mid, got %+v, %v", latestA, err)
+	}
+	if _, err := repo.GetLatestResult(ctx, "node-c"); !errors.Is(err, repositories.ErrNoResults) {
+		t.Errorf("expected ErrNoResults for a node without runs, got %v", err)
+	}
+
+	byID, err := repo.GetResultByID(ctx, "mid")
+	if err != nil {
+		t.Fatalf("failed to get result by id: %v", err)
+	}
+	if byID.Removed != 1 || byID.TotalCount != 2 {
+		t.Errorf("unexpected result for id mid: %+v", byID)
+	}
+
+	page, err := repo.GetResults(ctx, repositories.ResultQuery{}, 2, 1)
+	if err != nil {
+		t.Fatalf("failed to list results: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != "mid" || page[1].ID != "old" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+
+	filters := []struct {
+		name  string
+		query repositories.ResultQuery
+		want  []string
+	}{
+		{name: "hostname", query: repositories.ResultQuery{Hostname: "host-a"}, want: []string{"mid", "old"}},
+		{name: "node name", query: repositories.ResultQuery{NodeName: "node-b"}, want: []string{"new"}},
+		{name: "machine id", query: repositories.ResultQuery{MachineID: "m-a"}, want: []string{"mid", "old"}},
+		{name: "labels", query: repositories.ResultQuery{Labels: map[string]string{"env": "prod", "zone": "a"}}, want: []string{"mid", "old"}},
+		{name: "label mismatch", query: repositories.ResultQuery{Labels: map[string]string{"env": "prod", "zone": "b"}}, want: nil},
+		{name: "host and time", query: repositories.ResultQuery{Hostname: "host-a", From: now.Add(-3 * time.Hour)}, want: []string{"mid"}},
+	}
+	for _, f := range filters {
+		filtered, err := repo.GetResults(ctx, f.query, 10, 0)
+		if err != nil {
+			t.Fatalf("%s: failed to filter results: %v", f.name, err)
+		}
+		var ids []string
+		for _, result := range filtered {
+			ids = append(ids, result.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(f.want, ",") {
+			t.Errorf("%s: expected %v, got %v", f.name, f.want, ids)
+		}
+	}
+
+	var streamed []string
+	err = repo.StreamResults(ctx, repositories.ResultQuery{From: now.Add(-3 * time.Hour), To: now.Add(time.Second)}, func(result repositories.CleanupResult) error {
+		streamed = append(streamed, result.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to stream results: %v", err)
+	}
+	if len(streamed) != 2 || streamed[0] != "mid" || streamed[1] != "new" {
+		t.Errorf("expected streamed results [mid new], got %v", streamed)
+	}
+
+	removals := []repositories.ImageRemoval{
+		{RunID: "new", RequestID: "req-new", ImageID: "sha256:b", Tags: []string{"app:2"}, SizeBytes: 1 << 40, Outcome: repositories.RemovalOutcomeRemoved, Time: now.Add(30 * time.Second)},
+		{RunID: "mid", ImageID: "sha256:a", SizeBytes: 2048, Outcome: repositories.RemovalOutcomeFailed, Error: "image is locked", Time: now.Add(-2*time.Hour + time.Second)},
+		{RunID: "mid", ImageID: "sha256:c", Tags: []string{"app:1", "app:latest"}, SizeBytes: 1024, Outcome: repositories.RemovalOutcomeRemoved, Time: now.Add(-2*time.Hour + 2*time.Second)},
+		// Lần chạy chưa được lưu kết quả thì sự kiện chưa xuất hiện trong export
+		{RunID: "unsaved", ImageID: "sha256:d", Outcome: repositories.RemovalOutcomeRemoved, Time: now},
+	}
+	if err := repo.SaveRemovals(ctx, removals); err != nil {
+		t.Fatalf("failed to save removals: %v", err)
+	}
+
+	var streamedRemovals []repositories.ImageRemoval
+	err = repo.StreamRemovals(ctx, repositories.ResultQuery{}, func(removal repositories.ImageRemoval) error {
+		streamedRemovals = append(streamedRemovals, removal)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to stream removals: %v", err)
+	}
+	var removedImages []string
+	for _, removal := range streamedRemovals {
+		removedImages = append(removedImages, removal.ImageID)
+	}
+	if strings.Join(removedImages, ",") != "sha256:a,sha256:c,sha256:b" {
+		t.Fatalf("expected removals ordered by run then time, got %v", removedImages)
+	}
+	if first := streamedRemovals[0]; first.NodeName != "node-a" || first.Hostname != "host-a" || first.Outcome != repositories.RemovalOutcomeFailed ||
+		first.Error != "image is locked" || !first.Time.Equal(now.Add(-2*time.Hour+time.Second)) {
+		t.Errorf("unexpected failed removal: %+v", first)
+	}
+	if last := streamedRemovals[2]; last.SizeBytes != 1<<40 || last.RequestID != "req-new" || len(last.Tags) != 1 || last.Tags[0] != "app:2" {
+		t.Errorf("unexpected removal of run new: %+v", last)
+	}
+
+	var nodeRemovals int
+	err = repo.StreamRemovals(ctx, repositories.ResultQuery{NodeName: "node-b"}, func(removal repositories.ImageRemoval) error {
+		nodeRemovals++
+		return nil
+	})
+	if err != nil || nodeRemovals != 1 {
+		t.Errorf("expected 1 removal of node-b, got %d, %v", nodeRemovals, err)
+	}
+
+	// Policy theo node không đụng tới lịch sử của node khác dù đã quá hạn
+	for _, policy := range []repositories.RetentionPolicy{
+		{MaxAge: time.Hour, NodeName: "node-b"},
+		{MaxRows: 1, NodeName: "node-b"},
+	} {
+		pruned, err := repo.PruneResults(ctx, policy)
+		if err != nil {
+			t.Fatalf("failed to prune with %+v: %v", policy, err)
+		}
+		if pruned != 0 {
+			t.Errorf("expected nothing pruned for node-b with %+v, got %d", policy, pruned)
+		}
+	}
+
+	pruned, err := repo.PruneResults(ctx, repositories.RetentionPolicy{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("failed to prune by age: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 result pruned by age, got %d", pruned)
+	}
+
+	recent := repositories.CleanupResult{ID: "recent", HostInfo: "host-a", Host: hostA, StartTime: now.Add(-time.Hour), EndTime: now.Add(-time.Hour + time.Minute), Duration: time.Minute, TotalCount: 1, Removed: 1}
+	if err := repo.SaveResult(ctx, recent); err != nil {
+		t.Fatalf("failed to save result %s: %v", recent.ID, err)
+	}
+
+	// MaxRows theo node chỉ đếm kết quả của node-a: giữ recent, xóa mid, không tính new của node-b
+	pruned, err = repo.PruneResults(ctx, repositories.RetentionPolicy{MaxRows: 1, NodeName: "node-a"})
+	if err != nil {
+		t.Fatalf("failed to prune node-a by rows: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 result of node-a pruned by row count, got %d", pruned)
+	}
+	if _, err := repo.GetResultByID(ctx, "mid"); !errors.Is(err, repositories.ErrNoResults) {
+		t.Errorf("expected mid to be pruned, got %v", err)
+	}
+
+	pruned, err = repo.PruneResults(ctx, repositories.RetentionPolicy{MaxRows: 1})
+	if err != nil {
+		t.Fatalf("failed to prune by rows: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 result pruned by row count, got %d", pruned)
+	}
+
+	if _, err := repo.GetResultByID(ctx, "new"); err != nil {
+		t.Errorf("expected newest result to be kept: %v", err)
+	}
+
+	if err := repo.Optimize(ctx); err != nil {
+		t.Errorf("optimize failed: %v", err)
+	}
+
+	size, err := repo.DatabaseSize(ctx)
+	if err != nil {
+		t.Fatalf("failed to get database size: %v", err)
+	}
+	if size <= 0 {
+		t.Errorf("expected positive database size, got %d", size)
+	}
+}
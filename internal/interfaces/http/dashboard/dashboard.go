@@ -0,0 +1,41 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+// Path is where the dashboard is mounted
+const Path = "/dashboard"
+
+//go:embed static
+var static embed.FS
+
+// contentSecurityPolicy only allows the embedded script and stylesheet and same-origin API calls
+const contentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' data:; connect-src 'self'; base-uri 'none'; frame-ancestors 'none'; form-action 'none'"
+
+// Handler serves the embedded dashboard. The page holds no data itself; everything it shows
+// comes from /api/v1, so the API's authentication and client certificate checks still apply.
+func Handler() fiber.Handler {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		panic("dashboard assets are missing: " + err.Error())
+	}
+
+	files := filesystem.New(filesystem.Config{
+		Root:   http.FS(root),
+		Index:  "index.html",
+		MaxAge: int((5 * time.Minute).Seconds()),
+	})
+
+	return func(c *fiber.Ctx) error {
+		c.Set("Content-Security-Policy", contentSecurityPolicy)
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		return files(c)
+	}
+}
@@ -0,0 +1,228 @@
+// Image Cleanup dashboard. Everything is loaded from /api/v1; the API key, when auth is
+// enabled, is kept in sessionStorage only and sent as a bearer token.
+(function () {
+  "use strict";
+
+  var API = "/api/v1";
+  var KEY_STORAGE = "image-cleanup-api-key";
+  var SVG_NS = "http://www.w3.org/2000/svg";
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  function el(tag, text, className) {
+    var node = document.createElement(tag);
+    if (text !== undefined && text !== null) node.textContent = String(text);
+    if (className) node.className = className;
+    return node;
+  }
+
+  function svg(tag, attrs) {
+    var node = document.createElementNS(SVG_NS, tag);
+    Object.keys(attrs).forEach(function (name) {
+      node.setAttribute(name, attrs[name]);
+    });
+    return node;
+  }
+
+  function showMessage(text, isError) {
+    var box = $("message");
+    box.textContent = text;
+    box.className = isError ? "error" : "";
+    box.hidden = !text;
+  }
+
+  function formatTime(value) {
+    if (!value || value.indexOf("0001-01-01") === 0) return "never";
+    return new Date(value).toLocaleString();
+  }
+
+  function formatBytes(bytes) {
+    if (!bytes) return "-";
+    var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+    var i = 0;
+    while (bytes >= 1024 && i < units.length - 1) {
+      bytes /= 1024;
+      i++;
+    }
+    return bytes.toFixed(i === 0 ? 0 : 1) + " " + units[i];
+  }
+
+  function request(method, path) {
+    var headers = { Accept: "application/json" };
+    var key = sessionStorage.getItem(KEY_STORAGE);
+    if (key) headers.Authorization = "Bearer " + key;
+
+    return fetch(API + path, { method: method, headers: headers, credentials: "same-origin" }).then(function (resp) {
+      return resp.json().catch(function () {
+        return {};
+      }).then(function (body) {
+        if (!resp.ok) {
+          var reason = body.message || resp.statusText;
+          if (resp.status === 401) reason += " (enter an API key)";
+          if (resp.status === 403) reason += " (the API key lacks the required scope)";
+          throw new Error(method + " " + path + ": " + reason);
+        }
+        return body;
+      });
+    });
+  }
+
+  function replaceRows(tbody, rows, columns) {
+    tbody.replaceChildren();
+    if (rows.length === 0) {
+      var empty = el("td", "Nothing to show", "muted");
+      empty.colSpan = columns;
+      tbody.appendChild(el("tr")).appendChild(empty);
+      return;
+    }
+    rows.forEach(function (cells) {
+      var tr = el("tr");
+      cells.forEach(function (cell) {
+        tr.appendChild(cell instanceof Node ? cell : el("td", cell));
+      });
+      tbody.appendChild(tr);
+    });
+  }
+
+  function renderLatest(data) {
+    $("node").textContent = data.host && (data.host.node_name || data.host.hostname) || "";
+    var stats = [
+      ["Started", formatTime(data.start_time)],
+      ["Duration", data.duration],
+      ["Images found", data.total_count],
+      ["Removed", data.removed_count],
+      ["Skipped", data.skipped_count],
+      ["Host", data.host_info]
+    ];
+    var list = $("latest");
+    list.replaceChildren();
+    stats.forEach(function (pair) {
+      list.appendChild(el("dt", pair[0]));
+      list.appendChild(el("dd", pair[1]));
+    });
+  }
+
+  function renderSchedules(data) {
+    replaceRows($("schedules"), (data.schedules || []).map(function (s) {
+      return [
+        s.name,
+        s.expression + (s.expression !== s.default_expression ? " (configured: " + s.default_expression + ")" : ""),
+        s.paused ? "paused" : "active",
+        s.next_run ? formatTime(s.next_run) : "-",
+        s.prev_run ? formatTime(s.prev_run) : "-"
+      ];
+    }), 5);
+  }
+
+  function renderChart(data) {
+    var runs = (data.results || []).slice().reverse();
+    var chart = $("chart");
+    chart.replaceChildren();
+    if (runs.length === 0) {
+      chart.appendChild(el("p", "No runs recorded yet", "muted"));
+      return;
+    }
+
+    var width = 600, height = 180, bottom = 20;
+    var max = Math.max.apply(null, runs.map(function (r) { return r.removed + r.skipped; }).concat([1]));
+    var slot = width / runs.length;
+    var barWidth = Math.max(slot * 0.7, 1);
+    var root = svg("svg", { viewBox: "0 0 " + width + " " + height, preserveAspectRatio: "none", role: "img" });
+
+    runs.forEach(function (run, i) {
+      var x = i * slot + (slot - barWidth) / 2;
+      var scale = (height - bottom) / max;
+      var removedHeight = run.removed * scale;
+      var skippedHeight = run.skipped * scale;
+
+      var removed = svg("rect", { x: x, y: height - bottom - removedHeight, width: barWidth, height: removedHeight, "class": "removed" });
+      var skipped = svg("rect", { x: x, y: height - bottom - removedHeight - skippedHeight, width: barWidth, height: skippedHeight, "class": "skipped" });
+      var title = svg("title", {});
+      title.textContent = formatTime(run.start_time) + ": " + run.removed + " removed, " + run.skipped + " skipped";
+      removed.appendChild(title);
+      skipped.appendChild(title.cloneNode(true));
+      root.appendChild(removed);
+      root.appendChild(skipped);
+    });
+
+    var label = svg("text", { x: 0, y: height - 4 });
+    label.textContent = "max " + max + " images per run";
+    root.appendChild(label);
+    chart.appendChild(root);
+  }
+
+  function renderInventory(data, dryRun) {
+    var inventory = data.inventory;
+    $("inventory-summary").textContent = (dryRun ? "Dry run: " : "") +
+      inventory.remove_count + " of " + inventory.total_count + " images would be removed, freeing " +
+      formatBytes(inventory.reclaimable_bytes) + " (" + formatTime(inventory.generated_at) + ")";
+
+    replaceRows($("inventory"), inventory.images.map(function (img) {
+      return [
+        img.tags.length ? img.tags.join(", ") : "<none>",
+        el("td", img.id.replace(/^sha256:/, "").slice(0, 12), "id"),
+        formatBytes(img.size_bytes),
+        img.in_use ? "yes" : "no",
+        el("td", img.action + " (" + img.reason.replace("_", " ") + ")", "decision-" + img.action)
+      ];
+    }), 5);
+  }
+
+  function load(path, render) {
+    return request("GET", path).then(render).catch(function (err) {
+      showMessage(err.message, true);
+    });
+  }
+
+  function refresh() {
+    showMessage("");
+    load("/cleanup", renderLatest);
+    load("/schedules", renderSchedules);
+    load("/results?limit=30", renderChart);
+    load("/images", function (data) { renderInventory(data, false); });
+  }
+
+  function withButton(button, action) {
+    button.disabled = true;
+    return action().catch(function (err) {
+      showMessage(err.message, true);
+    }).then(function () {
+      button.disabled = false;
+    });
+  }
+
+  $("auth").addEventListener("submit", function (event) {
+    event.preventDefault();
+    var key = $("api-key").value.trim();
+    if (key) {
+      sessionStorage.setItem(KEY_STORAGE, key);
+    } else {
+      sessionStorage.removeItem(KEY_STORAGE);
+    }
+    $("api-key").value = "";
+    refresh();
+  });
+
+  $("refresh").addEventListener("click", refresh);
+
+  $("dry-run").addEventListener("click", function () {
+    withButton(this, function () {
+      return request("POST", "/cleanup?dry_run=true").then(function (data) {
+        renderInventory(data, true);
+      });
+    });
+  });
+
+  $("cleanup").addEventListener("click", function () {
+    if (!window.confirm("Remove every image marked \"remove\" on this node now?")) return;
+    withButton(this, function () {
+      return request("POST", "/cleanup").then(function (data) {
+        showMessage(data.message + ". Refresh in a moment to see the result.", false);
+      });
+    });
+  });
+
+  refresh();
+})();
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"go-image-cleanup/internal/usecases/backup"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type AdminHandler struct {
+	backupUseCase backup.BackupUseCase
+	logger        *zap.Logger
+}
+
+// NewAdminHandler creates the admin handler; backupUseCase is nil when the result store does not support online backups
+func NewAdminHandler(backupUseCase backup.BackupUseCase, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		backupUseCase: backupUseCase,
+		logger:        logger,
+	}
+}
+
+// Backup creates an online backup of the SQLite database
+func (h *AdminHandler) Backup(c *fiber.Ctx) error {
+	h.logger.Info("Backup API endpoint called",
+		zap.String("ip", c.IP()),
+		zap.String("method", c.Method()))
+
+	if h.backupUseCase == nil {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Online backup is only supported for the SQLite result store",
+		})
+	}
+
+	info, err := h.backupUseCase.CreateBackup(c.UserContext())
+	if err != nil {
+		h.logger.Error("Failed to create backup", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Failed to create backup",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":     "success",
+		"path":       info.Path,
+		"size_bytes": info.SizeBytes,
+		"created_at": info.CreatedAt.Format(time.RFC3339),
+	})
+}
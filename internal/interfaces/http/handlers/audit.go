@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type AuditHandler struct {
+	auditUseCase audit.AuditUseCase
+	logger       *zap.Logger
+}
+
+func NewAuditHandler(auditUseCase audit.AuditUseCase, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: auditUseCase,
+		logger:       logger,
+	}
+}
+
+// ListEntries returns audit log entries matching the filters, newest first
+func (h *AuditHandler) ListEntries(c *fiber.Ctx) error {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	limit := c.QueryInt("limit", constants.DefaultResultsLimit)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > constants.MaxResultsLimit || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": fmt.Sprintf("limit must be between 1 and %d and offset must not be negative", constants.MaxResultsLimit),
+		})
+	}
+
+	entries, err := h.auditUseCase.ListEntries(c.UserContext(), query, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list audit entries", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Failed to list audit entries",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"count":   len(entries),
+		"limit":   limit,
+		"offset":  offset,
+		"entries": entries,
+	})
+}
+
+// parseAuditQuery reads the from/to range and the actor, method and outcome filters
+func parseAuditQuery(c *fiber.Ctx) (repositories.AuditQuery, error) {
+	from, err := helper.ParseTimeParam(c.Query("from"))
+	if err != nil {
+		return repositories.AuditQuery{}, err
+	}
+
+	to, err := helper.ParseTimeParam(c.Query("to"))
+	if err != nil {
+		return repositories.AuditQuery{}, err
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return repositories.AuditQuery{}, fmt.Errorf("from must be before to")
+	}
+
+	outcome := c.Query("outcome")
+	if outcome != "" && outcome != models.AuditOutcomeSuccess && outcome != models.AuditOutcomeFailure {
+		return repositories.AuditQuery{}, fmt.Errorf("outcome must be %s or %s", models.AuditOutcomeSuccess, models.AuditOutcomeFailure)
+	}
+
+	return repositories.AuditQuery{
+		From:    from,
+		To:      to,
+		Actor:   c.Query("actor"),
+		Method:  c.Query("method"),
+		Outcome: outcome,
+	}, nil
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"context"
+	"go-image-cleanup/internal/interfaces/http/middleware"
 	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -40,6 +43,7 @@ func (h *CleanupHandler) GetCleanupStatus(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":        "success",
 		"host_info":     stats.HostInfo,
+		"host":          stats.Host,
 		"start_time":    stats.StartTime.Format(time.RFC3339),
 		"end_time":      stats.EndTime.Format(time.RFC3339),
 		"duration":      stats.Duration.String(),
@@ -49,27 +53,56 @@ func (h *CleanupHandler) GetCleanupStatus(c *fiber.Ctx) error {
 	})
 }
 
-// TriggerCleanup handles API requests to start the cleanup process
+// GetInventory lists the images on the node with the policy decision for each one
+func (h *CleanupHandler) GetInventory(c *fiber.Ctx) error {
+	inventory, err := h.cleanupUseCase.Inventory(c.UserContext())
+	if err != nil {
+		h.logger.Error("Failed to collect image inventory", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Failed to list images",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":    "success",
+		"inventory": inventory,
+	})
+}
+
+// TriggerCleanup handles API requests to start the cleanup process.
+// With ?dry_run=true nothing is removed and the planned decisions are returned instead.
 func (h *CleanupHandler) TriggerCleanup(c *fiber.Ctx) error {
+	requestID := middleware.RequestIDFromContext(c)
 	h.logger.Info("Cleanup API endpoint called",
 		zap.String("ip", c.IP()),
-		zap.String("method", c.Method()))
+		zap.String("method", c.Method()),
+		zap.Bool("dry_run", c.QueryBool("dry_run")),
+		zap.String("request_id", requestID))
+
+	if c.QueryBool("dry_run") {
+		return h.GetInventory(c)
+	}
 
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Minute)
+	// The run outlives the request, so it must not use the request context; only the request ID is carried over
+	ctx, cancel := context.WithTimeout(helper.WithRequestID(context.Background(), requestID), constants.CleanupTimeout)
 
 	// Start cleanup in a goroutine to avoid blocking the API response
 	go func() {
 		defer cancel()
 
 		if err := h.cleanupUseCase.Cleanup(ctx); err != nil {
-			h.logger.Error("API-triggered cleanup failed", zap.Error(err))
+			h.logger.Error("API-triggered cleanup failed",
+				zap.Error(err),
+				zap.String("request_id", requestID))
 		}
 	}()
 
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
-		"status":  "accepted",
-		"message": "Cleanup job has been triggered",
-		"time":    time.Now().Format(time.RFC3339),
+		"status":     "accepted",
+		"message":    "Cleanup job has been triggered",
+		"time":       time.Now().Format(time.RFC3339),
+		"request_id": requestID,
 	})
 }
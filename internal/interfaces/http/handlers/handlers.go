@@ -2,17 +2,28 @@ package handlers
 
 import (
 	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/internal/usecases/backup"
 	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/internal/usecases/outbox"
+	"go-image-cleanup/internal/usecases/readiness"
+	"go-image-cleanup/internal/usecases/schedule"
 
 	"go.uber.org/zap"
 )
 
 type Handlers struct {
-	Health  *HealthHandler
-	Version *VersionHandler
-	Metrics *MetricsHandler
-	Cleanup *CleanupHandler
-	logger  *zap.Logger
+	Health   *HealthHandler
+	Version  *VersionHandler
+	Metrics  *MetricsHandler
+	Cleanup  *CleanupHandler
+	History  *HistoryHandler
+	Admin    *AdminHandler
+	Schedule *ScheduleHandler
+	Audit    *AuditHandler
+	Outbox   *OutboxHandler
+	logger   *zap.Logger
 }
 
 func NewHandlers(
@@ -21,12 +32,23 @@ func NewHandlers(
 	buildTime string,
 	metrics metrics.MetricsCollector,
 	cleanupUseCase cleanup.CleanupUseCase,
+	historyUseCase history.HistoryUseCase,
+	backupUseCase backup.BackupUseCase,
+	readinessUseCase readiness.ReadinessUseCase,
+	scheduleUseCase schedule.ScheduleUseCase,
+	auditUseCase audit.AuditUseCase,
+	outboxUseCase outbox.OutboxUseCase,
 ) *Handlers {
 	return &Handlers{
-		Health:  NewHealthHandler(logger),
-		Version: NewVersionHandler(version, buildTime),
-		Metrics: NewMetricsHandler(metrics, logger),
-		Cleanup: NewCleanupHandler(cleanupUseCase, logger),
-		logger:  logger,
+		Health:   NewHealthHandler(readinessUseCase, logger),
+		Version:  NewVersionHandler(version, buildTime),
+		Metrics:  NewMetricsHandler(metrics, logger),
+		Cleanup:  NewCleanupHandler(cleanupUseCase, logger),
+		History:  NewHistoryHandler(historyUseCase, logger),
+		Admin:    NewAdminHandler(backupUseCase, logger),
+		Schedule: NewScheduleHandler(scheduleUseCase, logger),
+		Audit:    NewAuditHandler(auditUseCase, logger),
+		Outbox:   NewOutboxHandler(outboxUseCase, logger),
+		logger:   logger,
 	}
 }
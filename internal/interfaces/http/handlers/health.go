@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"go-image-cleanup/internal/usecases/readiness"
 	"runtime"
 	"time"
 
@@ -9,17 +10,22 @@ import (
 )
 
 type HealthHandler struct {
-	logger    *zap.Logger
-	startTime time.Time
+	readinessUseCase readiness.ReadinessUseCase
+	logger           *zap.Logger
+	startTime        time.Time
 }
 
-func NewHealthHandler(logger *zap.Logger) *HealthHandler {
+// NewHealthHandler creates the health handler; dependency checks are skipped when readinessUseCase is nil
+func NewHealthHandler(readinessUseCase readiness.ReadinessUseCase, logger *zap.Logger) *HealthHandler {
 	return &HealthHandler{
-		logger:    logger,
-		startTime: time.Now(),
+		readinessUseCase: readinessUseCase,
+		logger:           logger,
+		startTime:        time.Now(),
 	}
 }
 
+// Status reports liveness with runtime statistics and dependency checks.
+// It always returns 200 so that a failing dependency does not restart the service; use Ready for gating.
 func (h *HealthHandler) Status(c *fiber.Ctx) error {
 	// Log request details
 	h.logger.Debug("Health check requested",
@@ -47,6 +53,12 @@ func (h *HealthHandler) Status(c *fiber.Ctx) error {
 		},
 	}
 
+	if h.readinessUseCase != nil {
+		report := h.readinessUseCase.Check(c.UserContext())
+		healthInfo["ready"] = report.Ready()
+		healthInfo["checks"] = report.Checks
+	}
+
 	// Log successful health check
 	h.logger.Info("Health check successful",
 		zap.String("ip", c.IP()),
@@ -55,3 +67,28 @@ func (h *HealthHandler) Status(c *fiber.Ctx) error {
 
 	return c.JSON(healthInfo)
 }
+
+// Ready returns 200 when every dependency check passes and 503 with the failing checks otherwise
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	if h.readinessUseCase == nil {
+		return c.JSON(fiber.Map{
+			"status": "ready",
+			"checks": []readiness.CheckResult{},
+		})
+	}
+
+	report := h.readinessUseCase.Check(c.UserContext())
+
+	status := fiber.StatusOK
+	state := "ready"
+	if !report.Ready() {
+		status = fiber.StatusServiceUnavailable
+		state = "not_ready"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"status":     state,
+		"checked_at": report.CheckedAt.Format(time.RFC3339),
+		"checks":     report.Checks,
+	})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type HistoryHandler struct {
+	historyUseCase history.HistoryUseCase
+	logger         *zap.Logger
+}
+
+func NewHistoryHandler(historyUseCase history.HistoryUseCase, logger *zap.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		historyUseCase: historyUseCase,
+		logger:         logger,
+	}
+}
+
+// ListResults returns cleanup results matching the time range and host filters, newest first
+func (h *HistoryHandler) ListResults(c *fiber.Ctx) error {
+	query, err := parseResultQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	limit := c.QueryInt("limit", constants.DefaultResultsLimit)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > constants.MaxResultsLimit || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": fmt.Sprintf("limit must be between 1 and %d and offset must not be negative", constants.MaxResultsLimit),
+		})
+	}
+
+	results, err := h.historyUseCase.ListResults(c.UserContext(), query, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list cleanup results", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Failed to list cleanup results",
+			"error":   err.Error(),
+		})
+	}
+
+	if results == nil {
+		results = []repositories.CleanupResult{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"count":   len(results),
+		"limit":   limit,
+		"offset":  offset,
+		"results": results,
+	})
+}
+
+// Export streams cleanup history as CSV, JSON or NDJSON for the requested time range.
+// type=removals exports the per-image removal events of the matching runs instead of run totals.
+func (h *HistoryHandler) Export(c *fiber.Ctx) error {
+	format, err := history.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	exportType, err := history.ParseExportType(c.Query("type"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	query, err := parseResultQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	h.logger.Info("Export history API endpoint called",
+		zap.String("ip", c.IP()),
+		zap.String("format", string(format)),
+		zap.String("type", string(exportType)),
+		zap.Time("from", query.From),
+		zap.Time("to", query.To),
+		zap.String("hostname", query.Hostname),
+		zap.String("node_name", query.NodeName))
+
+	// Once the stream starts the 200 is committed, so probe the query first to report
+	// an unreachable database or a bad filter as a proper error status
+	if _, err := h.historyUseCase.ListResults(c.UserContext(), query, 1, 0); err != nil {
+		h.logger.Error("Failed to start history export", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Failed to export cleanup history",
+			"error":   err.Error(),
+		})
+	}
+
+	export, name := h.historyUseCase.Export, "history"
+	if exportType == history.ExportRemovals {
+		export, name = h.historyUseCase.ExportRemovals, "removals"
+	}
+
+	filename := fmt.Sprintf("cleanup-%s-%s.%s", name, time.Now().UTC().Format("20060102-150405"), format.Extension())
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The body is written after the handler returns, so the export must not use the request context
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.ExportTimeout)
+		defer cancel()
+
+		// A failure after this point is marked at the end of the body by the encoder
+		if _, err := export(ctx, w, format, query); err != nil {
+			h.logger.Error("Failed to stream history export", zap.Error(err))
+		}
+		if err := w.Flush(); err != nil {
+			h.logger.Warn("Failed to flush history export", zap.Error(err))
+		}
+	})
+
+	return nil
+}
+
+// parseResultQuery reads the from/to range and the hostname, node, machine_id and label filters.
+// Labels are passed as repeated label=key=value parameters.
+func parseResultQuery(c *fiber.Ctx) (repositories.ResultQuery, error) {
+	from, err := helper.ParseTimeParam(c.Query("from"))
+	if err != nil {
+		return repositories.ResultQuery{}, err
+	}
+
+	to, err := helper.ParseTimeParam(c.Query("to"))
+	if err != nil {
+		return repositories.ResultQuery{}, err
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return repositories.ResultQuery{}, fmt.Errorf("from must be before to")
+	}
+
+	var pairs []string
+	for _, value := range c.Context().QueryArgs().PeekMulti("label") {
+		pairs = append(pairs, string(value))
+	}
+	labels, err := helper.ParseLabelPairs(pairs)
+	if err != nil {
+		return repositories.ResultQuery{}, err
+	}
+
+	return repositories.ResultQuery{
+		From:      from,
+		To:        to,
+		Hostname:  c.Query("hostname"),
+		NodeName:  c.Query("node"),
+		MachineID: c.Query("machine_id"),
+		Labels:    labels,
+	}, nil
+}
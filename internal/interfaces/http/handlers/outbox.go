@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/usecases/outbox"
+	"go-image-cleanup/pkg/constants"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type OutboxHandler struct {
+	outboxUseCase outbox.OutboxUseCase
+	logger        *zap.Logger
+}
+
+func NewOutboxHandler(outboxUseCase outbox.OutboxUseCase, logger *zap.Logger) *OutboxHandler {
+	return &OutboxHandler{
+		outboxUseCase: outboxUseCase,
+		logger:        logger,
+	}
+}
+
+// ListMessages returns the pending and dead-lettered notifications with their counts, newest first
+func (h *OutboxHandler) ListMessages(c *fiber.Ctx) error {
+	status := c.Query("status")
+	if status != "" && status != models.OutboxPending && status != models.OutboxFailed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": fmt.Sprintf("status must be %s or %s", models.OutboxPending, models.OutboxFailed),
+		})
+	}
+
+	limit := c.QueryInt("limit", constants.DefaultResultsLimit)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > constants.MaxResultsLimit || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": fmt.Sprintf("limit must be between 1 and %d and offset must not be negative", constants.MaxResultsLimit),
+		})
+	}
+
+	stats, err := h.outboxUseCase.Stats(c.UserContext())
+	if err != nil {
+		return h.internalError(c, "Failed to read notification outbox", err)
+	}
+
+	messages, err := h.outboxUseCase.List(c.UserContext(), status, limit, offset)
+	if err != nil {
+		return h.internalError(c, "Failed to read notification outbox", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   "success",
+		"pending":  stats.Pending,
+		"failed":   stats.Failed,
+		"count":    len(messages),
+		"limit":    limit,
+		"offset":   offset,
+		"messages": messages,
+	})
+}
+
+// Retry schedules a pending or dead-lettered notification for immediate delivery with a fresh attempt budget
+func (h *OutboxHandler) Retry(c *fiber.Ctx) error {
+	message, err := h.outboxUseCase.Retry(c.UserContext(), c.Params("id"))
+	if errors.Is(err, repositories.ErrOutboxMessageNotFound) {
+		return h.notFound(c)
+	}
+	if err != nil {
+		return h.internalError(c, "Failed to retry notification", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":       "success",
+		"notification": message,
+	})
+}
+
+// Discard removes a notification from the outbox without delivering it
+func (h *OutboxHandler) Discard(c *fiber.Ctx) error {
+	err := h.outboxUseCase.Discard(c.UserContext(), c.Params("id"))
+	if errors.Is(err, repositories.ErrOutboxMessageNotFound) {
+		return h.notFound(c)
+	}
+	if err != nil {
+		return h.internalError(c, "Failed to discard notification", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Notification discarded",
+	})
+}
+
+func (h *OutboxHandler) notFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"status":  "error",
+		"message": "Notification not found",
+	})
+}
+
+func (h *OutboxHandler) internalError(c *fiber.Ctx, message string, err error) error {
+	h.logger.Error(message, zap.String("id", c.Params("id")), zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"status":  "error",
+		"message": message,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"go-image-cleanup/internal/interfaces/http/middleware"
+	"go-image-cleanup/internal/usecases/schedule"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type ScheduleHandler struct {
+	scheduleUseCase schedule.ScheduleUseCase
+	logger          *zap.Logger
+}
+
+func NewScheduleHandler(scheduleUseCase schedule.ScheduleUseCase, logger *zap.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleUseCase: scheduleUseCase,
+		logger:          logger,
+	}
+}
+
+// RescheduleRequest is the body of PUT /api/v1/schedules/:name
+type RescheduleRequest struct {
+	Expression string `json:"expression"`
+}
+
+// ListSchedules returns every cron job with its expression and next/previous run times
+func (h *ScheduleHandler) ListSchedules(c *fiber.Ctx) error {
+	schedules := h.scheduleUseCase.ListSchedules(c.UserContext())
+
+	return c.JSON(fiber.Map{
+		"status":    "success",
+		"schedules": schedules,
+	})
+}
+
+// Pause stops a job from running until it is resumed; the state survives restarts
+func (h *ScheduleHandler) Pause(c *fiber.Ctx) error {
+	status, err := h.scheduleUseCase.Pause(c.UserContext(), c.Params("name"), middleware.Actor(c))
+	return h.respond(c, status, err)
+}
+
+// Resume schedules a paused job again
+func (h *ScheduleHandler) Resume(c *fiber.Ctx) error {
+	status, err := h.scheduleUseCase.Resume(c.UserContext(), c.Params("name"), middleware.Actor(c))
+	return h.respond(c, status, err)
+}
+
+// Reschedule replaces the cron expression of a job
+func (h *ScheduleHandler) Reschedule(c *fiber.Ctx) error {
+	var req RescheduleRequest
+	if err := c.BodyParser(&req); err != nil || req.Expression == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": `Request body must be JSON with a non-empty "expression"`,
+		})
+	}
+
+	status, err := h.scheduleUseCase.Reschedule(c.UserContext(), c.Params("name"), req.Expression, middleware.Actor(c))
+	return h.respond(c, status, err)
+}
+
+func (h *ScheduleHandler) respond(c *fiber.Ctx, status *schedule.ScheduleStatus, err error) error {
+	switch {
+	case errors.Is(err, schedule.ErrScheduleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Schedule not found",
+		})
+	case errors.Is(err, schedule.ErrInvalidExpression):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	case err != nil:
+		h.logger.Error("Failed to update schedule",
+			zap.String("name", c.Params("name")),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Failed to update schedule",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   "success",
+		"schedule": status,
+	})
+}
@@ -18,6 +18,11 @@ func NewVersionHandler(version, buildTime string) *VersionHandler {
 	}
 }
 
+// Version returns the build version of the service
+func (h *VersionHandler) Version() string {
+	return h.version
+}
+
 func (h *VersionHandler) GetVersion(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"version":   h.version,
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/usecases/audit"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// auditWriteTimeout bounds the audit insert, which runs after the request context may be gone
+const auditWriteTimeout = 5 * time.Second
+
+// Audit records every state-changing request (anything but GET, HEAD and OPTIONS) passing through it,
+// including requests rejected by authentication. A failure to write the entry is logged and does not
+// change the response.
+//
+// Requests that were not authorized are recorded with method, route, source IP and status only: the
+// middleware runs before authentication, so storing their path, parameters or body would let anyone
+// who can reach the port write arbitrary data into the append-only log.
+func Audit(auditUseCase audit.AuditUseCase, logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		errorMessage := ""
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberError *fiber.Error
+			if errors.As(err, &fiberError) {
+				status = fiberError.Code
+			}
+			errorMessage = err.Error()
+		}
+
+		outcome := models.AuditOutcomeSuccess
+		if status >= fiber.StatusBadRequest {
+			outcome = models.AuditOutcomeFailure
+			if errorMessage == "" {
+				errorMessage = responseMessage(c)
+			}
+		}
+
+		entry := models.AuditEntry{
+			Time:       start.UTC(),
+			Actor:      Actor(c),
+			SourceIP:   c.IP(),
+			RequestID:  RequestIDFromContext(c),
+			Method:     c.Method(),
+			Route:      c.Route().Path,
+			Status:     status,
+			Outcome:    outcome,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if Authorized(c) {
+			entry.Path = c.Path()
+			entry.Params = auditParams(c)
+			entry.Error = errorMessage
+		}
+		if key := APIKeyFromContext(c); key != nil {
+			entry.APIKeyID = key.ID
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		defer cancel()
+		if recordErr := auditUseCase.Record(ctx, entry); recordErr != nil {
+			logger.Error("Failed to record audit entry",
+				zap.Error(recordErr),
+				zap.String("actor", entry.Actor),
+				zap.String("method", entry.Method),
+				zap.String("path", entry.Path),
+				zap.Int("status", entry.Status))
+		}
+
+		return err
+	}
+}
+
+// Actor identifies the caller: the API key name, or the client IP (or the unix socket) when the request
+// was not authenticated
+func Actor(c *fiber.Ctx) string {
+	if key := APIKeyFromContext(c); key != nil {
+		return "api_key:" + key.Name
+	}
+	if IsUnixSocket(c) {
+		return "unix:" + c.Context().LocalAddr().String()
+	}
+	return "ip:" + c.IP()
+}
+
+func auditParams(c *fiber.Ctx) models.AuditParams {
+	var params models.AuditParams
+
+	if names := c.Route().Params; len(names) > 0 {
+		params.Path = make(map[string]string, len(names))
+		for _, name := range names {
+			params.Path[name] = c.Params(name)
+		}
+	}
+	if queries := c.Queries(); len(queries) > 0 {
+		params.Query = queries
+	}
+	params.Body = strings.TrimSpace(string(c.Body()))
+
+	return params
+}
+
+// responseMessage extracts the "message" field of a JSON error response
+func responseMessage(c *fiber.Ctx) string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+		return ""
+	}
+	return body.Message
+}
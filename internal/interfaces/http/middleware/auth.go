@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"errors"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/usecases/auth"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// apiKeyLocalsKey is the fiber.Ctx locals key holding the authenticated *models.APIKey
+const apiKeyLocalsKey = "api_key"
+
+// authorizedLocalsKey marks a request that passed Require, including every request when auth is disabled
+const authorizedLocalsKey = "authorized"
+
+// Auth authenticates bearer API keys and enforces per-route scopes
+type Auth struct {
+	authUseCase      auth.AuthUseCase
+	metricsCollector metrics.MetricsCollector
+	logger           *zap.Logger
+}
+
+// NewAuth creates the auth middleware; a nil authUseCase disables authentication
+func NewAuth(authUseCase auth.AuthUseCase, metricsCollector metrics.MetricsCollector, logger *zap.Logger) *Auth {
+	return &Auth{
+		authUseCase:      authUseCase,
+		metricsCollector: metricsCollector,
+		logger:           logger,
+	}
+}
+
+// Enabled reports whether requests are authenticated
+func (a *Auth) Enabled() bool {
+	return a.authUseCase != nil
+}
+
+// Require returns a handler that rejects requests without a valid API key holding scope
+func (a *Auth) Require(scope models.Scope) fiber.Handler {
+	if !a.Enabled() {
+		return func(c *fiber.Ctx) error {
+			c.Locals(authorizedLocalsKey, true)
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		token, ok := bearerToken(c)
+		if !ok {
+			return a.reject(c, fiber.StatusUnauthorized, "missing_token", scope, nil, "Missing bearer token")
+		}
+
+		key, err := a.authUseCase.Authenticate(c.UserContext(), token)
+		if errors.Is(err, auth.ErrInvalidAPIKey) {
+			return a.reject(c, fiber.StatusUnauthorized, "invalid_token", scope, nil, "Invalid or revoked API key")
+		}
+		if err != nil {
+			a.logger.Error("Failed to authenticate request", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Failed to authenticate request",
+			})
+		}
+
+		if !key.HasScope(scope) {
+			return a.reject(c, fiber.StatusForbidden, "insufficient_scope", scope, key, "API key does not have the required scope")
+		}
+
+		c.Locals(apiKeyLocalsKey, key)
+		c.Locals(authorizedLocalsKey, true)
+
+		a.logger.Info("API request authorized",
+			zap.String("key_id", key.ID),
+			zap.String("key_name", key.Name),
+			zap.String("scope", string(scope)),
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.String("ip", c.IP()),
+			zap.String("request_id", RequestIDFromContext(c)))
+
+		return c.Next()
+	}
+}
+
+func (a *Auth) reject(c *fiber.Ctx, status int, reason string, scope models.Scope, key *models.APIKey, message string) error {
+	a.metricsCollector.IncAuthFailures(c.Route().Path, reason)
+
+	fields := []zap.Field{
+		zap.String("reason", reason),
+		zap.String("scope", string(scope)),
+		zap.String("method", c.Method()),
+		zap.String("path", c.Path()),
+		zap.String("ip", c.IP()),
+		zap.String("user_agent", string(c.Request().Header.UserAgent())),
+		zap.String("request_id", RequestIDFromContext(c)),
+	}
+	if key != nil {
+		fields = append(fields, zap.String("key_id", key.ID), zap.String("key_name", key.Name))
+	}
+	a.logger.Warn("API authentication failed", fields...)
+
+	if status == fiber.StatusUnauthorized {
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="image-cleanup"`)
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"status":  "error",
+		"message": message,
+	})
+}
+
+// APIKeyFromContext returns the API key that authenticated the request, or nil when auth is disabled
+func APIKeyFromContext(c *fiber.Ctx) *models.APIKey {
+	key, _ := c.Locals(apiKeyLocalsKey).(*models.APIKey)
+	return key
+}
+
+// Authorized reports whether the request passed authentication and the scope check. It is false for
+// requests rejected by Require or by a check running before it, and for routes without Require.
+func Authorized(c *fiber.Ctx) bool {
+	authorized, _ := c.Locals(authorizedLocalsKey).(bool)
+	return authorized
+}
+
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	header := c.Get(fiber.HeaderAuthorization)
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
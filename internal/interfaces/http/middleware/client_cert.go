@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"go-image-cleanup/internal/domain/metrics"
+	"net"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequireClientCert rejects requests that did not present a client certificate verified against the configured CA.
+// Requests over the unix socket are let through: they are local and restricted by the socket file permissions.
+func RequireClientCert(metricsCollector metrics.MetricsCollector, logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if IsUnixSocket(c) {
+			return c.Next()
+		}
+
+		state := c.Context().TLSConnectionState()
+		if state == nil || len(state.VerifiedChains) == 0 {
+			metricsCollector.IncAuthFailures(c.Route().Path, "client_cert_required")
+			logger.Warn("Request without verified client certificate rejected",
+				zap.String("method", c.Method()),
+				zap.String("path", c.Path()),
+				zap.String("ip", c.IP()),
+				zap.Bool("tls", state != nil))
+
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "A verified client certificate is required",
+			})
+		}
+
+		logger.Debug("Client certificate verified",
+			zap.String("subject", state.VerifiedChains[0][0].Subject.String()),
+			zap.String("path", c.Path()))
+
+		return c.Next()
+	}
+}
+
+// IsUnixSocket reports whether the request arrived on the unix socket rather than the TCP port
+func IsUnixSocket(c *fiber.Ctx) bool {
+	_, ok := c.Context().LocalAddr().(*net.UnixAddr)
+	return ok
+}
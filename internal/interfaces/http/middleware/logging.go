@@ -8,14 +8,15 @@ import (
 
 func Logger(log *zap.Logger) fiber.Handler {
 	return fiberLogger.New(fiberLogger.Config{
-		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
+		Format: "[${time}] ${status} - ${latency} ${method} ${path} ${locals:request_id}\n",
 		Done: func(c *fiber.Ctx, logString []byte) {
 			if c.Response().StatusCode() >= 400 {
 				log.Warn("HTTP request failed",
 					zap.Int("status", c.Response().StatusCode()),
 					zap.String("method", c.Method()),
 					zap.String("path", c.Path()),
-					zap.String("ip", c.IP()))
+					zap.String("ip", c.IP()),
+					zap.String("request_id", RequestIDFromContext(c)))
 			}
 		},
 	})
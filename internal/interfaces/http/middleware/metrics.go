@@ -2,27 +2,34 @@ package middleware
 
 import (
 	"go-image-cleanup/internal/domain/metrics"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
+// unmatchedRoute labels requests that no route handled, keeping label cardinality bounded
+const unmatchedRoute = "/other"
+
 func MetricsMiddleware(metricsCollector metrics.MetricsCollector, logger *zap.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Store start time
 		start := time.Now()
 
+		metricsCollector.IncHttpInFlight()
+		defer metricsCollector.DecHttpInFlight()
+
 		// Process request
 		err := c.Next()
+		duration := time.Since(start)
 
 		// Get response status
 		status := c.Response().StatusCode()
-		path := getPathPattern(c.Path())
+		path := routePattern(c, status)
 
 		// Record request metrics
 		metricsCollector.IncHttpRequests(path, c.Method(), status)
+		metricsCollector.ObserveHttpDuration(path, c.Method(), status, duration)
 
 		// Track timeouts
 		if status == fiber.StatusRequestTimeout {
@@ -47,37 +54,19 @@ func MetricsMiddleware(metricsCollector metrics.MetricsCollector, logger *zap.Lo
 			zap.String("path", path),
 			zap.String("method", c.Method()),
 			zap.Int("status", status),
-			zap.Duration("duration", time.Since(start)))
+			zap.Duration("duration", duration))
 
 		return err
 	}
 }
 
-// getPathPattern returns the pattern of the path for consistent metrics
-func getPathPattern(path string) string {
-	// Strip trailing slash
-	if len(path) > 1 && path[len(path)-1] == '/' {
-		path = path[:len(path)-1]
-	}
-
-	switch {
-	case path == "/":
-		return "/"
-	case path == "/health":
-		return "/health"
-	case path == "/metrics":
-		return "/metrics"
-	case path == "/version":
-		return "/version"
-	case path == "/favicon.ico":
-		return "/favicon.ico"
-	case strings.HasPrefix(path, "/api/v1/"):
-		// For API endpoints, keep only first part after /api/v1/
-		parts := strings.Split(path[7:], "/")
-		if len(parts) > 0 {
-			return "/api/v1/" + parts[0]
-		}
+// routePattern returns the route template Fiber matched (e.g. /api/v1/schedules/:name), read after
+// c.Next() so it reflects the handler that served the request rather than this middleware.
+// Requests that only reached the catch-all 404 handler mounted at "/" are grouped as unmatchedRoute.
+func routePattern(c *fiber.Ctx, status int) string {
+	path := c.Route().Path
+	if path == "" || (path == "/" && status == fiber.StatusNotFound) {
+		return unmatchedRoute
 	}
-
-	return "/other"
+	return path
 }
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"go-image-cleanup/pkg/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// requestIDLocalsKey is the fiber.Ctx locals key holding the request ID
+const requestIDLocalsKey = "request_id"
+
+// RequestID accepts the client's X-Request-ID when it is a safe printable token and generates one otherwise.
+// The ID is echoed in the response, stored in the locals and added to the request's user context, so code
+// that only sees a context.Context can read it with helper.RequestIDFromContext.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Copy the header value: fiber strings point into a buffer reused after the request
+		requestID := string([]byte(c.Get(fiber.HeaderXRequestID)))
+		if !helper.ValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.Locals(requestIDLocalsKey, requestID)
+		c.SetUserContext(helper.WithRequestID(c.UserContext(), requestID))
+
+		return c.Next()
+	}
+}
+
+// RequestIDFromContext returns the request ID assigned by the RequestID middleware
+func RequestIDFromContext(c *fiber.Ctx) string {
+	requestID, _ := c.Locals(requestIDLocalsKey).(string)
+	return requestID
+}
@@ -0,0 +1,558 @@
+package router
+
+import (
+	"encoding/json"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/interfaces/http/handlers"
+	"go-image-cleanup/internal/interfaces/http/openapi"
+	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/internal/usecases/readiness"
+	"go-image-cleanup/internal/usecases/schedule"
+	"go-image-cleanup/pkg/constants"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const bearerAuth = "bearerAuth"
+
+// BuildOpenAPISpec describes every route registered by SetupRoutes.
+// TestOpenAPISpecCoversRoutes fails when a route under /api/v1 is missing here.
+func BuildOpenAPISpec(version string) *openapi.Document {
+	return &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Image Cleanup Service API",
+			Description: "Management API of the container image cleanup daemon running on each node.",
+			Version:     version,
+		},
+		Servers: []openapi.Server{{URL: "/", Description: "The node running the service"}},
+		Tags: []openapi.Tag{
+			{Name: "system", Description: "Health, version and metrics"},
+			{Name: "cleanup", Description: "Cleanup status and manual runs"},
+			{Name: "history", Description: "Stored cleanup results"},
+			{Name: "admin", Description: "Database administration and audit log"},
+			{Name: "schedules", Description: "Cron jobs of this node"},
+			{Name: "notifications", Description: "Notifications waiting to be delivered again"},
+		},
+		Paths: map[string]*openapi.PathItem{
+			"/health": {
+				"get": {
+					OperationID: "getHealth",
+					Summary:     "Service health and runtime statistics",
+					Tags:        []string{"system"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Service is running", Content: openapi.JSON(openapi.Ref("Health"))},
+						"408": errorResponse("Health check timed out"),
+					},
+				},
+			},
+			"/ready": {
+				"get": {
+					OperationID: "getReady",
+					Summary:     "Readiness based on container runtime, database, scheduler and last cleanup run",
+					Tags:        []string{"system"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "All dependency checks pass", Content: openapi.JSON(openapi.Ref("Readiness"))},
+						"408": errorResponse("Readiness check timed out"),
+						"503": {Description: "At least one dependency check fails", Content: openapi.JSON(openapi.Ref("Readiness"))},
+					},
+				},
+			},
+			"/version": {
+				"get": {
+					OperationID: "getVersion",
+					Summary:     "Build version",
+					Tags:        []string{"system"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Version information", Content: openapi.JSON(openapi.Ref("Version"))},
+					},
+				},
+			},
+			"/metrics": {
+				"get": {
+					OperationID: "getMetrics",
+					Summary:     "Prometheus metrics",
+					Tags:        []string{"system"},
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "Metrics in the Prometheus text exposition format",
+							Content:     map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+						},
+					},
+				},
+			},
+			"/api/v1/openapi.json": {
+				"get": {
+					OperationID: "getOpenAPISpec",
+					Summary:     "This OpenAPI document",
+					Tags:        []string{"system"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "OpenAPI 3 document", Content: openapi.JSON(&openapi.Schema{Type: "object"})},
+					},
+				},
+			},
+			"/api/v1/cleanup": {
+				"get": secured(models.ScopeRead, &openapi.Operation{
+					OperationID: "getCleanupStatus",
+					Summary:     "Latest cleanup run",
+					Tags:        []string{"cleanup"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Latest cleanup run", Content: openapi.JSON(openapi.Ref("CleanupStatus"))},
+						"500": statusErrorResponse("No cleanup result is available or it could not be read"),
+					},
+				}),
+				"post": secured(models.ScopeTrigger, &openapi.Operation{
+					OperationID: "triggerCleanup",
+					Summary:     "Start a cleanup run in the background",
+					Tags:        []string{"cleanup"},
+					Parameters: []openapi.Parameter{{
+						Name: "dry_run", In: "query", Description: "Return the images the run would remove instead of removing them",
+						Schema: &openapi.Schema{Type: "boolean", Default: false},
+					}},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Dry run: planned decision for every image", Content: openapi.JSON(openapi.Ref("InventoryResponse"))},
+						"202": {Description: "Cleanup run started", Content: openapi.JSON(openapi.Ref("CleanupAccepted"))},
+						"500": statusErrorResponse("Dry run: images could not be listed"),
+					},
+				}),
+			},
+			"/api/v1/images": {
+				"get": secured(models.ScopeRead, &openapi.Operation{
+					OperationID: "getInventory",
+					Summary:     "Images on the node with the cleanup policy decision for each one",
+					Tags:        []string{"cleanup"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Current image inventory", Content: openapi.JSON(openapi.Ref("InventoryResponse"))},
+						"500": statusErrorResponse("Images could not be listed from the container runtime"),
+					},
+				}),
+			},
+			"/api/v1/results": {
+				"get": secured(models.ScopeRead, &openapi.Operation{
+					OperationID: "listResults",
+					Summary:     "List cleanup runs, newest first",
+					Tags:        []string{"history"},
+					Parameters: append(resultQueryParameters(),
+						openapi.Parameter{Name: "limit", In: "query", Description: "Maximum number of results", Schema: &openapi.Schema{
+							Type: "integer", Minimum: float(1), Maximum: float(constants.MaxResultsLimit), Default: constants.DefaultResultsLimit,
+						}},
+						openapi.Parameter{Name: "offset", In: "query", Description: "Number of results to skip", Schema: &openapi.Schema{
+							Type: "integer", Minimum: float(0), Default: 0,
+						}},
+					),
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Matching cleanup runs", Content: openapi.JSON(openapi.Ref("ResultList"))},
+						"400": statusErrorResponse("Invalid filter or pagination parameter"),
+						"500": statusErrorResponse("Results could not be read"),
+					},
+				}),
+			},
+			"/api/v1/export": {
+				"get": secured(models.ScopeRead, &openapi.Operation{
+					OperationID: "exportHistory",
+					Summary:     "Stream cleanup runs or image removals as a file download",
+					Description: "Runs are streamed in ascending start time order without loading the whole history into memory. With type=removals the per-image removal events (removed or failed) of the matching runs are streamed instead, ordered by run and removal time. If the export fails after the response has started, the body ends with an error marker: a trailing {\"error\": ...} object in NDJSON, an unterminated JSON array ending with that object, or a final error,<message> CSV row.",
+					Tags:        []string{"history"},
+					Parameters: append([]openapi.Parameter{{
+						Name: "format", In: "query", Description: "Output format",
+						Schema: &openapi.Schema{Type: "string", Enum: []string{string(history.FormatCSV), string(history.FormatJSON), string(history.FormatNDJSON)}, Default: string(history.FormatJSON)},
+					}, {
+						Name: "type", In: "query", Description: "Exported data: run totals or per-image removal events",
+						Schema: &openapi.Schema{Type: "string", Enum: []string{string(history.ExportRuns), string(history.ExportRemovals)}, Default: string(history.ExportRuns)},
+					}}, resultQueryParameters()...),
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "Exported cleanup runs or image removals",
+							Headers: map[string]openapi.Header{
+								fiber.HeaderContentDisposition: {Description: "Attachment file name", Schema: &openapi.Schema{Type: "string"}},
+							},
+							Content: map[string]openapi.MediaType{
+								"text/csv":                         {Schema: &openapi.Schema{Type: "string", Description: "CSV with a header row and the ExportRecord (or ExportRemovalRecord) fields as columns"}},
+								history.FormatJSON.ContentType():   {Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{OneOf: []*openapi.Schema{openapi.Ref("ExportRecord"), openapi.Ref("ExportRemovalRecord")}}}},
+								history.FormatNDJSON.ContentType(): {Schema: &openapi.Schema{Type: "string", Description: "One ExportRecord (or ExportRemovalRecord) JSON object per line"}},
+							},
+						},
+						"400": statusErrorResponse("Invalid format or filter parameter"),
+						"500": statusErrorResponse("Result store could not be queried"),
+					},
+				}),
+			},
+			"/api/v1/schedules": {
+				"get": secured(models.ScopeRead, &openapi.Operation{
+					OperationID: "listSchedules",
+					Summary:     "Cron jobs with their expression and next/previous run times",
+					Tags:        []string{"schedules"},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Registered jobs", Content: openapi.JSON(openapi.Ref("ScheduleList"))},
+					},
+				}),
+			},
+			"/api/v1/schedules/{name}": {
+				"put": secured(models.ScopeAdmin, scheduleOperation(&openapi.Operation{
+					OperationID: "rescheduleJob",
+					Summary:     "Change the cron expression of a job",
+					Description: "The new expression is persisted and replaces the configured schedule after restarts. A paused job stays paused.",
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  openapi.JSON(openapi.SchemaOf(handlers.RescheduleRequest{})),
+					},
+				})),
+			},
+			"/api/v1/schedules/{name}/pause": {
+				"post": secured(models.ScopeAdmin, scheduleOperation(&openapi.Operation{
+					OperationID: "pauseJob",
+					Summary:     "Stop a job from running until it is resumed; survives restarts",
+				})),
+			},
+			"/api/v1/schedules/{name}/resume": {
+				"post": secured(models.ScopeAdmin, scheduleOperation(&openapi.Operation{
+					OperationID: "resumeJob",
+					Summary:     "Schedule a paused job again",
+				})),
+			},
+			"/api/v1/notifications/outbox": {
+				"get": secured(models.ScopeRead, &openapi.Operation{
+					OperationID: "listOutboxMessages",
+					Summary:     "Failed notification deliveries waiting for a retry or dead-lettered, newest first",
+					Description: "A delivery that fails is retried with exponential backoff. After NOTIFY_OUTBOX_MAX_ATTEMPTS attempts it is kept with status failed until it is retried or discarded.",
+					Tags:        []string{"notifications"},
+					Parameters: []openapi.Parameter{
+						{Name: "status", In: "query", Description: "Only messages with this status", Schema: &openapi.Schema{
+							Type: "string", Enum: []string{models.OutboxPending, models.OutboxFailed},
+						}},
+						{Name: "limit", In: "query", Description: "Maximum number of messages", Schema: &openapi.Schema{
+							Type: "integer", Minimum: float(1), Maximum: float(constants.MaxResultsLimit), Default: constants.DefaultResultsLimit,
+						}},
+						{Name: "offset", In: "query", Description: "Number of messages to skip", Schema: &openapi.Schema{
+							Type: "integer", Minimum: float(0), Default: 0,
+						}},
+					},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Outbox counts and matching messages", Content: openapi.JSON(openapi.Ref("OutboxList"))},
+						"400": statusErrorResponse("Invalid status or pagination parameter"),
+						"500": statusErrorResponse("The outbox could not be read"),
+					},
+				}),
+			},
+			"/api/v1/notifications/outbox/{id}/retry": {
+				"post": secured(models.ScopeAdmin, outboxOperation(&openapi.Operation{
+					OperationID: "retryOutboxMessage",
+					Summary:     "Deliver a pending or dead-lettered notification now, with a fresh attempt budget",
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Message queued for delivery", Content: openapi.JSON(openapi.Ref("OutboxRetried"))},
+					},
+				})),
+			},
+			"/api/v1/notifications/outbox/{id}": {
+				"delete": secured(models.ScopeAdmin, outboxOperation(&openapi.Operation{
+					OperationID: "discardOutboxMessage",
+					Summary:     "Remove a notification from the outbox without delivering it",
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Message removed", Content: openapi.JSON(openapi.Ref("StatusMessage"))},
+					},
+				})),
+			},
+			"/api/v1/audit": {
+				"get": secured(models.ScopeAdmin, &openapi.Operation{
+					OperationID: "listAuditEntries",
+					Summary:     "State-changing API calls on this node, newest first",
+					Description: "Every /api/v1 request other than GET, HEAD and OPTIONS is recorded, including requests rejected by authentication. Entries cannot be changed or deleted.",
+					Tags:        []string{"admin"},
+					Parameters: []openapi.Parameter{
+						{Name: "from", In: "query", Description: "Start of range (inclusive), RFC3339 or YYYY-MM-DD", Schema: &openapi.Schema{Type: "string"}},
+						{Name: "to", In: "query", Description: "End of range (exclusive), RFC3339 or YYYY-MM-DD", Schema: &openapi.Schema{Type: "string"}},
+						{Name: "actor", In: "query", Description: "Only calls by this actor, e.g. api_key:ops or ip:10.0.0.1", Schema: &openapi.Schema{Type: "string"}},
+						{Name: "method", In: "query", Description: "Only calls with this HTTP method, or TELEGRAM for bot commands", Schema: &openapi.Schema{Type: "string"}},
+						{Name: "outcome", In: "query", Description: "Only successful or failed calls", Schema: &openapi.Schema{
+							Type: "string", Enum: []string{models.AuditOutcomeSuccess, models.AuditOutcomeFailure},
+						}},
+						{Name: "limit", In: "query", Description: "Maximum number of entries", Schema: &openapi.Schema{
+							Type: "integer", Minimum: float(1), Maximum: float(constants.MaxResultsLimit), Default: constants.DefaultResultsLimit,
+						}},
+						{Name: "offset", In: "query", Description: "Number of entries to skip", Schema: &openapi.Schema{
+							Type: "integer", Minimum: float(0), Default: 0,
+						}},
+					},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "Matching audit entries", Content: openapi.JSON(openapi.Ref("AuditList"))},
+						"400": statusErrorResponse("Invalid filter or pagination parameter"),
+						"500": statusErrorResponse("The audit log could not be read"),
+					},
+				}),
+			},
+			"/api/v1/admin/backup": {
+				"post": secured(models.ScopeAdmin, &openapi.Operation{
+					OperationID: "createBackup",
+					Summary:     "Create an online backup of the SQLite database",
+					Tags:        []string{"admin"},
+					Responses: map[string]openapi.Response{
+						"201": {Description: "Backup created", Content: openapi.JSON(openapi.Ref("BackupCreated"))},
+						"500": statusErrorResponse("Backup failed"),
+						"501": statusErrorResponse("The result store does not support online backups"),
+					},
+				}),
+			},
+		},
+		Components: openapi.Components{
+			Schemas: componentSchemas(),
+			SecuritySchemes: map[string]openapi.SecurityScheme{
+				bearerAuth: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "API key",
+					Description:  "API key with the scope named in x-required-scope. Only enforced when AUTH_ENABLED=true.",
+				},
+			},
+		},
+	}
+}
+
+// secured marks an operation as requiring an API key with scope and adds the auth error responses
+func secured(scope models.Scope, op *openapi.Operation) *openapi.Operation {
+	op.Security = []map[string][]string{{bearerAuth: {}}}
+	op.RequiredScope = string(scope)
+	op.Responses["401"] = statusErrorResponse("Missing, unknown or revoked API key")
+	op.Responses["403"] = statusErrorResponse("API key lacks the required scope, or no verified client certificate was presented")
+	return op
+}
+
+// scheduleOperation adds the job name parameter and the shared responses of the schedule update endpoints
+func scheduleOperation(op *openapi.Operation) *openapi.Operation {
+	op.Tags = []string{"schedules"}
+	op.Parameters = []openapi.Parameter{{
+		Name: "name", In: "path", Required: true, Description: "Job name",
+		Schema: &openapi.Schema{Type: "string", Enum: []string{models.ScheduleCleanup, models.ScheduleMaintenance, models.ScheduleDigest}},
+	}}
+	op.Responses = map[string]openapi.Response{
+		"200": {Description: "Updated job", Content: openapi.JSON(openapi.Ref("ScheduleUpdated"))},
+		"400": statusErrorResponse("Invalid cron expression or request body"),
+		"404": statusErrorResponse("Unknown job name"),
+		"500": statusErrorResponse("The schedule could not be saved"),
+	}
+	return op
+}
+
+// outboxOperation adds the message ID parameter and the shared error responses of the outbox endpoints
+func outboxOperation(op *openapi.Operation) *openapi.Operation {
+	op.Tags = []string{"notifications"}
+	op.Parameters = []openapi.Parameter{{
+		Name: "id", In: "path", Required: true, Description: "Outbox message ID", Schema: &openapi.Schema{Type: "string"},
+	}}
+	op.Responses["404"] = statusErrorResponse("Unknown message ID")
+	op.Responses["500"] = statusErrorResponse("The outbox could not be updated")
+	return op
+}
+
+func resultQueryParameters() []openapi.Parameter {
+	explode := true
+	return []openapi.Parameter{
+		{Name: "from", In: "query", Description: "Start of range (inclusive), RFC3339 or YYYY-MM-DD", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "to", In: "query", Description: "End of range (exclusive), RFC3339 or YYYY-MM-DD", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "hostname", In: "query", Description: "Only runs from this hostname", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "node", In: "query", Description: "Only runs from this node name", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "machine_id", In: "query", Description: "Only runs from this machine ID", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "label", In: "query", Description: "Host label as key=value; repeat to require several labels", Explode: &explode,
+			Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+	}
+}
+
+func errorResponse(description string) openapi.Response {
+	return openapi.Response{Description: description, Content: openapi.JSON(openapi.Ref("ErrorResponse"))}
+}
+
+func statusErrorResponse(description string) openapi.Response {
+	return openapi.Response{Description: description, Content: openapi.JSON(openapi.Ref("StatusError"))}
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func componentSchemas() map[string]*openapi.Schema {
+	str := func(description string) *openapi.Schema {
+		return &openapi.Schema{Type: "string", Description: description}
+	}
+	integer := func(description string) *openapi.Schema {
+		return &openapi.Schema{Type: "integer", Description: description}
+	}
+	statusEnum := func(values ...string) *openapi.Schema {
+		return &openapi.Schema{Type: "string", Enum: values}
+	}
+
+	return map[string]*openapi.Schema{
+		"ErrorResponse": openapi.SchemaOf(ErrorResponse{}),
+		"StatusError": {
+			Type:     "object",
+			Required: []string{"status", "message"},
+			Properties: map[string]*openapi.Schema{
+				"status":  statusEnum("error"),
+				"message": str("Human readable error"),
+				"error":   str("Underlying error, when available"),
+			},
+		},
+		"Host":                openapi.SchemaOf(models.Host{}),
+		"CleanupResult":       openapi.SchemaOf(repositories.CleanupResult{}),
+		"ExportRecord":        openapi.SchemaOf(history.Record{}),
+		"ExportRemovalRecord": openapi.SchemaOf(history.RemovalRecord{}),
+		"CleanupStatus": {
+			Type:     "object",
+			Required: []string{"status", "host_info", "host", "start_time", "end_time", "duration", "total_count", "removed_count", "skipped_count"},
+			Properties: map[string]*openapi.Schema{
+				"status":        statusEnum("success"),
+				"host_info":     str("Formatted host description"),
+				"host":          openapi.Ref("Host"),
+				"start_time":    {Type: "string", Format: "date-time"},
+				"end_time":      {Type: "string", Format: "date-time"},
+				"duration":      str("Go duration string, e.g. 1m30s"),
+				"total_count":   integer("Images found"),
+				"removed_count": integer("Images removed"),
+				"skipped_count": integer("Images skipped because they are in use"),
+			},
+		},
+		"Inventory": openapi.SchemaOf(cleanup.Inventory{}),
+		"InventoryResponse": {
+			Type:     "object",
+			Required: []string{"status", "inventory"},
+			Properties: map[string]*openapi.Schema{
+				"status":    statusEnum("success"),
+				"inventory": openapi.Ref("Inventory"),
+			},
+		},
+		"CleanupAccepted": {
+			Type:     "object",
+			Required: []string{"status", "message", "time", "request_id"},
+			Properties: map[string]*openapi.Schema{
+				"status":     statusEnum("accepted"),
+				"message":    str(""),
+				"time":       {Type: "string", Format: "date-time"},
+				"request_id": str("Request ID carried into the run's logs, saved result and notification"),
+			},
+		},
+		"ResultList": {
+			Type:     "object",
+			Required: []string{"status", "count", "limit", "offset", "results"},
+			Properties: map[string]*openapi.Schema{
+				"status":  statusEnum("success"),
+				"count":   integer("Number of results in this page"),
+				"limit":   integer(""),
+				"offset":  integer(""),
+				"results": {Type: "array", Items: openapi.Ref("CleanupResult")},
+			},
+		},
+		"BackupCreated": {
+			Type:     "object",
+			Required: []string{"status", "path", "size_bytes", "created_at"},
+			Properties: map[string]*openapi.Schema{
+				"status":     statusEnum("success"),
+				"path":       str("Backup file on the node"),
+				"size_bytes": {Type: "integer", Format: "int64"},
+				"created_at": {Type: "string", Format: "date-time"},
+			},
+		},
+		"Health": {
+			Type:     "object",
+			Required: []string{"status", "uptime", "timestamp", "system"},
+			Properties: map[string]*openapi.Schema{
+				"status":    statusEnum("ok"),
+				"uptime":    str("Go duration string"),
+				"timestamp": {Type: "string", Format: "date-time"},
+				"system":    {Type: "object", Description: "Goroutine count and memory statistics"},
+				"ready":     {Type: "boolean", Description: "Whether every dependency check passes"},
+				"checks":    {Type: "array", Items: openapi.Ref("CheckResult")},
+			},
+		},
+		"CheckResult": openapi.SchemaOf(readiness.CheckResult{}),
+		"Readiness": {
+			Type:     "object",
+			Required: []string{"status", "checks"},
+			Properties: map[string]*openapi.Schema{
+				"status":     {Type: "string", Enum: []string{"ready", "not_ready"}},
+				"checked_at": {Type: "string", Format: "date-time"},
+				"checks":     {Type: "array", Items: openapi.Ref("CheckResult")},
+			},
+		},
+		"Schedule": openapi.SchemaOf(schedule.ScheduleStatus{}),
+		"ScheduleList": {
+			Type:     "object",
+			Required: []string{"status", "schedules"},
+			Properties: map[string]*openapi.Schema{
+				"status":    statusEnum("success"),
+				"schedules": {Type: "array", Items: openapi.Ref("Schedule")},
+			},
+		},
+		"ScheduleUpdated": {
+			Type:     "object",
+			Required: []string{"status", "schedule"},
+			Properties: map[string]*openapi.Schema{
+				"status":   statusEnum("success"),
+				"schedule": openapi.Ref("Schedule"),
+			},
+		},
+		"AuditEntry": openapi.SchemaOf(models.AuditEntry{}),
+		"AuditList": {
+			Type:     "object",
+			Required: []string{"status", "count", "limit", "offset", "entries"},
+			Properties: map[string]*openapi.Schema{
+				"status":  statusEnum("success"),
+				"count":   integer("Number of entries in this page"),
+				"limit":   integer(""),
+				"offset":  integer(""),
+				"entries": {Type: "array", Items: openapi.Ref("AuditEntry")},
+			},
+		},
+		"OutboxMessage": openapi.SchemaOf(models.OutboxMessage{}),
+		"OutboxList": {
+			Type:     "object",
+			Required: []string{"status", "pending", "failed", "count", "limit", "offset", "messages"},
+			Properties: map[string]*openapi.Schema{
+				"status":   statusEnum("success"),
+				"pending":  integer("Messages waiting for another attempt"),
+				"failed":   integer("Dead-lettered messages"),
+				"count":    integer("Number of messages in this page"),
+				"limit":    integer(""),
+				"offset":   integer(""),
+				"messages": {Type: "array", Items: openapi.Ref("OutboxMessage")},
+			},
+		},
+		"OutboxRetried": {
+			Type:     "object",
+			Required: []string{"status", "notification"},
+			Properties: map[string]*openapi.Schema{
+				"status":       statusEnum("success"),
+				"notification": openapi.Ref("OutboxMessage"),
+			},
+		},
+		"StatusMessage": {
+			Type:     "object",
+			Required: []string{"status", "message"},
+			Properties: map[string]*openapi.Schema{
+				"status":  statusEnum("success"),
+				"message": str(""),
+			},
+		},
+		"Version": {
+			Type:     "object",
+			Required: []string{"version", "buildTime", "status"},
+			Properties: map[string]*openapi.Schema{
+				"version":   str(""),
+				"buildTime": str(""),
+				"status":    statusEnum("ok"),
+			},
+		},
+	}
+}
+
+// openAPIHandler serves the document, encoded once at startup
+func openAPIHandler(doc *openapi.Document) fiber.Handler {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		panic("failed to encode OpenAPI document: " + err.Error())
+	}
+
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(body)
+	}
+}
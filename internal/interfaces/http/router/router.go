@@ -3,8 +3,12 @@ package router
 import (
 	"errors"
 	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/interfaces/http/dashboard"
 	"go-image-cleanup/internal/interfaces/http/handlers"
 	"go-image-cleanup/internal/interfaces/http/middleware"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/internal/usecases/auth"
 	"go-image-cleanup/pkg/constants"
 	"net/http"
 	"strings"
@@ -34,6 +38,7 @@ func NewFiberApp(logger *zap.Logger) *FiberApp {
 		WriteTimeout:          30 * time.Second, // Tăng write timeout
 		DisableKeepalive:      false,            // Enable keepalive
 		ServerHeader:          constants.ServiceName,
+		BodyLimit:             constants.MaxRequestBodyBytes, // Audit log lưu body, không nhận body lớn
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			// Default status code and error message
 			status := fiber.StatusInternalServerError
@@ -93,9 +98,13 @@ func NewFiberApp(logger *zap.Logger) *FiberApp {
 	return &FiberApp{app}
 }
 
-func SetupRoutes(app *FiberApp, handlers *handlers.Handlers, metricsCollector metrics.MetricsCollector, logger *zap.Logger) {
+// SetupRoutes registers middleware and routes. A nil authUseCase leaves /api/v1 unauthenticated and a nil
+// auditUseCase disables the audit log; requireClientCert restricts /api/v1 to clients with a certificate
+// verified by the TLS client CA.
+func SetupRoutes(app *FiberApp, handlers *handlers.Handlers, metricsCollector metrics.MetricsCollector, authUseCase auth.AuthUseCase, auditUseCase audit.AuditUseCase, requireClientCert bool, logger *zap.Logger) {
 	// Add middleware
 	app.Use(middleware.Recovery(logger))
+	app.Use(middleware.RequestID())
 	app.Use(middleware.Logger(logger))
 	app.Use(middleware.MetricsMiddleware(metricsCollector, logger))
 
@@ -106,6 +115,7 @@ func SetupRoutes(app *FiberApp, handlers *handlers.Handlers, metricsCollector me
 
 	// Health routes with 5s timeout
 	app.Get("/health", middleware.TimeoutMiddleware(5*time.Second), handlers.Health.Status)
+	app.Get("/ready", middleware.TimeoutMiddleware(5*time.Second), handlers.Health.Ready)
 
 	// Metrics routes
 	app.Get("/metrics", handlers.Metrics.Handle)
@@ -113,9 +123,24 @@ func SetupRoutes(app *FiberApp, handlers *handlers.Handlers, metricsCollector me
 	// Version routes
 	app.Get("/version", handlers.Version.GetVersion)
 
+	// Embedded web dashboard; its data comes from /api/v1
+	app.Use(dashboard.Path, dashboard.Handler())
+
 	// Add API prefix for future endpoints
 	api := app.Group("/api/v1")
-	setupAPIRoutes(api, handlers)
+	if auditUseCase != nil {
+		// Registered first so that requests rejected by the checks below are recorded too
+		api.Use(middleware.Audit(auditUseCase, logger))
+	}
+	// The client certificate is checked per route rather than with Use, so that rejected requests are
+	// audited and counted with their route template
+	clientCert := func(c *fiber.Ctx) error {
+		return c.Next()
+	}
+	if requireClientCert {
+		clientCert = middleware.RequireClientCert(metricsCollector, logger)
+	}
+	setupAPIRoutes(api, handlers, clientCert, middleware.NewAuth(authUseCase, metricsCollector, logger))
 
 	// Add 404 handler
 	app.Use(func(c *fiber.Ctx) error {
@@ -127,7 +152,32 @@ func SetupRoutes(app *FiberApp, handlers *handlers.Handlers, metricsCollector me
 	})
 }
 
-func setupAPIRoutes(router fiber.Router, handlers *handlers.Handlers) {
-	// Future API endpoints will go here
-	router.Get("/cleanup", handlers.Cleanup.GetCleanupStatus)
+func setupAPIRoutes(router fiber.Router, handlers *handlers.Handlers, cert fiber.Handler, auth *middleware.Auth) {
+	// API description for client generators; must list every route below
+	router.Get("/openapi.json", cert, openAPIHandler(BuildOpenAPISpec(handlers.Version.Version())))
+
+	// Read-only endpoints
+	read := auth.Require(models.ScopeRead)
+	router.Get("/cleanup", cert, read, handlers.Cleanup.GetCleanupStatus)
+	router.Get("/results", cert, read, handlers.History.ListResults)
+	router.Get("/export", cert, read, handlers.History.Export)
+	router.Get("/schedules", cert, read, handlers.Schedule.ListSchedules)
+	router.Get("/images", cert, read, handlers.Cleanup.GetInventory)
+
+	// Mutating endpoints
+	router.Post("/cleanup", cert, auth.Require(models.ScopeTrigger), handlers.Cleanup.TriggerCleanup)
+
+	admin := auth.Require(models.ScopeAdmin)
+	router.Post("/admin/backup", cert, admin, handlers.Admin.Backup)
+	router.Get("/audit", cert, admin, handlers.Audit.ListEntries)
+
+	// Changing schedules can stop cleanup on the node, so it needs admin
+	router.Put("/schedules/:name", cert, admin, handlers.Schedule.Reschedule)
+	router.Post("/schedules/:name/pause", cert, admin, handlers.Schedule.Pause)
+	router.Post("/schedules/:name/resume", cert, admin, handlers.Schedule.Resume)
+
+	// Notifications waiting for another delivery attempt; retrying or discarding them needs admin
+	router.Get("/notifications/outbox", cert, read, handlers.Outbox.ListMessages)
+	router.Post("/notifications/outbox/:id/retry", cert, admin, handlers.Outbox.Retry)
+	router.Delete("/notifications/outbox/:id", cert, admin, handlers.Outbox.Discard)
 }
@@ -0,0 +1,464 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-image-cleanup/internal/domain/models"
+	prometheusMetrics "go-image-cleanup/internal/infrastructure/metrics"
+	"go-image-cleanup/internal/interfaces/http/handlers"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/internal/usecases/auth"
+	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/internal/usecases/schedule"
+	"go-image-cleanup/pkg/constants"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	routeParamPattern = regexp.MustCompile(`:(\w+)\??`)
+
+	// Prometheus metrics register in the default registry, so they can only be created once
+	testMetrics     *prometheusMetrics.PrometheusMetrics
+	testMetricsOnce sync.Once
+)
+
+// testDeps are the optional use cases wired into the test router; zero values leave auth and audit off
+type testDeps struct {
+	auth              auth.AuthUseCase
+	audit             audit.AuditUseCase
+	requireClientCert bool
+}
+
+func newTestApp(t *testing.T) *FiberApp {
+	return newTestAppWith(t, testDeps{})
+}
+
+func newTestAppWith(t *testing.T, deps testDeps) *FiberApp {
+	t.Helper()
+
+	logger := zap.NewNop()
+	testMetricsOnce.Do(func() {
+		testMetrics = prometheusMetrics.NewPrometheusMetrics(logger)
+	})
+	metricsCollector := testMetrics
+	h := handlers.NewHandlers(logger, "test", "now", metricsCollector, stubCleanupUseCase{}, nil, nil, nil, stubScheduleUseCase{}, deps.audit, nil)
+
+	app := NewFiberApp(logger)
+	SetupRoutes(app, h, metricsCollector, deps.auth, deps.audit, deps.requireClientCert, logger)
+	return app
+}
+
+// stubAuthUseCase accepts the tokens in keys
+type stubAuthUseCase struct {
+	auth.AuthUseCase
+	keys map[string]models.APIKey
+}
+
+func (s stubAuthUseCase) Authenticate(ctx context.Context, token string) (*models.APIKey, error) {
+	key, ok := s.keys[token]
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	return &key, nil
+}
+
+// testKeys has one key per scope, named after its token
+var testKeys = stubAuthUseCase{keys: map[string]models.APIKey{
+	"read-token":    {ID: "key-read", Name: "reader", Scopes: []models.Scope{models.ScopeRead}},
+	"trigger-token": {ID: "key-trigger", Name: "ci", Scopes: []models.Scope{models.ScopeTrigger}},
+	"admin-token":   {ID: "key-admin", Name: "ops", Scopes: []models.Scope{models.ScopeAdmin}},
+}}
+
+type stubCleanupUseCase struct {
+	cleanup.CleanupUseCase
+}
+
+func (stubCleanupUseCase) Cleanup(ctx context.Context) error { return nil }
+
+type stubScheduleUseCase struct {
+	schedule.ScheduleUseCase
+}
+
+// stubAuditUseCase keeps the recorded entries in memory
+type stubAuditUseCase struct {
+	audit.AuditUseCase
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+func (s *stubAuditUseCase) Record(ctx context.Context, entry models.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *stubAuditUseCase) recorded() []models.AuditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.AuditEntry(nil), s.entries...)
+}
+
+func (stubScheduleUseCase) ListSchedules(ctx context.Context) []schedule.ScheduleStatus {
+	return []schedule.ScheduleStatus{{Name: "cleanup", Expression: "0 2 * * *"}}
+}
+
+func (stubScheduleUseCase) Pause(ctx context.Context, name, actor string) (*schedule.ScheduleStatus, error) {
+	return &schedule.ScheduleStatus{Name: name, Paused: true, UpdatedBy: actor}, nil
+}
+
+// registeredRoutes returns "METHOD /path" for every route, with :param converted to {param}
+func registeredRoutes(app *FiberApp) map[string]bool {
+	routes := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Path == "/favicon.ico" {
+			continue
+		}
+		path := routeParamPattern.ReplaceAllString(route.Path, "{$1}")
+		routes[route.Method+" "+path] = true
+	}
+	return routes
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	app := newTestApp(t)
+	routes := registeredRoutes(app)
+	spec := BuildOpenAPISpec("test")
+
+	documented := make(map[string]bool)
+	for path, item := range spec.Paths {
+		for method := range *item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range routes {
+		if strings.Contains(route, " /api/v1") && !documented[route] {
+			t.Errorf("route %s is registered but missing from the OpenAPI spec", route)
+		}
+	}
+
+	for route := range documented {
+		if !routes[route] {
+			t.Errorf("route %s is in the OpenAPI spec but not registered", route)
+		}
+	}
+}
+
+func TestOpenAPISpecIsServed(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var doc struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("expected OpenAPI 3 document, got %q", doc.OpenAPI)
+	}
+
+	// Every $ref must point to a defined component schema
+	for _, match := range regexp.MustCompile(`"#/components/schemas/(\w+)"`).FindAllStringSubmatch(string(body), -1) {
+		if _, ok := doc.Components.Schemas[match[1]]; !ok {
+			t.Errorf("schema %s is referenced but not defined", match[1])
+		}
+	}
+}
+
+func TestDashboardIsServed(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, path := range []string{"/dashboard/", "/dashboard/app.js", "/dashboard/style.css"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, resp.StatusCode)
+		}
+		if resp.Header.Get("Content-Security-Policy") == "" {
+			t.Errorf("%s: expected a Content-Security-Policy header", path)
+		}
+	}
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name     string
+		incoming string
+		wantEcho bool
+	}{
+		{name: "generated when missing", incoming: "", wantEcho: false},
+		{name: "client value is kept", incoming: "deploy-1234", wantEcho: true},
+		{name: "invalid value is replaced", incoming: "has spaces in it", wantEcho: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/health", nil)
+			if tt.incoming != "" {
+				req.Header.Set(fiber.HeaderXRequestID, tt.incoming)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			got := resp.Header.Get(fiber.HeaderXRequestID)
+			if got == "" {
+				t.Fatal("expected an X-Request-ID response header")
+			}
+			if tt.wantEcho && got != tt.incoming {
+				t.Errorf("expected request ID %q to be echoed, got %q", tt.incoming, got)
+			}
+			if !tt.wantEcho && got == tt.incoming {
+				t.Errorf("expected a generated request ID, got the client value %q", got)
+			}
+		})
+	}
+}
+
+func TestHTTPLatencyUsesRouteTemplate(t *testing.T) {
+	app := newTestApp(t)
+
+	requests := map[string]string{
+		"/version":                  fiber.MethodGet,
+		"/api/v1/schedules/cleanup": fiber.MethodPut,
+		"/no/such/route":            fiber.MethodGet,
+	}
+	for path, method := range requests {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		if err != nil {
+			t.Fatalf("request %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	if err != nil {
+		t.Fatalf("metrics request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+
+	for _, want := range []string{
+		`path="/version"`,
+		`path="/api/v1/schedules/:name"`,
+		`path="/other"`,
+	} {
+		if !regexp.MustCompile(`image_cleanup_http_request_duration_seconds_count\{[^}]*` + regexp.QuoteMeta(want)).Match(body) {
+			t.Errorf("expected a latency series with %s", want)
+		}
+	}
+	if !regexp.MustCompile(`image_cleanup_http_request_duration_seconds_count\{[^}]*status="200"`).Match(body) {
+		t.Error("expected the latency histogram to label the response code as status")
+	}
+	if !strings.Contains(string(body), "image_cleanup_http_requests_in_flight") {
+		t.Error("expected the in-flight gauge to be exported")
+	}
+	if strings.Contains(string(body), `path="/api/v1/schedules/cleanup"`) {
+		t.Error("raw request paths must not be used as metric labels")
+	}
+}
+
+func TestAPIScopes(t *testing.T) {
+	app := newTestAppWith(t, testDeps{auth: testKeys})
+
+	routes := []struct {
+		method, path string
+		want         map[string]int // by token; "" sends no Authorization header
+	}{
+		{fiber.MethodGet, "/api/v1/schedules", map[string]int{
+			"": fiber.StatusUnauthorized, "bogus": fiber.StatusUnauthorized,
+			"read-token": fiber.StatusOK, "trigger-token": fiber.StatusForbidden, "admin-token": fiber.StatusOK,
+		}},
+		{fiber.MethodPost, "/api/v1/cleanup", map[string]int{
+			"": fiber.StatusUnauthorized, "bogus": fiber.StatusUnauthorized,
+			"read-token": fiber.StatusForbidden, "trigger-token": fiber.StatusAccepted, "admin-token": fiber.StatusAccepted,
+		}},
+		{fiber.MethodPost, "/api/v1/schedules/cleanup/pause", map[string]int{
+			"": fiber.StatusUnauthorized, "bogus": fiber.StatusUnauthorized,
+			"read-token": fiber.StatusForbidden, "trigger-token": fiber.StatusForbidden, "admin-token": fiber.StatusOK,
+		}},
+		{fiber.MethodPost, "/api/v1/admin/backup", map[string]int{
+			"": fiber.StatusUnauthorized, "read-token": fiber.StatusForbidden, "trigger-token": fiber.StatusForbidden,
+		}},
+	}
+
+	for _, route := range routes {
+		for token, want := range route.want {
+			t.Run(route.method+" "+route.path+" "+token, func(t *testing.T) {
+				req := httptest.NewRequest(route.method, route.path, nil)
+				if token != "" {
+					req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+				}
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				resp.Body.Close()
+
+				if resp.StatusCode != want {
+					t.Errorf("expected %d, got %d", want, resp.StatusCode)
+				}
+				if challenge := resp.Header.Get(fiber.HeaderWWWAuthenticate); (want == fiber.StatusUnauthorized) != (challenge != "") {
+					t.Errorf("expected a WWW-Authenticate challenge only on 401, got %q", challenge)
+				}
+			})
+		}
+	}
+
+	// Probes and metrics stay open
+	for _, path := range []string{"/version", "/metrics"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("%s: expected 200 without a key, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	auditLog := &stubAuditUseCase{}
+	app := newTestAppWith(t, testDeps{auth: testKeys, audit: auditLog})
+
+	body := strings.NewReader(strings.Repeat("x", constants.MaxRequestBodyBytes+1))
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/cleanup", body)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer admin-token")
+	// fasthttp rejects the body while reading the request: the test client sees the connection closed
+	// (a real client gets 413) and nothing reaches the routes or the audit log
+	resp, err := app.Test(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusRequestEntityTooLarge {
+			t.Errorf("expected %d for an oversized body, got %d", fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+		}
+	}
+	if entries := auditLog.recorded(); len(entries) != 0 {
+		t.Errorf("expected the oversized request not to be recorded, got %+v", entries)
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	tests := []struct {
+		name              string
+		requireClientCert bool
+		method, path      string
+		token             string
+		wantStatus        int
+		wantRecorded      bool
+		want              models.AuditEntry // Only the fields checked below
+	}{
+		{
+			name: "missing key", method: fiber.MethodPost, path: "/api/v1/cleanup",
+			wantStatus: fiber.StatusUnauthorized, wantRecorded: true,
+			want: models.AuditEntry{Route: "/api/v1/cleanup", Outcome: models.AuditOutcomeFailure, Actor: "ip:0.0.0.0"},
+		},
+		{
+			name: "insufficient scope", method: fiber.MethodPost, path: "/api/v1/schedules/cleanup/pause", token: "trigger-token",
+			wantStatus: fiber.StatusForbidden, wantRecorded: true,
+			want: models.AuditEntry{Route: "/api/v1/schedules/:name/pause", Outcome: models.AuditOutcomeFailure, Actor: "ip:0.0.0.0"},
+		},
+		{
+			name: "missing client certificate", requireClientCert: true, method: fiber.MethodPost, path: "/api/v1/cleanup", token: "admin-token",
+			wantStatus: fiber.StatusForbidden, wantRecorded: true,
+			want: models.AuditEntry{Route: "/api/v1/cleanup", Outcome: models.AuditOutcomeFailure, Actor: "ip:0.0.0.0"},
+		},
+		{
+			name: "authorized change", method: fiber.MethodPost, path: "/api/v1/schedules/cleanup/pause", token: "admin-token",
+			wantStatus: fiber.StatusOK, wantRecorded: true,
+			want: models.AuditEntry{Route: "/api/v1/schedules/:name/pause", Path: "/api/v1/schedules/cleanup/pause", Outcome: models.AuditOutcomeSuccess, Actor: "api_key:ops", APIKeyID: "key-admin",
+				Params: models.AuditParams{Path: map[string]string{"name": "cleanup"}, Body: `{"note":"audit"}`}},
+		},
+		{
+			name: "read", method: fiber.MethodGet, path: "/api/v1/schedules", token: "read-token",
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "rejected read", method: fiber.MethodGet, path: "/api/v1/schedules",
+			wantStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditLog := &stubAuditUseCase{}
+			app := newTestAppWith(t, testDeps{auth: testKeys, audit: auditLog, requireClientCert: tt.requireClientCert})
+
+			// Rejected requests keep only method, route, IP and status, whatever the caller sent
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"note":"audit"}`))
+			req.Header.Set(fiber.HeaderXRequestID, "audit-test-1")
+			if tt.token != "" {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			entries := auditLog.recorded()
+			if !tt.wantRecorded {
+				if len(entries) != 0 {
+					t.Errorf("expected %s not to be recorded, got %+v", tt.method, entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected one audit entry, got %+v", entries)
+			}
+
+			got := entries[0]
+			if got.RequestID != "audit-test-1" || got.Method != tt.method || got.Status != tt.wantStatus {
+				t.Errorf("unexpected request details %+v", got)
+			}
+			if got.Route != tt.want.Route || got.Path != tt.want.Path || got.Outcome != tt.want.Outcome || got.Actor != tt.want.Actor ||
+				got.APIKeyID != tt.want.APIKeyID || got.Error != tt.want.Error {
+				t.Errorf("expected route=%q path=%q outcome=%q actor=%q key=%q error=%q, got %+v",
+					tt.want.Route, tt.want.Path, tt.want.Outcome, tt.want.Actor, tt.want.APIKeyID, tt.want.Error, got)
+			}
+			if got.Params.Body != tt.want.Params.Body || got.Params.Path["name"] != tt.want.Params.Path["name"] || len(got.Params.Query) != 0 {
+				t.Errorf("expected params %+v, got %+v", tt.want.Params, got.Params)
+			}
+		})
+	}
+}
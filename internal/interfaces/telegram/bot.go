@@ -0,0 +1,316 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultAPIBase is the public Bot API server
+const DefaultAPIBase = "https://api.telegram.org"
+
+// BotConfig configures the command bot
+type BotConfig struct {
+	BotToken   string
+	APIBase    string  // Bot API server, defaults to DefaultAPIBase
+	AllowedIDs []int64 // Chats and users allowed to send commands
+	NodeName   string  // /history only lists the runs of this node
+}
+
+// Bot answers commands sent to the Telegram bot, using long polling of getUpdates
+type Bot struct {
+	config      BotConfig
+	allowed     map[int64]bool
+	cleanup     cleanup.CleanupUseCase
+	history     history.HistoryUseCase
+	audit       audit.AuditUseCase // Records state-changing commands, nil to skip
+	client      *http.Client
+	logger      *zap.Logger
+	username    string // Set by getMe; commands addressed to other bots are ignored
+	pollTimeout time.Duration
+	retryDelay  time.Duration
+	now         func() time.Time
+}
+
+func NewBot(config BotConfig, cleanupUseCase cleanup.CleanupUseCase, historyUseCase history.HistoryUseCase, auditUseCase audit.AuditUseCase, logger *zap.Logger) *Bot {
+	if config.APIBase == "" {
+		config.APIBase = DefaultAPIBase
+	}
+	allowed := make(map[int64]bool, len(config.AllowedIDs))
+	for _, id := range config.AllowedIDs {
+		allowed[id] = true
+	}
+	return &Bot{
+		config:  config,
+		allowed: allowed,
+		cleanup: cleanupUseCase,
+		history: historyUseCase,
+		audit:   auditUseCase,
+		// Long polling holds the request open for pollTimeout
+		client:      &http.Client{Timeout: constants.TelegramPollTimeout + constants.NotificationTimeout},
+		logger:      logger.With(zap.String("component", "telegram_bot")),
+		pollTimeout: constants.TelegramPollTimeout,
+		retryDelay:  constants.TelegramPollRetryDelay,
+		now:         time.Now,
+	}
+}
+
+// Bot API types, limited to the fields the bot uses
+type (
+	apiResponse struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+
+	update struct {
+		UpdateID int64    `json:"update_id"`
+		Message  *message `json:"message"`
+	}
+
+	message struct {
+		MessageID int64  `json:"message_id"`
+		ThreadID  int64  `json:"message_thread_id"`
+		From      *user  `json:"from"`
+		Chat      chat   `json:"chat"`
+		Date      int64  `json:"date"`
+		Text      string `json:"text"`
+	}
+
+	user struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	}
+
+	chat struct {
+		ID int64 `json:"id"`
+	}
+)
+
+// Run polls for commands until ctx is cancelled. Only one process may poll a bot token at a
+// time; Telegram answers the others with 409 Conflict, which is logged and retried.
+func (b *Bot) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		var me user
+		err := b.call(ctx, "getMe", nil, &me)
+		if err == nil {
+			b.username = me.Username
+			break
+		}
+		b.logger.Error("Failed to reach the Telegram Bot API", zap.Error(err))
+		b.sleep(ctx, b.retryDelay)
+	}
+	b.logger.Info("Telegram bot is listening for commands",
+		zap.String("username", b.username),
+		zap.Int("allowed_ids", len(b.allowed)))
+
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := b.getUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.logger.Error("Failed to get Telegram updates", zap.Error(err))
+				b.sleep(ctx, b.retryDelay)
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message != nil {
+				b.handleMessage(ctx, u.Message)
+			}
+		}
+	}
+}
+
+func (b *Bot) getUpdates(ctx context.Context, offset int64) ([]update, error) {
+	var updates []update
+	err := b.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(b.pollTimeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// handleMessage runs the command in msg when the sender is allowed and replies with its output
+func (b *Bot) handleMessage(ctx context.Context, msg *message) {
+	name, args, ok := b.parseCommand(msg.Text)
+	if !ok {
+		return
+	}
+
+	var userID int64
+	if msg.From != nil {
+		userID = msg.From.ID
+	}
+	// Like an API request, every command gets a request ID; /cleanup passes it on to the run
+	requestID := uuid.NewString()
+	ctx = helper.WithRequestID(ctx, requestID)
+	log := b.logger.With(
+		zap.String("command", name),
+		zap.Int64("chat_id", msg.Chat.ID),
+		zap.Int64("user_id", userID),
+		zap.String("request_id", requestID))
+	start := time.Now()
+
+	if !b.allowed[msg.Chat.ID] && !b.allowed[userID] {
+		log.Warn("Ignoring Telegram command from a chat and user that are not allowed")
+		b.auditCommand(ctx, msg, userID, name, http.StatusForbidden, "chat and user are not allowed", start, log)
+		return
+	}
+	// Commands queued while the service was stopped are stale; a late /cleanup would surprise
+	if age := b.now().Sub(time.Unix(msg.Date, 0)); age > constants.TelegramCommandMaxAge {
+		log.Warn("Ignoring stale Telegram command", zap.Duration("age", age))
+		b.auditCommand(ctx, msg, userID, name, http.StatusRequestTimeout, fmt.Sprintf("command is older than %s", constants.TelegramCommandMaxAge), start, log)
+		return
+	}
+
+	log.Info("Telegram command received", zap.Strings("args", args))
+	reply := b.execute(ctx, name, args, log)
+	b.auditCommand(ctx, msg, userID, name, http.StatusAccepted, "", start, log)
+	if err := b.reply(ctx, msg, reply); err != nil {
+		log.Error("Failed to reply to Telegram command", zap.Error(err))
+	}
+}
+
+// auditedCommands change state, so like non-GET API calls they are recorded in the audit log
+var auditedCommands = map[string]bool{"cleanup": true}
+
+// auditCommand records an audited command with the sender as actor and the chat in the params.
+// A failure to write the entry is logged and does not change the reply.
+func (b *Bot) auditCommand(ctx context.Context, msg *message, userID int64, name string, status int, errorMessage string, start time.Time, log *zap.Logger) {
+	if b.audit == nil || !auditedCommands[name] {
+		return
+	}
+
+	outcome := models.AuditOutcomeSuccess
+	if status >= http.StatusBadRequest {
+		outcome = models.AuditOutcomeFailure
+	}
+	entry := models.AuditEntry{
+		Time:       start.UTC(),
+		Actor:      "telegram:" + strconv.FormatInt(userID, 10),
+		RequestID:  helper.RequestIDFromContext(ctx),
+		Method:     models.AuditMethodTelegram,
+		Route:      "/" + name,
+		Path:       "/" + name,
+		Params:     models.AuditParams{Query: map[string]string{"chat_id": strconv.FormatInt(msg.Chat.ID, 10)}},
+		Status:     status,
+		Outcome:    outcome,
+		Error:      errorMessage,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	// The entry must be stored even when ctx was cancelled by a shutdown
+	auditCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), constants.NotificationTimeout)
+	defer cancel()
+	if err := b.audit.Record(auditCtx, entry); err != nil {
+		log.Error("Failed to record audit entry", zap.Error(err))
+	}
+}
+
+// parseCommand splits "/history@cleanup_bot 5" into "history" and ["5"]. Commands addressed
+// to another bot in a group are not ours.
+func (b *Bot) parseCommand(text string) (string, []string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+
+	name, target, addressed := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	if addressed && !strings.EqualFold(target, b.username) {
+		return "", nil, false
+	}
+	return strings.ToLower(name), fields[1:], name != ""
+}
+
+func (b *Bot) reply(ctx context.Context, msg *message, text string) error {
+	params := map[string]any{
+		"chat_id": msg.Chat.ID,
+		"text":    text,
+	}
+	if msg.ThreadID != 0 {
+		params["message_thread_id"] = msg.ThreadID
+	}
+	return b.call(ctx, "sendMessage", params, nil)
+}
+
+// call invokes a Bot API method with a JSON body and decodes its result into result
+func (b *Bot) call(ctx context.Context, method string, params map[string]any, result any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	apiURL := fmt.Sprintf("%s/bot%s/%s", b.config.APIBase, b.config.BotToken, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// The URL contains the bot token, so only the underlying error is reported
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if err := json.Unmarshal(raw, &apiResp); err != nil {
+		return fmt.Errorf("%s returned status %d with an invalid body: %w", method, resp.StatusCode, err)
+	}
+	if !apiResp.OK {
+		if apiResp.ErrorCode == http.StatusConflict {
+			return fmt.Errorf("%s returned 409 %s: another process polls this bot token or a webhook is set", method, apiResp.Description)
+		}
+		return fmt.Errorf("%s returned %d: %s", method, apiResp.ErrorCode, apiResp.Description)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(apiResp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+func (b *Bot) sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
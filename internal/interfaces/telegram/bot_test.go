@@ -0,0 +1,323 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/usecases/audit"
+	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/internal/usecases/history"
+	"go-image-cleanup/pkg/helper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// stubBotAPI serves getMe, hands out queued updates through getUpdates and records sendMessage calls
+type stubBotAPI struct {
+	mu      sync.Mutex
+	updates []update
+	sent    []map[string]any
+	offsets []int64
+}
+
+func newStubBotAPI(t *testing.T) (*stubBotAPI, *httptest.Server) {
+	stub := &stubBotAPI{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+
+		var result any
+		switch r.URL.Path {
+		case "/bot123:abc/getMe":
+			result = user{ID: 1, Username: "cleanup_bot"}
+		case "/bot123:abc/getUpdates":
+			stub.mu.Lock()
+			stub.offsets = append(stub.offsets, int64(params["offset"].(float64)))
+			result, stub.updates = stub.updates, nil
+			stub.mu.Unlock()
+			if result.([]update) == nil {
+				// Long polling with nothing to deliver
+				time.Sleep(10 * time.Millisecond)
+				result = []update{}
+			}
+		case "/bot123:abc/sendMessage":
+			stub.mu.Lock()
+			stub.sent = append(stub.sent, params)
+			stub.mu.Unlock()
+			result = map[string]any{}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+			return
+		}
+
+		raw, _ := json.Marshal(result)
+		json.NewEncoder(w).Encode(apiResponse{OK: true, Result: raw})
+	}))
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (s *stubBotAPI) push(updates ...update) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, updates...)
+}
+
+// waitForReplies waits until n replies were sent and returns them
+func (s *stubBotAPI) waitForReplies(t *testing.T, n int) []map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		sent := append([]map[string]any(nil), s.sent...)
+		s.mu.Unlock()
+		if len(sent) >= n {
+			return sent
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d replies, got %d", n, len(s.sent))
+	return nil
+}
+
+type mockCleanupUseCase struct {
+	started chan context.Context
+}
+
+func (m *mockCleanupUseCase) Cleanup(ctx context.Context) error {
+	m.started <- ctx
+	return nil
+}
+
+func (m *mockCleanupUseCase) Inventory(ctx context.Context) (*cleanup.Inventory, error) {
+	inventory := &cleanup.Inventory{
+		Host:             models.Host{NodeName: "node-1"},
+		TotalCount:       25,
+		RemoveCount:      23,
+		KeepCount:        2,
+		ReclaimableBytes: 23 << 20,
+	}
+	inventory.Images = append(inventory.Images,
+		cleanup.ImageDecision{ID: "sha256:aaa", Tags: []string{"nginx:1.25"}, SizeBytes: 5 << 20, InUse: true, Action: cleanup.ActionKeep},
+		cleanup.ImageDecision{ID: "sha256:0123456789abcdef", SizeBytes: 1 << 20, Action: cleanup.ActionRemove},
+		cleanup.ImageDecision{ID: "sha256:bbb", Tags: []string{"redis:7"}, SizeBytes: 5 << 20, InUse: true, Action: cleanup.ActionKeep},
+	)
+	for i := 0; i < 22; i++ {
+		inventory.Images = append(inventory.Images, cleanup.ImageDecision{
+			ID: fmt.Sprintf("sha256:%d", i), Tags: []string{fmt.Sprintf("app:v%d", i)}, SizeBytes: 1 << 20, Action: cleanup.ActionRemove,
+		})
+	}
+	return inventory, nil
+}
+
+func (m *mockCleanupUseCase) GetLastCleanupStats() (*cleanup.CleanupStats, error) {
+	return &cleanup.CleanupStats{
+		Host:       models.Host{NodeName: "node-1"},
+		StartTime:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Duration:   90 * time.Second,
+		TotalCount: 10,
+		Removed:    3,
+		Skipped:    7,
+	}, nil
+}
+
+type mockHistoryUseCase struct {
+	history.HistoryUseCase
+	query repositories.ResultQuery
+	limit int
+}
+
+func (m *mockHistoryUseCase) ListResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
+	m.query, m.limit = query, limit
+	return []repositories.CleanupResult{
+		{StartTime: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Duration: time.Minute, TotalCount: 4, Removed: 2},
+		{StartTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Duration: time.Minute, TotalCount: 4, Removed: 0},
+	}, nil
+}
+
+// mockAuditUseCase records audit entries written by the bot goroutine
+type mockAuditUseCase struct {
+	audit.AuditUseCase
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+func (m *mockAuditUseCase) Record(ctx context.Context, entry models.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *mockAuditUseCase) recorded() []models.AuditEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.AuditEntry(nil), m.entries...)
+}
+
+func TestBotCommands(t *testing.T) {
+	stub, server := newStubBotAPI(t)
+	cleanupUseCase := &mockCleanupUseCase{started: make(chan context.Context, 1)}
+	historyUseCase := &mockHistoryUseCase{}
+	auditUseCase := &mockAuditUseCase{}
+
+	bot := NewBot(BotConfig{BotToken: "123:abc", APIBase: server.URL, AllowedIDs: []int64{-100, 42}, NodeName: "node-1"},
+		cleanupUseCase, historyUseCase, auditUseCase, zap.NewNop())
+	now := time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	command := func(id int64, chatID, userID int64, text string) update {
+		return update{UpdateID: id, Message: &message{
+			MessageID: id, Chat: chat{ID: chatID}, From: &user{ID: userID}, Date: now.Unix(), Text: text,
+		}}
+	}
+	stale := command(6, -100, 7, "/cleanup")
+	stale.Message.Date = now.Add(-time.Hour).Unix()
+	topic := command(10, -100, 7, "/history@cleanup_bot 3")
+	topic.Message.ThreadID = 15
+
+	stub.push(
+		command(1, -100, 7, "/status"),
+		command(2, 555, 42, "/dryrun"),           // allowed user in another chat
+		command(3, 555, 7, "/status"),            // neither chat nor user allowed
+		command(4, -100, 7, "hello"),             // not a command
+		command(5, -100, 7, "/status@other_bot"), // addressed to another bot
+		stale,
+		command(7, -100, 7, "/images"),
+		command(8, -100, 7, "/history 50"),
+		command(9, -100, 7, "/cleanup"),
+		topic,
+		command(11, 555, 8, "/cleanup"), // neither chat nor user allowed
+	)
+	sent := stub.waitForReplies(t, 6)
+
+	texts := make([]string, len(sent))
+	for i, params := range sent {
+		texts[i] = params["text"].(string)
+	}
+
+	if !strings.Contains(texts[0], "Last cleanup on node-1") || !strings.Contains(texts[0], "3 removed, 7 skipped") ||
+		!strings.Contains(texts[0], "1m30s") || sent[0]["chat_id"] != float64(-100) {
+		t.Errorf("unexpected /status reply: %v", sent[0])
+	}
+
+	if sent[1]["chat_id"] != float64(555) || !strings.HasPrefix(texts[1], "🔍 Dry run on node-1: 23 of 25 images would be removed") {
+		t.Errorf("unexpected /dryrun reply: %v", sent[1])
+	}
+	if strings.Contains(texts[1], "nginx") || !strings.Contains(texts[1], "🗑 0123456789ab 1.0 MiB") ||
+		!strings.HasSuffix(texts[1], "… and 3 more") {
+		t.Errorf("expected only removals, capped at %d, got %q", maxListedImages, texts[1])
+	}
+
+	if !strings.Contains(texts[2], "✅ nginx:1.25") || !strings.HasSuffix(texts[2], "… and 5 more") {
+		t.Errorf("unexpected /images reply: %q", texts[2])
+	}
+
+	if !strings.HasPrefix(texts[3], "Usage: /history") {
+		t.Errorf("expected usage for an out of range count, got %q", texts[3])
+	}
+
+	var runRequestID string
+	select {
+	case runCtx := <-cleanupUseCase.started:
+		runRequestID = helper.RequestIDFromContext(runCtx)
+		if runRequestID == "" || !strings.Contains(texts[4], runRequestID) {
+			t.Errorf("expected the reply to carry the run's request ID %q, got %q", runRequestID, texts[4])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected /cleanup to start a run")
+	}
+
+	if sent[5]["message_thread_id"] != float64(15) || !strings.Contains(texts[5], "Last 2 cleanups") {
+		t.Errorf("expected the history reply in the topic, got %v", sent[5])
+	}
+	if historyUseCase.query.NodeName != "node-1" || historyUseCase.limit != 3 {
+		t.Errorf("expected the history of this node to be queried, got %+v limit %d", historyUseCase.query, historyUseCase.limit)
+	}
+
+	// Ignored messages get no reply, and the stale /cleanup did not start a second run
+	time.Sleep(50 * time.Millisecond)
+	if got := len(stub.waitForReplies(t, 6)); got != 6 {
+		t.Errorf("expected 6 replies, got %d", got)
+	}
+	select {
+	case <-cleanupUseCase.started:
+		t.Error("expected the stale /cleanup to be ignored")
+	default:
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if last := stub.offsets[len(stub.offsets)-1]; last != 12 {
+		t.Errorf("expected handled updates to be confirmed with offset 12, got %d", last)
+	}
+
+	// Every /cleanup is audited, including the ignored ones; read-only commands are not
+	entries := auditUseCase.recorded()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 audited /cleanup commands, got %+v", entries)
+	}
+	want := []struct {
+		actor, chatID string
+		status        int
+		outcome       string
+	}{
+		{"telegram:7", "-100", http.StatusRequestTimeout, models.AuditOutcomeFailure},
+		{"telegram:7", "-100", http.StatusAccepted, models.AuditOutcomeSuccess},
+		{"telegram:8", "555", http.StatusForbidden, models.AuditOutcomeFailure},
+	}
+	for i, w := range want {
+		entry := entries[i]
+		if entry.Actor != w.actor || entry.Params.Query["chat_id"] != w.chatID || entry.Status != w.status || entry.Outcome != w.outcome ||
+			entry.Method != models.AuditMethodTelegram || entry.Route != "/cleanup" || entry.RequestID == "" {
+			t.Errorf("unexpected audit entry %d: %+v", i, entry)
+		}
+		if (w.outcome == models.AuditOutcomeFailure) == (entry.Error == "") {
+			t.Errorf("expected an error only on failures, got %+v", entry)
+		}
+	}
+	if entries[1].RequestID != runRequestID {
+		t.Errorf("expected the audit entry to carry the run's request ID %q, got %q", runRequestID, entries[1].RequestID)
+	}
+}
+
+func TestBotCallErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"ok":false,"error_code":409,"description":"Conflict: terminated by other getUpdates request"}`)
+	}))
+	defer server.Close()
+
+	bot := NewBot(BotConfig{BotToken: "123:abc", APIBase: server.URL}, nil, nil, nil, zap.NewNop())
+	_, err := bot.getUpdates(context.Background(), 0)
+	if err == nil || !strings.Contains(err.Error(), "another process polls this bot token") {
+		t.Errorf("expected a hint for the conflict, got %v", err)
+	}
+
+	bot = NewBot(BotConfig{BotToken: "123:secret", APIBase: "http://127.0.0.1:1"}, nil, nil, nil, zap.NewNop())
+	if err = bot.call(context.Background(), "getMe", nil, nil); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected a network error without the bot token, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/internal/usecases/cleanup"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultHistoryCount = 5
+	maxHistoryCount     = 20
+
+	// maxListedImages keeps image lists well below Telegram's 4096 character limit
+	maxListedImages = 20
+)
+
+const helpText = `Commands:
+/status - last cleanup run
+/history [N] - last N runs on this node (default 5, max 20)
+/images - images on this node and what cleanup would do with them
+/dryrun - images the next cleanup would remove
+/cleanup - start a cleanup run now`
+
+// execute runs a command and returns the reply
+func (b *Bot) execute(ctx context.Context, name string, args []string, log *zap.Logger) string {
+	switch name {
+	case "start", "help":
+		return helpText
+	case "status":
+		return b.status()
+	case "history":
+		return b.historyReply(ctx, args, log)
+	case "images":
+		return b.images(ctx, false, log)
+	case "dryrun":
+		return b.images(ctx, true, log)
+	case "cleanup":
+		return b.startCleanup(ctx, log)
+	}
+	return fmt.Sprintf("Unknown command /%s\n\n%s", name, helpText)
+}
+
+func (b *Bot) status() string {
+	stats, err := b.cleanup.GetLastCleanupStats()
+	if err != nil {
+		return "Failed to read the last cleanup: " + err.Error()
+	}
+	if stats.StartTime.IsZero() {
+		return fmt.Sprintf("No cleanup has run on %s yet", hostName(stats.Host))
+	}
+	return fmt.Sprintf("📊 Last cleanup on %s\nStarted: %s\nDuration: %s\nImages: %d total, %d removed, %d skipped",
+		hostName(stats.Host), helper.FormatICT(stats.StartTime), stats.Duration.Round(time.Second),
+		stats.TotalCount, stats.Removed, stats.Skipped)
+}
+
+func (b *Bot) historyReply(ctx context.Context, args []string, log *zap.Logger) string {
+	count := defaultHistoryCount
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > maxHistoryCount {
+			return fmt.Sprintf("Usage: /history [N], N between 1 and %d", maxHistoryCount)
+		}
+		count = n
+	}
+
+	results, err := b.history.ListResults(ctx, repositories.ResultQuery{NodeName: b.config.NodeName}, count, 0)
+	if err != nil {
+		log.Error("Failed to list cleanup results", zap.Error(err))
+		return "Failed to read the cleanup history: " + err.Error()
+	}
+	if len(results) == 0 {
+		return "No cleanup runs recorded yet"
+	}
+
+	lines := []string{fmt.Sprintf("🕘 Last %d cleanups", len(results))}
+	for _, r := range results {
+		lines = append(lines, fmt.Sprintf("%s: %d/%d removed in %s",
+			helper.FormatICT(r.StartTime), r.Removed, r.TotalCount, r.Duration.Round(time.Second)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// images lists the inventory; removalsOnly lists only the images the next run would remove
+func (b *Bot) images(ctx context.Context, removalsOnly bool, log *zap.Logger) string {
+	inventory, err := b.cleanup.Inventory(ctx)
+	if err != nil {
+		log.Error("Failed to collect image inventory", zap.Error(err))
+		return "Failed to list images: " + err.Error()
+	}
+
+	var lines []string
+	if removalsOnly {
+		lines = append(lines, fmt.Sprintf("🔍 Dry run on %s: %d of %d images would be removed, freeing %s",
+			hostName(inventory.Host), inventory.RemoveCount, inventory.TotalCount, helper.FormatBytes(inventory.ReclaimableBytes)))
+	} else {
+		lines = append(lines, fmt.Sprintf("🖼 %d images on %s: %d to remove (%s), %d kept",
+			inventory.TotalCount, hostName(inventory.Host), inventory.RemoveCount,
+			helper.FormatBytes(inventory.ReclaimableBytes), inventory.KeepCount))
+	}
+
+	listed := 0
+	for _, image := range inventory.Images {
+		if removalsOnly && image.Action != cleanup.ActionRemove {
+			continue
+		}
+		if listed == maxListedImages {
+			total := inventory.TotalCount
+			if removalsOnly {
+				total = inventory.RemoveCount
+			}
+			lines = append(lines, fmt.Sprintf("… and %d more", total-listed))
+			break
+		}
+		listed++
+
+		icon := "✅"
+		if image.Action == cleanup.ActionRemove {
+			icon = "🗑"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s", icon, imageName(image), helper.FormatBytes(image.SizeBytes)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// startCleanup starts a run in the background, like POST /api/v1/cleanup; the result arrives
+// through the notification channels. The run carries the request ID of the command.
+func (b *Bot) startCleanup(ctx context.Context, log *zap.Logger) string {
+	requestID := helper.RequestIDFromContext(ctx)
+	runCtx, cancel := context.WithTimeout(ctx, constants.CleanupTimeout)
+
+	go func() {
+		defer cancel()
+		if err := b.cleanup.Cleanup(runCtx); err != nil {
+			log.Error("Telegram-triggered cleanup failed", zap.Error(err))
+		}
+	}()
+
+	log.Info("Cleanup triggered from Telegram")
+	return "🧹 Cleanup started\nRequest ID: " + requestID
+}
+
+func hostName(host models.Host) string {
+	if host.NodeName != "" {
+		return host.NodeName
+	}
+	return host.Hostname
+}
+
+// imageName returns the first tag, or the short ID of an untagged image
+func imageName(image cleanup.ImageDecision) string {
+	if len(image.Tags) > 0 {
+		return image.Tags[0]
+	}
+	id := strings.TrimPrefix(image.ID, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
@@ -0,0 +1,17 @@
+package alerting
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/notification"
+)
+
+type AlertingUseCase interface {
+	// Notify cập nhật alert theo kết quả của lần cleanup (run_completed, run_failed) và gửi tới Alertmanager
+	notification.EventNotifier
+
+	// Run kiểm tra lần chạy thành công gần nhất và gửi lại các alert đang firing cho tới khi ctx bị hủy
+	Run(ctx context.Context)
+
+	// Active trả về các alert đang firing, sắp xếp theo alertname
+	Active() []notification.Alert
+}
@@ -0,0 +1,287 @@
+// internal/usecases/alerting/service.go
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/health"
+	"go-image-cleanup/internal/domain/host"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/pkg/constants"
+	"go-image-cleanup/pkg/helper"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Verify that AlertingService implements AlertingUseCase
+var _ AlertingUseCase = (*AlertingService)(nil)
+
+// Tên các alert, là label alertname
+const (
+	AlertRunFailed       = "ImageCleanupRunFailed"
+	AlertRemovalFailures = "ImageCleanupRemovalFailures"
+	AlertNoRecentSuccess = "ImageCleanupNoRecentSuccess"
+)
+
+// Giá trị label severity
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
+// AlertingService giữ trạng thái các alert của node và đồng bộ chúng với Alertmanager.
+// Alert được bật khi lần chạy lỗi, khi số image xóa lỗi vượt ngưỡng, hoặc khi quá lâu không có lần
+// chạy thành công; mọi alert được resolve ở lần chạy thành công tiếp theo.
+// Trạng thái chỉ nằm trong bộ nhớ: sau khi restart, alert lỗi cũ hết hạn theo endsAt,
+// còn thời điểm chạy thành công gần nhất được đọc lại từ lịch sử cleanup.
+type AlertingService struct {
+	sender           notification.AlertSender
+	repo             repositories.CleanupResultRepository
+	hosts            host.Identifier
+	failureThreshold int                  // Số image xóa lỗi tối đa trong một lần chạy mà không bật alert
+	window           health.RunWindowFunc // Khoảng thời gian tối đa không có lần chạy thành công, theo lịch hiện tại
+	resendInterval   time.Duration
+	ttl              time.Duration
+	logger           *zap.Logger
+	now              func() time.Time
+
+	// mu giữ cả lúc gửi, để lần gửi cũ không đến Alertmanager sau lần resolve mới hơn
+	mu          sync.Mutex
+	host        models.Host
+	firing      map[string]notification.Alert // Theo alertname
+	resolved    []notification.Alert          // Đã resolve nhưng chưa gửi được
+	lastSuccess time.Time
+}
+
+func NewAlertingService(
+	sender notification.AlertSender,
+	repo repositories.CleanupResultRepository,
+	hosts host.Identifier,
+	failureThreshold int,
+	window health.RunWindowFunc,
+	logger *zap.Logger,
+) *AlertingService {
+	return &AlertingService{
+		sender:           sender,
+		repo:             repo,
+		hosts:            hosts,
+		failureThreshold: failureThreshold,
+		window:           window,
+		resendInterval:   constants.AlertmanagerResendInterval,
+		ttl:              constants.AlertmanagerAlertTTL,
+		logger:           logger.With(zap.String("component", "alerting")),
+		now:              time.Now,
+		firing:           make(map[string]notification.Alert),
+	}
+}
+
+// Notify cập nhật alert theo sự kiện. Lỗi gửi chỉ được ghi log và không trả về, vì Run gửi lại
+// trạng thái hiện tại ở vòng sau; gửi lại sự kiện cũ từ outbox có thể bật lại alert đã resolve.
+func (s *AlertingService) Notify(ctx context.Context, event notification.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch e := event.(type) {
+	case notification.RunFailed:
+		s.fire(e.Host, AlertRunFailed, SeverityCritical,
+			fmt.Sprintf("Image cleanup failed on %s", hostName(e.Host)),
+			e.Error, e.RequestID)
+	case notification.Report:
+		if e.Failed > s.failureThreshold {
+			s.fire(e.Host, AlertRemovalFailures, SeverityWarning,
+				fmt.Sprintf("%d images could not be removed on %s", e.Failed, hostName(e.Host)),
+				fmt.Sprintf("%d of %d images failed to be removed (threshold %d), %d removed",
+					e.Failed, e.Total, s.failureThreshold, e.Removed),
+				e.RequestID)
+		} else {
+			s.resolveAll(e.Host, e.EndTime)
+		}
+	default:
+		return nil
+	}
+
+	s.send(ctx)
+	return nil
+}
+
+// Run đọc lần chạy gần nhất từ lịch sử, sau đó định kỳ kiểm tra và gửi lại alert cho tới khi ctx bị hủy
+func (s *AlertingService) Run(ctx context.Context) {
+	s.loadLastSuccess(ctx)
+
+	ticker := time.NewTicker(s.resendInterval)
+	defer ticker.Stop()
+	for {
+		s.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check bật alert khi quá lâu không có lần chạy thành công theo lịch hiện tại và gửi lại các alert đang firing.
+// Khi cleanup bị tạm dừng thì không có lần chạy nào được chờ, alert đang bật được resolve.
+func (s *AlertingService) Check(ctx context.Context) {
+	window := s.window()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	switch {
+	case window.Paused:
+		s.resolve(AlertNoRecentSuccess)
+	case window.MaxAge > 0 && !s.lastSuccess.IsZero() && now.After(window.Deadline(s.lastSuccess)):
+		s.fire(s.host, AlertNoRecentSuccess, SeverityCritical,
+			fmt.Sprintf("No successful image cleanup on %s for %s", hostName(s.host), now.Sub(s.lastSuccess).Round(time.Minute)),
+			fmt.Sprintf("The last successful cleanup ended at %s; expected one at least every %s",
+				helper.FormatICT(s.lastSuccess), window.MaxAge),
+			"")
+	}
+	s.send(ctx)
+}
+
+func (s *AlertingService) Active() []notification.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := slices.Sorted(maps.Keys(s.firing))
+	alerts := make([]notification.Alert, len(names))
+	for i, name := range names {
+		alerts[i] = s.firing[name]
+	}
+	return alerts
+}
+
+// loadLastSuccess lấy thời điểm kết thúc của lần chạy gần nhất của node làm mốc cho AlertNoRecentSuccess.
+// Lịch sử không phân biệt lần chạy có image xóa lỗi; không có lịch sử thì tính từ lúc service khởi động.
+func (s *AlertingService) loadLastSuccess(ctx context.Context) {
+	h, err := s.hosts.Identify(ctx)
+	if err != nil {
+		s.logger.Warn("Host identity is incomplete", zap.Error(err))
+	}
+
+	lastSuccess := s.now()
+	results, err := s.repo.GetResults(ctx, repositories.ResultQuery{NodeName: h.NodeName}, 1, 0)
+	if err != nil {
+		s.logger.Error("Failed to read the last cleanup result", zap.Error(err))
+	} else if len(results) > 0 {
+		lastSuccess = results[0].EndTime
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.host.NodeName == "" && s.host.Hostname == "" {
+		s.host = h
+	}
+	// Một lần chạy có thể đã kết thúc trong lúc đọc lịch sử
+	if lastSuccess.After(s.lastSuccess) {
+		s.lastSuccess = lastSuccess
+	}
+}
+
+// fire bật alert hoặc cập nhật nội dung của alert đang firing, giữ nguyên startsAt
+func (s *AlertingService) fire(h models.Host, name, severity, summary, description, requestID string) {
+	s.host = h
+	now := s.now()
+
+	annotations := map[string]string{
+		"summary":     summary,
+		"description": description,
+	}
+	if requestID != "" {
+		annotations["request_id"] = requestID
+	}
+
+	alert, ok := s.firing[name]
+	if !ok {
+		alert = notification.Alert{Labels: alertLabels(h, name, severity), StartsAt: now}
+		s.logger.Warn("Alert firing", zap.String("alert", name), zap.String("summary", summary))
+	}
+	alert.Annotations = annotations
+	s.firing[name] = alert
+}
+
+// resolveAll ghi nhận lần chạy thành công và resolve mọi alert đang firing
+func (s *AlertingService) resolveAll(h models.Host, endTime time.Time) {
+	s.host = h
+	if endTime.After(s.lastSuccess) {
+		s.lastSuccess = endTime
+	}
+
+	for name := range s.firing {
+		s.resolve(name)
+	}
+}
+
+// resolve chuyển alert đang firing sang danh sách chờ gửi resolve; mu phải đang được giữ
+func (s *AlertingService) resolve(name string) {
+	alert, ok := s.firing[name]
+	if !ok {
+		return
+	}
+	alert.EndsAt = s.now()
+	s.resolved = append(s.resolved, alert)
+	delete(s.firing, name)
+	s.logger.Info("Alert resolved", zap.String("alert", name))
+}
+
+// send gửi các alert đang firing với endsAt mới cùng các alert vừa resolve; mu phải đang được giữ
+func (s *AlertingService) send(ctx context.Context) {
+	alerts := append([]notification.Alert(nil), s.resolved...)
+	endsAt := s.now().Add(s.ttl)
+	for _, alert := range s.firing {
+		alert.EndsAt = endsAt
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	if err := s.sender.SendAlerts(ctx, alerts); err != nil {
+		s.logger.Error("Failed to send alerts to Alertmanager, will retry",
+			zap.Int("alerts", len(alerts)),
+			zap.Error(err))
+		return
+	}
+	s.resolved = nil
+}
+
+// alertLabels tạo label nhận diện alert; label của host không ghi đè label có sẵn
+func alertLabels(h models.Host, name, severity string) map[string]string {
+	labels := map[string]string{
+		"alertname": name,
+		"severity":  severity,
+		"service":   constants.ServiceName,
+	}
+	if h.NodeName != "" {
+		labels["node"] = h.NodeName
+	}
+	if h.Hostname != "" {
+		labels["hostname"] = h.Hostname
+	}
+	for key, value := range h.Labels {
+		if _, ok := labels[key]; !ok {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+func hostName(h models.Host) string {
+	if h.NodeName != "" {
+		return h.NodeName
+	}
+	if h.Hostname != "" {
+		return h.Hostname
+	}
+	return "unknown host"
+}
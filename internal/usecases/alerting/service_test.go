@@ -0,0 +1,230 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/health"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// mockResultRepository chỉ cần GetResults để đọc lần chạy gần nhất
+type mockResultRepository struct {
+	repositories.CleanupResultRepository
+	results []repositories.CleanupResult
+	query   repositories.ResultQuery
+}
+
+func (m *mockResultRepository) GetResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
+	m.query = query
+	return m.results, nil
+}
+
+// mockAlertSender ghi lại các lần gửi; err khác nil làm lần gửi thất bại
+type mockAlertSender struct {
+	sent [][]notification.Alert
+	err  error
+}
+
+func (m *mockAlertSender) SendAlerts(ctx context.Context, alerts []notification.Alert) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, alerts)
+	return nil
+}
+
+// last trả về lần gửi gần nhất theo alertname
+func (m *mockAlertSender) last() map[string]notification.Alert {
+	alerts := make(map[string]notification.Alert)
+	if len(m.sent) == 0 {
+		return alerts
+	}
+	for _, alert := range m.sent[len(m.sent)-1] {
+		alerts[alert.Labels["alertname"]] = alert
+	}
+	return alerts
+}
+
+type mockHostIdentifier struct{}
+
+func (mockHostIdentifier) Identify(ctx context.Context) (models.Host, error) {
+	return testHost, nil
+}
+
+var testHost = models.Host{Hostname: "host-1", NodeName: "node-1", Labels: map[string]string{"env": "prod", "severity": "low"}}
+
+func newTestService(repo *mockResultRepository, sender *mockAlertSender, now *time.Time) *AlertingService {
+	return newTestServiceWithWindow(repo, sender, now, func() health.RunWindow { return health.RunWindow{MaxAge: 48 * time.Hour} })
+}
+
+func newTestServiceWithWindow(repo *mockResultRepository, sender *mockAlertSender, now *time.Time, window health.RunWindowFunc) *AlertingService {
+	service := NewAlertingService(sender, repo, mockHostIdentifier{}, 2, window, zap.NewNop())
+	service.now = func() time.Time { return *now }
+	return service
+}
+
+func TestAlertingServiceFiresAndResolves(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sender := &mockAlertSender{}
+	service := newTestService(&mockResultRepository{}, sender, &now)
+	ctx := context.Background()
+
+	service.Notify(ctx, notification.RunFailed{Host: testHost, Error: "crictl not found", RequestID: "req-1"})
+	alert, ok := sender.last()[AlertRunFailed]
+	if !ok {
+		t.Fatalf("expected the run failed alert to be sent, got %v", sender.sent)
+	}
+	wantLabels := map[string]string{"alertname": AlertRunFailed, "severity": SeverityCritical, "service": "image-cleanup",
+		"node": "node-1", "hostname": "host-1", "env": "prod"}
+	for key, value := range wantLabels {
+		if alert.Labels[key] != value {
+			t.Errorf("expected label %s=%q, got %v", key, value, alert.Labels)
+		}
+	}
+	if alert.Annotations["description"] != "crictl not found" || alert.Annotations["request_id"] != "req-1" {
+		t.Errorf("unexpected annotations %v", alert.Annotations)
+	}
+	if !alert.StartsAt.Equal(now) || !alert.EndsAt.Equal(now.Add(5*time.Minute)) {
+		t.Errorf("expected the alert to start now and expire after the TTL, got %v - %v", alert.StartsAt, alert.EndsAt)
+	}
+
+	// Vượt ngưỡng 2 image xóa lỗi thì bật thêm alert
+	now = now.Add(time.Hour)
+	service.Notify(ctx, notification.Report{Host: testHost, EndTime: now, Total: 10, Failed: 3, Removed: 7})
+	last := sender.last()
+	if _, ok := last[AlertRemovalFailures]; !ok || len(last) != 2 {
+		t.Fatalf("expected both alerts to be firing, got %v", last)
+	}
+	if !last[AlertRunFailed].StartsAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected startsAt to be kept when resending, got %v", last[AlertRunFailed].StartsAt)
+	}
+
+	// Số image xóa lỗi bằng ngưỡng vẫn là lần chạy thành công, resolve mọi alert
+	now = now.Add(time.Hour)
+	service.Notify(ctx, notification.Report{Host: testHost, EndTime: now, Total: 10, Failed: 2, Removed: 8})
+	last = sender.last()
+	if len(last) != 2 || !last[AlertRunFailed].EndsAt.Equal(now) || !last[AlertRemovalFailures].EndsAt.Equal(now) {
+		t.Errorf("expected both alerts to be resolved now, got %v", last)
+	}
+	if active := service.Active(); len(active) != 0 {
+		t.Errorf("expected no active alert, got %v", active)
+	}
+
+	// Không còn gì để gửi
+	sent := len(sender.sent)
+	service.Check(ctx)
+	service.Notify(ctx, notification.RunStarted{Host: testHost})
+	if len(sender.sent) != sent {
+		t.Errorf("expected nothing to be sent, got %v", sender.sent[sent:])
+	}
+}
+
+func TestAlertingServiceRetriesResolvedAlerts(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sender := &mockAlertSender{}
+	service := newTestService(&mockResultRepository{}, sender, &now)
+	ctx := context.Background()
+
+	service.Notify(ctx, notification.RunFailed{Host: testHost, Error: "boom"})
+
+	sender.err = errors.New("connection refused")
+	if err := service.Notify(ctx, notification.Report{Host: testHost, EndTime: now}); err != nil {
+		t.Errorf("expected delivery errors not to be returned, got %v", err)
+	}
+
+	sender.err = nil
+	now = now.Add(time.Minute)
+	service.Check(ctx)
+	if alert, ok := sender.last()[AlertRunFailed]; !ok || alert.EndsAt.After(now) {
+		t.Fatalf("expected the resolved alert to be sent on the next check, got %v", sender.sent)
+	}
+
+	sent := len(sender.sent)
+	service.Check(ctx)
+	if len(sender.sent) != sent {
+		t.Error("expected the resolved alert to be sent only once")
+	}
+}
+
+func TestAlertingServiceNoRecentSuccess(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := &mockResultRepository{results: []repositories.CleanupResult{{EndTime: now.Add(-47 * time.Hour)}}}
+	sender := &mockAlertSender{}
+	service := newTestService(repo, sender, &now)
+	ctx := context.Background()
+
+	service.loadLastSuccess(ctx)
+	if repo.query.NodeName != "node-1" {
+		t.Errorf("expected the history of this node to be read, got %+v", repo.query)
+	}
+
+	service.Check(ctx)
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no alert within the window, got %v", sender.sent)
+	}
+
+	now = now.Add(2 * time.Hour)
+	service.Check(ctx)
+	alert, ok := sender.last()[AlertNoRecentSuccess]
+	if !ok || alert.Labels["node"] != "node-1" || alert.Labels["severity"] != SeverityCritical {
+		t.Fatalf("expected the no recent success alert, got %v", sender.sent)
+	}
+
+	now = now.Add(time.Hour)
+	service.Notify(ctx, notification.Report{Host: testHost, EndTime: now})
+	if alert := sender.last()[AlertNoRecentSuccess]; !alert.EndsAt.Equal(now) {
+		t.Errorf("expected the alert to be resolved by the successful run, got %v", alert)
+	}
+
+	now = now.Add(time.Hour)
+	service.Check(ctx)
+	if active := service.Active(); len(active) != 0 {
+		t.Errorf("expected the window to restart after the successful run, got %v", active)
+	}
+}
+
+func TestAlertingServiceFollowsSchedule(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := &mockResultRepository{results: []repositories.CleanupResult{{EndTime: now.Add(-72 * time.Hour)}}}
+	sender := &mockAlertSender{}
+	window := health.RunWindow{MaxAge: 48 * time.Hour, Paused: true}
+	service := newTestServiceWithWindow(repo, sender, &now, func() health.RunWindow { return window })
+	ctx := context.Background()
+	service.loadLastSuccess(ctx)
+
+	// Cleanup tạm dừng thì không chờ lần chạy nào
+	service.Check(ctx)
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no alert while cleanup is paused, got %v", sender.sent)
+	}
+
+	// Vừa chạy lại: tính từ lúc đổi lịch chứ không từ lần chạy cũ
+	window = health.RunWindow{MaxAge: 48 * time.Hour, Since: now}
+	now = now.Add(47 * time.Hour)
+	service.Check(ctx)
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no alert within the window after resuming, got %v", sender.sent)
+	}
+
+	now = now.Add(2 * time.Hour)
+	service.Check(ctx)
+	if _, ok := sender.last()[AlertNoRecentSuccess]; !ok {
+		t.Fatalf("expected the no recent success alert after the window, got %v", sender.sent)
+	}
+
+	// Tạm dừng lại thì resolve alert
+	window.Paused = true
+	service.Check(ctx)
+	if alert := sender.last()[AlertNoRecentSuccess]; !alert.EndsAt.Equal(now) {
+		t.Errorf("expected the alert to be resolved when cleanup is paused, got %v", alert)
+	}
+	if active := service.Active(); len(active) != 0 {
+		t.Errorf("expected no active alert, got %v", active)
+	}
+}
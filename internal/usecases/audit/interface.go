@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+)
+
+type AuditUseCase interface {
+	// Record ghi một lời gọi API làm thay đổi trạng thái vào audit log
+	Record(ctx context.Context, entry models.AuditEntry) error
+
+	// ListEntries trả về các bản ghi audit theo bộ lọc, mới nhất trước
+	ListEntries(ctx context.Context, query repositories.AuditQuery, limit, offset int) ([]models.AuditEntry, error)
+
+	// Archive ghi các bản ghi cũ hơn before ra file NDJSON tại path rồi xóa chúng khỏi audit log.
+	// Trả về nil nếu không có bản ghi nào cần archive.
+	Archive(ctx context.Context, before time.Time, path string) (*models.AuditArchive, error)
+}
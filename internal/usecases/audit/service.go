@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// Verify that AuditService implements AuditUseCase
+var _ AuditUseCase = (*AuditService)(nil)
+
+// maxBodyBytes giới hạn phần body được lưu để một request lớn không làm phình audit log
+const maxBodyBytes = 4096
+
+type AuditService struct {
+	repo   repositories.AuditRepository
+	logger *zap.Logger
+}
+
+func NewAuditService(repo repositories.AuditRepository, logger *zap.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *AuditService) Record(ctx context.Context, entry models.AuditEntry) error {
+	entry.Params.Body = truncateBody(entry.Params.Body)
+
+	if err := s.repo.AppendEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	s.logger.Info("Audit entry recorded",
+		zap.String("actor", entry.Actor),
+		zap.String("source_ip", entry.SourceIP),
+		zap.String("request_id", entry.RequestID),
+		zap.String("method", entry.Method),
+		zap.String("path", entry.Path),
+		zap.Int("status", entry.Status),
+		zap.String("outcome", entry.Outcome))
+
+	return nil
+}
+
+func (s *AuditService) ListEntries(ctx context.Context, query repositories.AuditQuery, limit, offset int) ([]models.AuditEntry, error) {
+	return s.repo.ListEntries(ctx, query, limit, offset)
+}
+
+func (s *AuditService) Archive(ctx context.Context, before time.Time, path string) (*models.AuditArchive, error) {
+	archive, err := s.writeArchive(ctx, before, path)
+	if err != nil {
+		return nil, err
+	}
+	if archive.Entries == 0 {
+		os.Remove(path)
+		return nil, nil
+	}
+
+	// Chỉ xóa sau khi file đã được sync xuống đĩa, lỗi ở bước này để lại file archive thừa chứ không mất dữ liệu
+	deleted, err := s.repo.DeleteArchived(ctx, *archive)
+	if err != nil {
+		return nil, fmt.Errorf("audit entries were written to %s but not deleted: %w", path, err)
+	}
+
+	s.logger.Info("Audit log archived",
+		zap.String("path", archive.Path),
+		zap.Time("before", archive.Before),
+		zap.Int("entries", archive.Entries),
+		zap.Int64("deleted", deleted),
+		zap.String("sha256", archive.SHA256))
+
+	return archive, nil
+}
+
+// writeArchive ghi các bản ghi cũ hơn before ra file mới, mỗi dòng một bản ghi JSON.
+// File đã tồn tại không bị ghi đè; file ghi dở bị xóa khi có lỗi.
+func (s *AuditService) writeArchive(ctx context.Context, before time.Time, path string) (_ *models.AuditArchive, err error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit archive: %w", err)
+	}
+	defer func() {
+		file.Close()
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	hash := sha256.New()
+	buf := bufio.NewWriter(io.MultiWriter(file, hash))
+	encoder := json.NewEncoder(buf)
+
+	archive := &models.AuditArchive{
+		ArchivedAt: time.Now(),
+		Before:     before,
+		Path:       path,
+	}
+	err = s.repo.StreamEntriesBefore(ctx, before, func(entry models.AuditEntry) error {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write audit entry %d: %w", entry.ID, err)
+		}
+		archive.UpToID = entry.ID
+		archive.Entries++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := buf.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write audit archive: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync audit archive: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close audit archive: %w", err)
+	}
+
+	archive.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return archive, nil
+}
+
+// truncateBody cắt body về maxBodyBytes mà không cắt giữa một ký tự UTF-8
+func truncateBody(body string) string {
+	if len(body) <= maxBodyBytes {
+		return body
+	}
+
+	cut := maxBodyBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return body[:cut] + fmt.Sprintf("... (%d bytes truncated)", len(body)-cut)
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// memoryAuditRepository giữ audit log trong bộ nhớ, chỉ xóa bản ghi nằm trong archive
+type memoryAuditRepository struct {
+	repositories.AuditRepository
+	entries  []models.AuditEntry
+	archives []models.AuditArchive
+	nextID   int64
+}
+
+func (r *memoryAuditRepository) AppendEntry(ctx context.Context, entry models.AuditEntry) error {
+	r.nextID++
+	entry.ID = r.nextID
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryAuditRepository) StreamEntriesBefore(ctx context.Context, before time.Time, fn func(models.AuditEntry) error) error {
+	for _, entry := range r.entries {
+		if entry.Time.Before(before) {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryAuditRepository) DeleteArchived(ctx context.Context, archive models.AuditArchive) (int64, error) {
+	r.archives = append(r.archives, archive)
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if entry.ID > archive.UpToID || !entry.Time.Before(archive.Before) {
+			kept = append(kept, entry)
+		}
+	}
+	deleted := int64(len(r.entries) - len(kept))
+	r.entries = kept
+	return deleted, nil
+}
+
+func TestAuditServiceTruncatesBody(t *testing.T) {
+	repo := &memoryAuditRepository{}
+	service := NewAuditService(repo, zap.NewNop())
+
+	body := strings.Repeat("é", maxBodyBytes)
+	if err := service.Record(context.Background(), models.AuditEntry{Params: models.AuditParams{Body: body}}); err != nil {
+		t.Fatalf("failed to record entry: %v", err)
+	}
+
+	stored := repo.entries[0].Params.Body
+	if !strings.HasSuffix(stored, " bytes truncated)") {
+		t.Fatalf("expected a truncation marker, got %q", stored[len(stored)-40:])
+	}
+	if len(stored) > maxBodyBytes+64 || !strings.HasPrefix(stored, strings.Repeat("é", maxBodyBytes/2)) {
+		t.Errorf("expected body cut at %d bytes on a rune boundary, got %d bytes", maxBodyBytes, len(stored))
+	}
+}
+
+func TestAuditServiceArchive(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryAuditRepository{}
+	service := NewAuditService(repo, zap.NewNop())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, time.Hour} {
+		entry := models.AuditEntry{Time: now.Add(-age), Method: "POST", Route: "/api/v1/cleanup", Status: 200 + i}
+		if err := service.Record(ctx, entry); err != nil {
+			t.Fatalf("failed to record entry: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.ndjson")
+	archive, err := service.Archive(ctx, now.Add(-24*time.Hour), path)
+	if err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+	if archive == nil || archive.Entries != 2 || archive.UpToID != 2 || archive.SHA256 == "" {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+	if len(repo.entries) != 1 || repo.entries[0].Status != 202 {
+		t.Errorf("expected only the newest entry to remain, got %+v", repo.entries)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+
+	var statuses []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid archive line %q: %v", scanner.Text(), err)
+		}
+		statuses = append(statuses, entry.Status)
+	}
+	if len(statuses) != 2 || statuses[0] != 200 || statuses[1] != 201 {
+		t.Errorf("expected archived statuses [200 201], got %v", statuses)
+	}
+
+	// File đã tồn tại không bị ghi đè và không có gì bị xóa
+	if _, err := service.Archive(ctx, now, path); err == nil {
+		t.Error("expected archive to refuse an existing file")
+	}
+	if len(repo.entries) != 1 {
+		t.Errorf("expected entries to be kept when the archive file cannot be created, got %d", len(repo.entries))
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the existing archive to be kept: %v", err)
+	}
+
+	// Không có bản ghi nào cần archive thì không để lại file rỗng
+	empty := filepath.Join(dir, "empty.ndjson")
+	archive, err = service.Archive(ctx, now.Add(-100*time.Hour), empty)
+	if err != nil || archive != nil {
+		t.Fatalf("expected no archive, got %+v, %v", archive, err)
+	}
+	if _, err := os.Stat(empty); !os.IsNotExist(err) {
+		t.Errorf("expected no file for an empty archive, got %v", err)
+	}
+}
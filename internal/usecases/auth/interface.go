@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+)
+
+// ErrInvalidAPIKey được trả về khi key không tồn tại hoặc đã bị thu hồi
+var ErrInvalidAPIKey = errors.New("invalid or revoked api key")
+
+type AuthUseCase interface {
+	// Authenticate kiểm tra bearer token và trả về API key tương ứng
+	Authenticate(ctx context.Context, token string) (*models.APIKey, error)
+
+	// CreateKey tạo API key mới trong database; token chỉ được trả về một lần
+	CreateKey(ctx context.Context, name string, scopes []models.Scope) (string, *models.APIKey, error)
+
+	// ListKeys trả về các key trong database và trong API_KEYS_FILE
+	ListKeys(ctx context.Context) ([]models.APIKey, error)
+
+	// RevokeKey thu hồi key trong database
+	RevokeKey(ctx context.Context, id string) error
+}
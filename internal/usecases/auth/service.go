@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Verify that AuthService implements AuthUseCase
+var _ AuthUseCase = (*AuthService)(nil)
+
+const (
+	tokenPrefix   = "icu_"
+	tokenBytes    = 32
+	displayPrefix = len(tokenPrefix) + 8
+	touchInterval = time.Minute
+)
+
+type AuthService struct {
+	repo     repositories.APIKeyRepository
+	fileKeys map[string]models.APIKey // theo hash
+	logger   *zap.Logger
+}
+
+// NewAuthService tạo service xác thực từ key trong database và key tĩnh đọc từ API_KEYS_FILE
+func NewAuthService(repo repositories.APIKeyRepository, fileKeys []models.APIKey, logger *zap.Logger) *AuthService {
+	byHash := make(map[string]models.APIKey, len(fileKeys))
+	for _, key := range fileKeys {
+		byHash[key.Hash] = key
+	}
+
+	return &AuthService{
+		repo:     repo,
+		fileKeys: byHash,
+		logger:   logger,
+	}
+}
+
+// HashAPIKey trả về SHA-256 hex của token, là giá trị được lưu trong database và API_KEYS_FILE
+func HashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*models.APIKey, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	hash := HashAPIKey(token)
+
+	if key, ok := s.fileKeys[hash]; ok {
+		return &key, nil
+	}
+
+	key, err := s.repo.FindKeyByHash(ctx, hash)
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up api key: %w", err)
+	}
+
+	if key.Revoked() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Chỉ ghi last_used_at tối đa mỗi phút một lần để tránh ghi database ở mọi request
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval {
+		if err := s.repo.TouchKey(ctx, key.ID, now); err != nil {
+			s.logger.Warn("Failed to update api key usage", zap.String("key_id", key.ID), zap.Error(err))
+		}
+	}
+
+	return key, nil
+}
+
+func (s *AuthService) CreateKey(ctx context.Context, name string, scopes []models.Scope) (string, *models.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("api key name is required")
+	}
+	if len(scopes) == 0 {
+		return "", nil, fmt.Errorf("at least one scope is required")
+	}
+
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	token := tokenPrefix + hex.EncodeToString(raw)
+
+	key := models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prefix:    token[:displayPrefix],
+		Hash:      HashAPIKey(token),
+		Scopes:    scopes,
+		Source:    models.APIKeySourceDatabase,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return token, &key, nil
+}
+
+func (s *AuthService) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := s.repo.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range s.fileKeys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *AuthService) RevokeKey(ctx context.Context, id string) error {
+	if strings.HasPrefix(id, models.FileAPIKeyIDPrefix) {
+		return fmt.Errorf("key %s is loaded from API_KEYS_FILE, remove it from the file instead", id)
+	}
+	return s.repo.RevokeKey(ctx, id)
+}
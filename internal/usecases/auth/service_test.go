@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Mock API key repository
+type mockAPIKeyRepository struct {
+	keys    map[string]*models.APIKey // by hash
+	touched int
+}
+
+func newMockAPIKeyRepository() *mockAPIKeyRepository {
+	return &mockAPIKeyRepository{keys: make(map[string]*models.APIKey)}
+}
+
+func (m *mockAPIKeyRepository) CreateKey(ctx context.Context, key models.APIKey) error {
+	m.keys[key.Hash] = &key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) FindKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	key, ok := m.keys[hash]
+	if !ok {
+		return nil, repositories.ErrAPIKeyNotFound
+	}
+	found := *key
+	return &found, nil
+}
+
+func (m *mockAPIKeyRepository) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	for _, key := range m.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepository) RevokeKey(ctx context.Context, id string) error {
+	for _, key := range m.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			return nil
+		}
+	}
+	return repositories.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) TouchKey(ctx context.Context, id string, usedAt time.Time) error {
+	for _, key := range m.keys {
+		if key.ID == id {
+			key.LastUsedAt = &usedAt
+		}
+	}
+	m.touched++
+	return nil
+}
+
+func TestAuthService(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAPIKeyRepository()
+	fileKeys := []models.APIKey{{
+		ID:     models.FileAPIKeyIDPrefix + "prometheus",
+		Name:   "prometheus",
+		Hash:   HashAPIKey("static-token"),
+		Scopes: []models.Scope{models.ScopeRead},
+		Source: models.APIKeySourceFile,
+	}}
+	service := NewAuthService(repo, fileKeys, zap.NewNop())
+
+	token, key, err := service.CreateKey(ctx, "ci", []models.Scope{models.ScopeTrigger})
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	if !strings.HasPrefix(token, tokenPrefix) || !strings.HasPrefix(token, key.Prefix) {
+		t.Errorf("unexpected token %q for prefix %q", token, key.Prefix)
+	}
+	if key.Hash == token || key.Hash != HashAPIKey(token) {
+		t.Error("expected only the token hash to be stored")
+	}
+
+	t.Run("database key", func(t *testing.T) {
+		found, err := service.Authenticate(ctx, token)
+		if err != nil {
+			t.Fatalf("authenticate failed: %v", err)
+		}
+		if found.ID != key.ID || !found.HasScope(models.ScopeTrigger) || found.HasScope(models.ScopeRead) {
+			t.Errorf("unexpected key: %+v", found)
+		}
+
+		// Usage is recorded at most once per touch interval
+		if _, err := service.Authenticate(ctx, token); err != nil {
+			t.Fatalf("authenticate failed: %v", err)
+		}
+		if repo.touched != 1 {
+			t.Errorf("expected 1 usage update, got %d", repo.touched)
+		}
+	})
+
+	t.Run("file key", func(t *testing.T) {
+		found, err := service.Authenticate(ctx, "static-token")
+		if err != nil {
+			t.Fatalf("authenticate failed: %v", err)
+		}
+		if found.Name != "prometheus" || found.Source != models.APIKeySourceFile {
+			t.Errorf("unexpected key: %+v", found)
+		}
+		if err := service.RevokeKey(ctx, found.ID); err == nil {
+			t.Error("expected error when revoking a file key")
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		for _, token := range []string{"", "  ", "icu_unknown"} {
+			if _, err := service.Authenticate(ctx, token); !errors.Is(err, ErrInvalidAPIKey) {
+				t.Errorf("token %q: expected ErrInvalidAPIKey, got %v", token, err)
+			}
+		}
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		if err := service.RevokeKey(ctx, key.ID); err != nil {
+			t.Fatalf("revoke failed: %v", err)
+		}
+		if _, err := service.Authenticate(ctx, token); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("expected ErrInvalidAPIKey for revoked key, got %v", err)
+		}
+	})
+
+	keys, err := service.ListKeys(ctx)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("expected database and file keys, got %d", len(keys))
+	}
+}
+
+func TestAdminScopeImpliesAll(t *testing.T) {
+	key := models.APIKey{Scopes: []models.Scope{models.ScopeAdmin}}
+	for _, scope := range []models.Scope{models.ScopeRead, models.ScopeTrigger, models.ScopeAdmin} {
+		if !key.HasScope(scope) {
+			t.Errorf("expected admin key to have scope %s", scope)
+		}
+	}
+
+	if _, err := models.ParseScopes("read, write"); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+	if _, err := models.ParseScopes(" , "); err == nil {
+		t.Error("expected error for empty scopes")
+	}
+}
@@ -0,0 +1,18 @@
+package backup
+
+import (
+	"context"
+	"time"
+)
+
+// BackupInfo mô tả một bản backup đã tạo
+type BackupInfo struct {
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BackupUseCase interface {
+	// CreateBackup tạo bản backup mới trong thư mục backup
+	CreateBackup(ctx context.Context) (*BackupInfo, error)
+}
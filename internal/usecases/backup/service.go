@@ -0,0 +1,58 @@
+// internal/usecases/backup/service.go
+package backup
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Verify that BackupService implements BackupUseCase
+var _ BackupUseCase = (*BackupService)(nil)
+
+type BackupService struct {
+	repo      repositories.BackupRepository
+	backupDir string
+	logger    *zap.Logger
+}
+
+func NewBackupService(repo repositories.BackupRepository, backupDir string, logger *zap.Logger) *BackupService {
+	return &BackupService{
+		repo:      repo,
+		backupDir: backupDir,
+		logger:    logger,
+	}
+}
+
+func (s *BackupService) CreateBackup(ctx context.Context) (*BackupInfo, error) {
+	createdAt := time.Now().UTC()
+	// Mili giây và hậu tố ngẫu nhiên để hai backup trong cùng một giây không trùng tên file
+	name := fmt.Sprintf("cleanup-%s-%s.db", createdAt.Format("20060102-150405.000"), uuid.New().String()[:8])
+	path := filepath.Join(s.backupDir, name)
+
+	if err := s.repo.Backup(ctx, path); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup file: %w", err)
+	}
+
+	s.logger.Info("Database backup created",
+		zap.String("path", path),
+		zap.Int64("size_bytes", stat.Size()),
+		zap.String("duration", time.Since(createdAt).String()))
+
+	return &BackupInfo{
+		Path:      path,
+		SizeBytes: stat.Size(),
+		CreatedAt: createdAt,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fileBackupRepository ghi một file rỗng và từ chối ghi đè như SQLiteBackupRepository
+type fileBackupRepository struct{}
+
+func (fileBackupRepository) Backup(ctx context.Context, destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup file already exists: %s", destPath)
+	}
+	return os.WriteFile(destPath, []byte("backup"), 0644)
+}
+
+func TestBackupServiceCreatesUniqueNames(t *testing.T) {
+	service := NewBackupService(fileBackupRepository{}, t.TempDir(), zap.NewNop())
+
+	// Nhiều backup trong cùng một giây không được trùng tên
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		info, err := service.CreateBackup(context.Background())
+		if err != nil {
+			t.Fatalf("backup #%d failed: %v", i+1, err)
+		}
+		if seen[info.Path] {
+			t.Fatalf("backup #%d reused the name %s", i+1, info.Path)
+		}
+		seen[info.Path] = true
+	}
+}
@@ -2,12 +2,14 @@ package cleanup
 
 import (
 	"context"
+	"go-image-cleanup/internal/domain/models"
 	"time"
 )
 
 // CleanupStats chứa thống kê về quá trình cleanup
 type CleanupStats struct {
 	HostInfo   string        `json:"host_info"`
+	Host       models.Host   `json:"host"`
 	StartTime  time.Time     `json:"start_time"`
 	EndTime    time.Time     `json:"end_time"`
 	Duration   time.Duration `json:"duration"`
@@ -16,9 +18,23 @@ type CleanupStats struct {
 	Skipped    int           `json:"skipped"`
 }
 
+// Inventory là danh sách image hiện tại trên node kèm quyết định của policy, tức kết quả dry-run của cleanup
+type Inventory struct {
+	Host             models.Host     `json:"host"`
+	GeneratedAt      time.Time       `json:"generated_at"`
+	TotalCount       int             `json:"total_count"`
+	RemoveCount      int             `json:"remove_count"`
+	KeepCount        int             `json:"keep_count"`
+	ReclaimableBytes uint64          `json:"reclaimable_bytes"`
+	Images           []ImageDecision `json:"images"`
+}
+
 type CleanupUseCase interface {
 	Cleanup(ctx context.Context) error
 
-	// GetLastCleanupStats trả về thông tin của lần cleanup gần nhất
+	// Inventory liệt kê image và quyết định của policy mà không xóa gì
+	Inventory(ctx context.Context) (*Inventory, error)
+
+	// GetLastCleanupStats trả về thông tin của lần cleanup gần nhất trên node này
 	GetLastCleanupStats() (*CleanupStats, error)
 }
@@ -0,0 +1,45 @@
+package cleanup
+
+import "go-image-cleanup/internal/domain/models"
+
+// Hành động mà policy cleanup quyết định cho một image
+const (
+	ActionRemove = "remove"
+	ActionKeep   = "keep"
+)
+
+// Lý do của quyết định
+const (
+	ReasonInUse  = "in_use"
+	ReasonUnused = "unused"
+)
+
+// ImageDecision là quyết định của policy cleanup cho một image
+type ImageDecision struct {
+	ID        string   `json:"id"`
+	Tags      []string `json:"tags"`
+	SizeBytes uint64   `json:"size_bytes"`
+	InUse     bool     `json:"in_use"`
+	Action    string   `json:"action"`
+	Reason    string   `json:"reason"`
+}
+
+// decide áp dụng policy cleanup: image đang được container (kể cả đã dừng) sử dụng thì giữ lại, còn lại thì xóa
+func decide(img models.Image, usedImages map[string]bool) ImageDecision {
+	decision := ImageDecision{
+		ID:        img.ID,
+		Tags:      img.Tags,
+		SizeBytes: img.Size,
+		InUse:     usedImages[img.ID],
+		Action:    ActionRemove,
+		Reason:    ReasonUnused,
+	}
+	if decision.InUse {
+		decision.Action = ActionKeep
+		decision.Reason = ReasonInUse
+	}
+	if decision.Tags == nil {
+		decision.Tags = []string{}
+	}
+	return decision
+}
@@ -4,17 +4,17 @@ package cleanup
 import (
 	"context"
 	"fmt"
+	"go-image-cleanup/internal/domain/disk"
+	"go-image-cleanup/internal/domain/host"
 	"go-image-cleanup/internal/domain/metrics"
 	"go-image-cleanup/internal/domain/models"
 	"go-image-cleanup/internal/domain/notification"
 	"go-image-cleanup/internal/domain/repositories"
 	"go-image-cleanup/pkg/helper"
-	"net"
-	"os"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -24,18 +24,25 @@ var _ CleanupUseCase = (*CleanupService)(nil)
 type CleanupService struct {
 	repo       repositories.ImageRepository
 	resultRepo repositories.CleanupResultRepository
-	notifier   notification.Notifier
+	notifier   notification.EventNotifier
 	metrics    metrics.MetricsCollector
+	hosts      host.Identifier
 	logger     *zap.Logger
 	timeout    time.Duration
 	workerPool int
+
+	// Kiểm tra disk pressure sau mỗi lần chạy, tắt khi disk = nil
+	disk          disk.UsageReader
+	diskPath      string
+	diskThreshold float64
 }
 
 func NewCleanupService(
 	repo repositories.ImageRepository,
 	resultRepo repositories.CleanupResultRepository,
-	notifier notification.Notifier,
+	notifier notification.EventNotifier,
 	metrics metrics.MetricsCollector,
+	hosts host.Identifier,
 	logger *zap.Logger,
 ) *CleanupService {
 	return &CleanupService{
@@ -43,30 +50,35 @@ func NewCleanupService(
 		resultRepo: resultRepo,
 		notifier:   notifier,
 		metrics:    metrics,
+		hosts:      hosts,
 		logger:     logger,
 		timeout:    5 * time.Minute, // Configurable timeout
 		workerPool: 5,               // Configurable worker pool size
 	}
 }
 
+// SetDiskPressureCheck bật cảnh báo khi filesystem chứa path vẫn dùng quá thresholdPercent sau cleanup
+func (s *CleanupService) SetDiskPressureCheck(reader disk.UsageReader, path string, thresholdPercent float64) {
+	s.disk = reader
+	s.diskPath = path
+	s.diskThreshold = thresholdPercent
+}
+
 // Thêm phương thức GetLastCleanupStats để implement interface
 func (s *CleanupService) GetLastCleanupStats() (*CleanupStats, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	result, err := s.resultRepo.GetLatestResult(ctx)
-
-	// Lấy thông tin hostname thay vì trả về chuỗi cố định
-	hostname, ips, hostErr := s.getHostInfo()
-	hostInfo := "Unknown host"
-	if hostErr == nil {
-		hostInfo = fmt.Sprintf("Host: %s\nIP(s): %s", hostname, ips)
-	}
-
+	// Store PostgreSQL dùng chung giữa các node: chỉ đọc lần chạy của node này
+	host := s.identifyHost(ctx)
+	result, err := s.resultRepo.GetLatestResult(ctx, host.NodeName)
 	if err != nil {
 		s.logger.Warn("Failed to get latest cleanup result", zap.Error(err))
+
+		// Chưa có kết quả nào, trả về danh tính host hiện tại
 		return &CleanupStats{
-			HostInfo:   hostInfo,
+			HostInfo:   host.String(),
+			Host:       host,
 			StartTime:  time.Time{},
 			EndTime:    time.Time{},
 			Duration:   0,
@@ -78,6 +90,7 @@ func (s *CleanupService) GetLastCleanupStats() (*CleanupStats, error) {
 
 	return &CleanupStats{
 		HostInfo:   result.HostInfo,
+		Host:       result.Host,
 		StartTime:  result.StartTime,
 		EndTime:    result.EndTime,
 		Duration:   result.Duration,
@@ -87,12 +100,25 @@ func (s *CleanupService) GetLastCleanupStats() (*CleanupStats, error) {
 	}, nil
 }
 
-func (s *CleanupService) removeImagesInParallel(ctx context.Context, images []models.Image, usedImages map[string]bool) (int, int) {
+// removalStats đếm kết quả xóa image; skipped gồm cả các image xóa lỗi (failed) như trước đây
+type removalStats struct {
+	removed        int
+	skipped        int
+	failed         int
+	reclaimedBytes uint64
+
+	// deleted giữ các image đã xóa để gửi ImageDeleted sau khi worker xong, không chặn worker khi notifier chậm
+	deleted []models.Image
+
+	// removals là sự kiện xóa (thành công hoặc lỗi) của từng image, được lưu một lần sau khi worker xong
+	removals []repositories.ImageRemoval
+}
+
+func (s *CleanupService) removeImagesInParallel(ctx context.Context, log *zap.Logger, host models.Host, runID, requestID string, images []models.Image, usedImages map[string]bool) removalStats {
 	var (
-		wg      sync.WaitGroup
-		mu      sync.Mutex // Protects access to counters
-		removed int
-		skipped int
+		wg    sync.WaitGroup
+		mu    sync.Mutex // Protects access to counters
+		stats removalStats
 	)
 
 	// Create job channel
@@ -108,30 +134,51 @@ func (s *CleanupService) removeImagesInParallel(ctx context.Context, images []mo
 				case <-ctx.Done():
 					return
 				default:
-					if usedImages[img.ID] {
+					if decision := decide(img, usedImages); decision.Action == ActionKeep {
 						mu.Lock()
-						skipped++
+						stats.skipped++
 						mu.Unlock()
-						s.logger.Info("Skipping image in use",
+						log.Info("Skipping image in use",
 							zap.String("id", img.ID),
-							zap.Strings("tags", img.Tags))
+							zap.Strings("tags", img.Tags),
+							zap.String("reason", decision.Reason))
 						continue
 					}
 
+					removal := repositories.ImageRemoval{
+						RunID:     runID,
+						RequestID: requestID,
+						ImageID:   img.ID,
+						Tags:      img.Tags,
+						SizeBytes: img.Size,
+						Outcome:   repositories.RemovalOutcomeRemoved,
+					}
+
 					if err := s.repo.RemoveImage(ctx, img.ID); err != nil {
+						removal.Outcome = repositories.RemovalOutcomeFailed
+						removal.Error = err.Error()
+						removal.Time = time.Now()
+
 						mu.Lock()
-						skipped++
+						stats.skipped++
+						stats.failed++
+						stats.removals = append(stats.removals, removal)
 						mu.Unlock()
-						s.logger.Error("Failed to remove image",
+						log.Error("Failed to remove image",
 							zap.String("id", img.ID),
 							zap.Error(err))
 						continue
 					}
 
+					removal.Time = time.Now()
+
 					mu.Lock()
-					removed++
+					stats.removed++
+					stats.reclaimedBytes += img.Size
+					stats.deleted = append(stats.deleted, img)
+					stats.removals = append(stats.removals, removal)
 					mu.Unlock()
-					s.logger.Info("Successfully removed image",
+					log.Info("Successfully removed image",
 						zap.String("id", img.ID),
 						zap.Strings("tags", img.Tags))
 				}
@@ -144,7 +191,11 @@ func (s *CleanupService) removeImagesInParallel(ctx context.Context, images []mo
 		select {
 		case jobs <- img:
 		case <-ctx.Done():
-			return removed, skipped
+			close(jobs)
+			wg.Wait()
+			s.saveRemovals(ctx, log, stats.removals)
+			s.notifyDeleted(ctx, log, host, requestID, stats.deleted)
+			return stats
 		}
 	}
 	close(jobs)
@@ -152,76 +203,115 @@ func (s *CleanupService) removeImagesInParallel(ctx context.Context, images []mo
 	// Wait for all workers to complete
 	wg.Wait()
 
-	return removed, skipped
+	s.saveRemovals(ctx, log, stats.removals)
+	s.notifyDeleted(ctx, log, host, requestID, stats.deleted)
+	return stats
 }
 
-// getHostInfo returns hostname and IP addresses
-func (s *CleanupService) getHostInfo() (string, string, error) {
-	hostname, err := os.Hostname()
-	if err != nil {
-		return "", "", fmt.Errorf("failed to get hostname: %w", err)
+// saveRemovals lưu sự kiện xóa của từng image; lỗi chỉ được ghi log như khi lưu kết quả lần chạy
+func (s *CleanupService) saveRemovals(ctx context.Context, log *zap.Logger, removals []repositories.ImageRemoval) {
+	if len(removals) == 0 {
+		return
 	}
+	if err := s.resultRepo.SaveRemovals(ctx, removals); err != nil {
+		log.Error("Failed to save image removals",
+			zap.Int("count", len(removals)),
+			zap.Error(err))
+	}
+}
 
-	// Get all network interfaces
-	interfaces, err := net.Interfaces()
+// notifyDeleted gửi ImageDeleted cho các image đã xóa, sau khi mọi worker đã dừng
+func (s *CleanupService) notifyDeleted(ctx context.Context, log *zap.Logger, host models.Host, requestID string, images []models.Image) {
+	for _, img := range images {
+		s.notify(ctx, log, notification.ImageDeleted{
+			Host:      host,
+			ImageID:   img.ID,
+			Tags:      img.Tags,
+			SizeBytes: img.Size,
+			RequestID: requestID,
+		})
+	}
+}
+
+// identifyHost returns the current host identity, logging when parts of it are unavailable
+func (s *CleanupService) identifyHost(ctx context.Context) models.Host {
+	host, err := s.hosts.Identify(ctx)
 	if err != nil {
-		return hostname, "", fmt.Errorf("failed to get network interfaces: %w", err)
+		s.logger.Warn("Host identity is incomplete", zap.Error(err))
 	}
+	return host
+}
 
-	var ipAddresses []string
-	for _, iface := range interfaces {
-		// Skip loopback and interfaces that are down
-		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
-			continue
-		}
+func (s *CleanupService) Inventory(ctx context.Context) (*Inventory, error) {
+	images, err := s.repo.GetAllImages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images: %w", err)
+	}
 
-		addrs, err := iface.Addrs()
-		if err != nil {
-			continue
-		}
+	usedImages, err := s.repo.GetUsedImages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get used images: %w", err)
+	}
 
-		for _, addr := range addrs {
-			// Convert address to string and extract IP
-			ipNet, ok := addr.(*net.IPNet)
-			if !ok {
-				continue
-			}
-			ip := ipNet.IP.String()
-			// Skip loopback and IPv6 addresses
-			if !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
-				ipAddresses = append(ipAddresses, ip)
-			}
+	inventory := &Inventory{
+		Host:        s.identifyHost(ctx),
+		GeneratedAt: time.Now().UTC(),
+		TotalCount:  len(images),
+		Images:      make([]ImageDecision, 0, len(images)),
+	}
+	for _, img := range images {
+		decision := decide(img, usedImages)
+		if decision.Action == ActionRemove {
+			inventory.RemoveCount++
+			inventory.ReclaimableBytes += decision.SizeBytes
+		} else {
+			inventory.KeepCount++
 		}
+		inventory.Images = append(inventory.Images, decision)
 	}
 
-	return hostname, strings.Join(ipAddresses, ", "), nil
+	s.logger.Info("Image inventory collected",
+		zap.Int("total", inventory.TotalCount),
+		zap.Int("remove", inventory.RemoveCount),
+		zap.Int("keep", inventory.KeepCount),
+		zap.Uint64("reclaimable_bytes", inventory.ReclaimableBytes))
+
+	return inventory, nil
 }
 
 func (s *CleanupService) Cleanup(ctx context.Context) error {
 	startTime := helper.TimeInICT(time.Now())
 
+	// Lần chạy do API kích hoạt mang request ID để đối chiếu log, kết quả và thông báo với lời gọi API
+	requestID := helper.RequestIDFromContext(ctx)
+	log := s.logger
+	if requestID != "" {
+		log = log.With(zap.String("request_id", requestID))
+	}
+
+	// Get host information
+	host := s.identifyHost(ctx)
+	hostInfo := host.String()
+
+	s.notify(ctx, log, notification.RunStarted{Host: host, StartTime: startTime, RequestID: requestID})
+
 	images, err := s.repo.GetAllImages(ctx)
 	if err != nil {
-		s.metrics.IncCleanupErrors()
-		return fmt.Errorf("failed to get images: %w", err)
+		return s.fail(ctx, log, host, startTime, requestID, fmt.Errorf("failed to get images: %w", err))
 	}
 
 	usedImages, err := s.repo.GetUsedImages(ctx)
 	if err != nil {
-		s.metrics.IncCleanupErrors()
-		return fmt.Errorf("failed to get used images: %w", err)
+		return s.fail(ctx, log, host, startTime, requestID, fmt.Errorf("failed to get used images: %w", err))
 	}
 
-	stats := struct {
-		total   int
-		removed int
-		skipped int
-	}{
-		total: len(images),
-	}
+	total := len(images)
+
+	// ID của lần chạy được tạo trước để sự kiện xóa từng image trỏ về kết quả được lưu sau cùng
+	runID := uuid.New().String()
 
 	// Remove images in parallel
-	stats.removed, stats.skipped = s.removeImagesInParallel(ctx, images, usedImages)
+	stats := s.removeImagesInParallel(ctx, log, host, runID, requestID, images, usedImages)
 
 	// Update metrics
 	for i := 0; i < stats.removed; i++ {
@@ -231,13 +321,6 @@ func (s *CleanupService) Cleanup(ctx context.Context) error {
 		s.metrics.IncImagesSkipped()
 	}
 
-	// Get host information
-	hostname, ips, err := s.getHostInfo()
-	hostInfo := "Unknown host"
-	if err == nil {
-		hostInfo = fmt.Sprintf("Host: %s\nIP(s): %s", hostname, ips)
-	}
-
 	endTime := helper.TimeInICT(time.Now())
 	duration := endTime.Sub(startTime)
 
@@ -246,46 +329,127 @@ func (s *CleanupService) Cleanup(ctx context.Context) error {
 	s.metrics.ObserveCleanupDuration(duration)
 
 	// Send notification
-	message := helper.FormatCleanupMessage(
-		hostInfo,
-		startTime,
-		endTime,
-		duration,
-		stats.total,
-		stats.removed,
-		stats.skipped,
-	)
-
-	if err := s.notifier.SendNotification(message); err != nil {
-		s.logger.Error("Failed to send notification", zap.Error(err))
-		s.metrics.IncCleanupErrors()
+	report := notification.Report{
+		Host:           host,
+		HostInfo:       hostInfo,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Duration:       duration,
+		Total:          total,
+		Removed:        stats.removed,
+		Skipped:        stats.skipped,
+		Failed:         stats.failed,
+		ReclaimedBytes: stats.reclaimedBytes,
+		RequestID:      requestID,
+		Message: helper.FormatCleanupMessage(
+			hostInfo,
+			startTime,
+			endTime,
+			duration,
+			total,
+			stats.removed,
+			stats.skipped,
+			requestID,
+		),
 	}
 
-	s.logger.Info("Cleanup completed",
-		zap.Int("total", stats.total),
+	s.notify(ctx, log, report)
+
+	log.Info("Cleanup completed",
+		zap.Int("total", total),
 		zap.Int("removed", stats.removed),
 		zap.Int("skipped", stats.skipped),
-		zap.String("hostname", hostname),
-		zap.String("ips", ips),
+		zap.Int("failed", stats.failed),
+		zap.Uint64("reclaimed_bytes", stats.reclaimedBytes),
+		zap.String("hostname", host.Hostname),
+		zap.String("node_name", host.NodeName),
+		zap.Strings("ipv4", host.IPv4),
 		zap.String("start_time", helper.FormatICT(startTime)),
 		zap.String("end_time", helper.FormatICT(endTime)),
 		zap.String("duration", duration.String()))
 
 	// Lưu kết quả vào repository
 	result := repositories.CleanupResult{
+		ID:         runID,
 		HostInfo:   hostInfo,
+		Host:       host,
 		StartTime:  startTime,
 		EndTime:    endTime,
 		Duration:   duration,
-		TotalCount: stats.total,
+		TotalCount: total,
 		Removed:    stats.removed,
 		Skipped:    stats.skipped,
 		CreatedAt:  time.Now(),
+		RequestID:  requestID,
 	}
 
 	if err := s.resultRepo.SaveResult(ctx, result); err != nil {
-		s.logger.Error("Failed to save cleanup result", zap.Error(err))
+		log.Error("Failed to save cleanup result", zap.Error(err))
 		// Không return error ở đây, cleanup vẫn thành công
 	}
+
+	s.checkDiskPressure(ctx, log, host)
 	return nil
 }
+
+// fail kết thúc lần chạy bị lỗi. Lần chạy bị hủy (shutdown, hết timeout) không được tính là lỗi
+func (s *CleanupService) fail(ctx context.Context, log *zap.Logger, host models.Host, startTime time.Time, requestID string, err error) error {
+	if ctx.Err() != nil {
+		log.Warn("Cleanup cancelled", zap.Error(err))
+		return err
+	}
+
+	s.metrics.IncCleanupErrors()
+	log.Error("Cleanup failed", zap.Error(err))
+	s.notify(ctx, log, notification.RunFailed{
+		Host:      host,
+		StartTime: startTime,
+		Error:     err.Error(),
+		RequestID: requestID,
+	})
+	return err
+}
+
+// notify gửi sự kiện tới các kênh; lỗi gửi chỉ được ghi log và đếm, không làm hỏng lần chạy
+func (s *CleanupService) notify(ctx context.Context, log *zap.Logger, event notification.Event) {
+	// Context đã hủy thì không kênh nào gửi được nữa
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, event); err != nil {
+		log.Error("Failed to send notification",
+			zap.String("event", string(event.Type())),
+			zap.Error(err))
+		s.metrics.IncCleanupErrors()
+	}
+}
+
+// checkDiskPressure cảnh báo khi cleanup không giải phóng đủ dung lượng
+func (s *CleanupService) checkDiskPressure(ctx context.Context, log *zap.Logger, host models.Host) {
+	if s.disk == nil {
+		return
+	}
+
+	usage, err := s.disk.Usage(s.diskPath)
+	if err != nil {
+		log.Warn("Failed to read disk usage", zap.String("path", s.diskPath), zap.Error(err))
+		return
+	}
+	percent := usage.UsedPercent()
+	if percent < s.diskThreshold {
+		return
+	}
+
+	log.Warn("Disk usage is above threshold after cleanup",
+		zap.String("path", usage.Path),
+		zap.Float64("used_percent", percent),
+		zap.Float64("threshold_percent", s.diskThreshold))
+	s.notify(ctx, log, notification.DiskPressure{
+		Host:             host,
+		Path:             usage.Path,
+		UsedBytes:        usage.UsedBytes,
+		TotalBytes:       usage.TotalBytes,
+		UsedPercent:      percent,
+		ThresholdPercent: s.diskThreshold,
+	})
+}
@@ -3,8 +3,13 @@ package cleanup
 import (
 	"context"
 	"fmt"
+	"go-image-cleanup/internal/domain/disk"
 	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
 	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/pkg/helper"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -47,7 +52,8 @@ func (m *mockImageRepository) RemoveImage(ctx context.Context, imageID string) e
 
 // Mock cleanup result repository
 type mockCleanupResultRepository struct {
-	savedResults []repositories.CleanupResult
+	savedResults  []repositories.CleanupResult
+	savedRemovals []repositories.ImageRemoval
 }
 
 func (m *mockCleanupResultRepository) SaveResult(ctx context.Context, result repositories.CleanupResult) error {
@@ -55,13 +61,14 @@ func (m *mockCleanupResultRepository) SaveResult(ctx context.Context, result rep
 	return nil
 }
 
-func (m *mockCleanupResultRepository) GetLatestResult(ctx context.Context) (*repositories.CleanupResult, error) {
-	if len(m.savedResults) == 0 {
-		return nil, fmt.Errorf("no cleanup results found")
+func (m *mockCleanupResultRepository) GetLatestResult(ctx context.Context, nodeName string) (*repositories.CleanupResult, error) {
+	// Return the most recent result of the node
+	for i := len(m.savedResults) - 1; i >= 0; i-- {
+		if result := m.savedResults[i]; nodeName == "" || result.Host.NodeName == nodeName {
+			return &result, nil
+		}
 	}
-	// Return the most recent result
-	result := m.savedResults[len(m.savedResults)-1]
-	return &result, nil
+	return nil, fmt.Errorf("no cleanup results found")
 }
 
 func (m *mockCleanupResultRepository) GetResultByID(ctx context.Context, id string) (*repositories.CleanupResult, error) {
@@ -73,7 +80,7 @@ func (m *mockCleanupResultRepository) GetResultByID(ctx context.Context, id stri
 	return nil, fmt.Errorf("result with ID %s not found", id)
 }
 
-func (m *mockCleanupResultRepository) GetResults(ctx context.Context, limit, offset int) ([]repositories.CleanupResult, error) {
+func (m *mockCleanupResultRepository) GetResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
 	total := len(m.savedResults)
 	if offset >= total {
 		return []repositories.CleanupResult{}, nil
@@ -87,16 +94,102 @@ func (m *mockCleanupResultRepository) GetResults(ctx context.Context, limit, off
 	return m.savedResults[offset:end], nil
 }
 
-// Mock notifier
+func (m *mockCleanupResultRepository) StreamResults(ctx context.Context, query repositories.ResultQuery, fn func(repositories.CleanupResult) error) error {
+	for _, result := range m.savedResults {
+		if !query.From.IsZero() && result.StartTime.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && !result.StartTime.Before(query.To) {
+			continue
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockCleanupResultRepository) SaveRemovals(ctx context.Context, removals []repositories.ImageRemoval) error {
+	m.savedRemovals = append(m.savedRemovals, removals...)
+	return nil
+}
+
+func (m *mockCleanupResultRepository) StreamRemovals(ctx context.Context, query repositories.ResultQuery, fn func(repositories.ImageRemoval) error) error {
+	for _, removal := range m.savedRemovals {
+		if err := fn(removal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failingImageRepository không liệt kê được image, như khi container runtime không phản hồi
+type failingImageRepository struct {
+	*mockImageRepository
+}
+
+func (m *failingImageRepository) GetAllImages(ctx context.Context) ([]models.Image, error) {
+	return nil, fmt.Errorf("runtime unavailable")
+}
+
+// Mock notifier, các worker gửi sự kiện song song
 type mockNotifier struct {
-	messages []string
+	mu     sync.Mutex
+	events []notification.Event
 }
 
-func (m *mockNotifier) SendNotification(message string) error {
-	m.messages = append(m.messages, message)
+func (m *mockNotifier) Notify(ctx context.Context, event notification.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
 	return nil
 }
 
+// reports trả về các report (run completed) đã gửi
+func (m *mockNotifier) reports() []notification.Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var reports []notification.Report
+	for _, event := range m.events {
+		if report, ok := event.(notification.Report); ok {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func (m *mockNotifier) count(eventType notification.EventType) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, event := range m.events {
+		if event.Type() == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// Mock disk usage reader
+type mockUsageReader struct {
+	usage disk.Usage
+}
+
+func (m *mockUsageReader) Usage(path string) (disk.Usage, error) {
+	usage := m.usage
+	usage.Path = path
+	return usage, nil
+}
+
+// Mock host identifier
+type mockHostIdentifier struct {
+	host models.Host
+}
+
+func (m *mockHostIdentifier) Identify(ctx context.Context) (models.Host, error) {
+	return m.host, nil
+}
+
 // Mock metrics collector
 type mockMetricsCollector struct {
 	imagesRemoved   int
@@ -104,9 +197,16 @@ type mockMetricsCollector struct {
 	cleanupErrors   int
 	lastCleanupTime time.Time
 	cleanupDuration time.Duration
+	databaseSize    int64
+	resultsPruned   int64
 	httpRequests    map[string]int // track requests by path
 	httpTimeouts    map[string]int // track timeouts by path
 	httpErrors      map[string]int // track errors by path
+	authFailures    map[string]int // track auth failures by path and reason
+	httpDurations   map[string]int // track observed latencies by path
+	httpInFlight    int
+	notifications   map[string]int // track deliveries by channel and status
+	outboxMessages  map[string]int // track outbox size by status
 }
 
 func (m *mockMetricsCollector) IncImagesRemoved() {
@@ -129,6 +229,14 @@ func (m *mockMetricsCollector) IncCleanupErrors() {
 	m.cleanupErrors++
 }
 
+func (m *mockMetricsCollector) SetDatabaseSize(bytes int64) {
+	m.databaseSize = bytes
+}
+
+func (m *mockMetricsCollector) AddResultsPruned(count int64) {
+	m.resultsPruned += count
+}
+
 // New methods to implement HTTP metrics
 func (m *mockMetricsCollector) IncHttpRequests(path, method string, status int) {
 	if m.httpRequests == nil {
@@ -154,6 +262,45 @@ func (m *mockMetricsCollector) IncHttpError(path, method string, status int, err
 	m.httpErrors[key]++
 }
 
+func (m *mockMetricsCollector) IncAuthFailures(path, reason string) {
+	if m.authFailures == nil {
+		m.authFailures = make(map[string]int)
+	}
+	key := fmt.Sprintf("%s-%s", path, reason)
+	m.authFailures[key]++
+}
+
+func (m *mockMetricsCollector) ObserveHttpDuration(path, method string, status int, duration time.Duration) {
+	if m.httpDurations == nil {
+		m.httpDurations = make(map[string]int)
+	}
+	key := fmt.Sprintf("%s-%s-%d", path, method, status)
+	m.httpDurations[key]++
+}
+
+func (m *mockMetricsCollector) IncHttpInFlight() {
+	m.httpInFlight++
+}
+
+func (m *mockMetricsCollector) DecHttpInFlight() {
+	m.httpInFlight--
+}
+
+func (m *mockMetricsCollector) IncNotifications(channel, status string) {
+	if m.notifications == nil {
+		m.notifications = make(map[string]int)
+	}
+	key := fmt.Sprintf("%s-%s", channel, status)
+	m.notifications[key]++
+}
+
+func (m *mockMetricsCollector) SetOutboxMessages(status string, count int) {
+	if m.outboxMessages == nil {
+		m.outboxMessages = make(map[string]int)
+	}
+	m.outboxMessages[status] = count
+}
+
 func TestCleanupService(t *testing.T) {
 	// Setup logger
 	logger, _ := zap.NewDevelopment()
@@ -241,7 +388,8 @@ func TestCleanupService(t *testing.T) {
 			resultRepo := &mockCleanupResultRepository{} // Thêm mock repository mới
 
 			// Create service
-			service := NewCleanupService(repo, resultRepo, notifier, metrics, logger)
+			hosts := &mockHostIdentifier{host: models.Host{Hostname: "test-host", NodeName: "test-node"}}
+			service := NewCleanupService(repo, resultRepo, notifier, metrics, hosts, logger)
 
 			// If test requires sleep before cleanup
 			if tt.sleepBefore > 0 {
@@ -277,11 +425,11 @@ func TestCleanupService(t *testing.T) {
 			}
 
 			// Check notifications
-			if tt.wantNotified && len(notifier.messages) == 0 {
+			if tt.wantNotified && len(notifier.reports()) == 0 {
 				t.Error("expected notification, but none was sent")
 			}
-			if !tt.wantNotified && len(notifier.messages) > 0 {
-				t.Error("unexpected notification was sent")
+			if !tt.wantNotified && len(notifier.events) > 0 {
+				t.Errorf("unexpected notification was sent: %v", notifier.events)
 			}
 
 			// Check if results were saved to repository
@@ -291,6 +439,329 @@ func TestCleanupService(t *testing.T) {
 			if !tt.wantSaved && len(resultRepo.savedResults) > 0 {
 				t.Error("unexpected cleanup result was saved to repository")
 			}
+			if tt.wantSaved && len(resultRepo.savedResults) > 0 && resultRepo.savedResults[0].Host.NodeName != "test-node" {
+				t.Errorf("expected saved result to carry host identity, got %+v", resultRepo.savedResults[0].Host)
+			}
 		})
 	}
 }
+
+func TestCleanupServiceInventory(t *testing.T) {
+	repo := &mockImageRepository{
+		images: []models.Image{
+			{ID: "1", Tags: []string{"app:v1"}, Size: 100},
+			{ID: "2", Tags: []string{"app:v2"}, Size: 200},
+			{ID: "3", Size: 300},
+		},
+		usedImages: map[string]bool{"2": true},
+	}
+	resultRepo := &mockCleanupResultRepository{}
+	service := NewCleanupService(repo, resultRepo, &mockNotifier{}, &mockMetricsCollector{},
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host"}}, zap.NewNop())
+
+	inventory, err := service.Inventory(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if inventory.TotalCount != 3 || inventory.RemoveCount != 2 || inventory.KeepCount != 1 {
+		t.Errorf("unexpected counts: total=%d remove=%d keep=%d", inventory.TotalCount, inventory.RemoveCount, inventory.KeepCount)
+	}
+	if inventory.ReclaimableBytes != 400 {
+		t.Errorf("expected 400 reclaimable bytes, got %d", inventory.ReclaimableBytes)
+	}
+	if inventory.Host.Hostname != "test-host" {
+		t.Errorf("expected host test-host, got %s", inventory.Host.Hostname)
+	}
+
+	expected := map[string]string{"1": ActionRemove, "2": ActionKeep, "3": ActionRemove}
+	for _, decision := range inventory.Images {
+		if decision.Action != expected[decision.ID] {
+			t.Errorf("image %s: expected action %s, got %s", decision.ID, expected[decision.ID], decision.Action)
+		}
+		if decision.Tags == nil {
+			t.Errorf("image %s: expected empty tag list instead of nil", decision.ID)
+		}
+	}
+
+	// Inventory là dry-run, không được xóa hay lưu kết quả
+	if len(resultRepo.savedResults) != 0 {
+		t.Errorf("expected no saved results, got %d", len(resultRepo.savedResults))
+	}
+}
+
+func TestCleanupServiceCarriesRequestID(t *testing.T) {
+	repo := &mockImageRepository{images: []models.Image{{ID: "1", Tags: []string{"app:v1"}}}}
+	resultRepo := &mockCleanupResultRepository{}
+	notifier := &mockNotifier{}
+	service := NewCleanupService(repo, resultRepo, notifier, &mockMetricsCollector{},
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host"}}, zap.NewNop())
+
+	ctx := helper.WithRequestID(context.Background(), "req-42")
+	if err := service.Cleanup(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resultRepo.savedResults) != 1 || resultRepo.savedResults[0].RequestID != "req-42" {
+		t.Errorf("expected saved result to carry request ID req-42, got %+v", resultRepo.savedResults)
+	}
+	reports := notifier.reports()
+	if len(reports) != 1 || reports[0].RequestID != "req-42" || !strings.Contains(reports[0].Message, "req-42") {
+		t.Errorf("expected notification to mention request ID req-42, got %+v", reports)
+	}
+}
+
+func TestCleanupServiceSendsReport(t *testing.T) {
+	repo := &mockImageRepository{
+		images: []models.Image{
+			{ID: "1", Tags: []string{"app:v1"}, Size: 1024},
+			{ID: "2", Tags: []string{"app:v2"}, Size: 2048},
+			{ID: "3", Size: 4096},
+		},
+		usedImages: map[string]bool{"2": true},
+	}
+	notifier := &mockNotifier{}
+	service := NewCleanupService(repo, &mockCleanupResultRepository{}, notifier, &mockMetricsCollector{},
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host", NodeName: "test-node"}}, zap.NewNop())
+
+	if err := service.Cleanup(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(notifier.reports()) != 1 {
+		t.Fatalf("expected one report, got %d", len(notifier.reports()))
+	}
+	report := notifier.reports()[0]
+	if report.Total != 3 || report.Removed != 2 || report.Skipped != 1 || report.Failed != 0 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if report.ReclaimedBytes != 5120 {
+		t.Errorf("expected 5120 reclaimed bytes, got %d", report.ReclaimedBytes)
+	}
+	if report.Host.NodeName != "test-node" || report.Message == "" {
+		t.Errorf("expected host identity and a text fallback, got %+v", report)
+	}
+
+	// Image xóa lỗi được tính vào skipped và failed, không tính dung lượng
+	repo.removeErr = fmt.Errorf("image is locked")
+	if err := service.Cleanup(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report = notifier.reports()[1]
+	if report.Removed != 0 || report.Skipped != 3 || report.Failed != 2 || report.ReclaimedBytes != 0 {
+		t.Errorf("unexpected counts with removal failures: %+v", report)
+	}
+}
+
+func TestCleanupServiceEmitsEvents(t *testing.T) {
+	repo := &mockImageRepository{
+		images: []models.Image{
+			{ID: "1", Tags: []string{"app:v1"}, Size: 1024},
+			{ID: "2", Tags: []string{"app:v2"}, Size: 2048},
+			{ID: "3", Size: 4096},
+		},
+		usedImages: map[string]bool{"2": true},
+	}
+	notifier := &mockNotifier{}
+	metrics := &mockMetricsCollector{}
+	service := NewCleanupService(repo, &mockCleanupResultRepository{}, notifier, metrics,
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host"}}, zap.NewNop())
+	service.SetDiskPressureCheck(&mockUsageReader{usage: disk.Usage{UsedBytes: 90, TotalBytes: 100}}, "/var/lib/containerd", 85)
+
+	if err := service.Cleanup(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[notification.EventType]int{
+		notification.EventRunStarted:   1,
+		notification.EventImageDeleted: 2,
+		notification.EventRunCompleted: 1,
+		notification.EventDiskPressure: 1,
+		notification.EventRunFailed:    0,
+	}
+	for eventType, count := range want {
+		if got := notifier.count(eventType); got != count {
+			t.Errorf("expected %d %s events, got %d", count, eventType, got)
+		}
+	}
+	if first := notifier.events[0]; first.Type() != notification.EventRunStarted {
+		t.Errorf("expected run started first, got %s", first.Type())
+	}
+	pressure, ok := notifier.events[len(notifier.events)-1].(notification.DiskPressure)
+	if !ok || pressure.Path != "/var/lib/containerd" || pressure.UsedPercent != 90 || pressure.ThresholdPercent != 85 {
+		t.Errorf("expected disk pressure last, got %+v", notifier.events[len(notifier.events)-1])
+	}
+	if pressure.Host.Hostname != "test-host" {
+		t.Errorf("expected the event to carry the host, got %+v", pressure.Host)
+	}
+
+	// Dưới ngưỡng thì không cảnh báo
+	service.SetDiskPressureCheck(&mockUsageReader{usage: disk.Usage{UsedBytes: 50, TotalBytes: 100}}, "/var/lib/containerd", 85)
+	if err := service.Cleanup(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := notifier.count(notification.EventDiskPressure); got != 1 {
+		t.Errorf("expected no disk pressure below the threshold, got %d events", got)
+	}
+	if metrics.cleanupErrors != 0 {
+		t.Errorf("expected no errors, got %d", metrics.cleanupErrors)
+	}
+}
+
+// countingImageRepository đếm số image đã xóa
+type countingImageRepository struct {
+	*mockImageRepository
+	mu      sync.Mutex
+	removed int
+}
+
+func (m *countingImageRepository) RemoveImage(ctx context.Context, imageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removed++
+	return nil
+}
+
+// removalObserver ghi lại số image đã xóa tại thời điểm nhận mỗi ImageDeleted
+type removalObserver struct {
+	mockNotifier
+	repo *countingImageRepository
+	seen []int
+}
+
+func (m *removalObserver) Notify(ctx context.Context, event notification.Event) error {
+	if event.Type() == notification.EventImageDeleted {
+		m.repo.mu.Lock()
+		m.seen = append(m.seen, m.repo.removed)
+		m.repo.mu.Unlock()
+	}
+	return m.mockNotifier.Notify(ctx, event)
+}
+
+func TestCleanupServiceNotifiesDeletedImagesAfterRemoval(t *testing.T) {
+	var images []models.Image
+	for i := 0; i < 12; i++ {
+		images = append(images, models.Image{ID: fmt.Sprintf("img-%d", i), Size: 1024})
+	}
+	repo := &countingImageRepository{mockImageRepository: &mockImageRepository{images: images, usedImages: map[string]bool{}}}
+	notifier := &removalObserver{repo: repo}
+	service := NewCleanupService(repo, &mockCleanupResultRepository{}, notifier, &mockMetricsCollector{},
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host"}}, zap.NewNop())
+
+	if err := service.Cleanup(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Worker không chờ notifier: mọi image đã được xóa trước khi gửi ImageDeleted đầu tiên
+	if len(notifier.seen) != len(images) {
+		t.Fatalf("expected %d image deleted events, got %d", len(images), len(notifier.seen))
+	}
+	for _, removed := range notifier.seen {
+		if removed != len(images) {
+			t.Fatalf("expected image deleted events after every removal, got one after %d removals", removed)
+		}
+	}
+}
+
+// partialRemovalRepository không xóa được các image trong failIDs
+type partialRemovalRepository struct {
+	*mockImageRepository
+	failIDs map[string]bool
+}
+
+func (m *partialRemovalRepository) RemoveImage(ctx context.Context, imageID string) error {
+	if m.failIDs[imageID] {
+		return fmt.Errorf("image %s is locked", imageID)
+	}
+	return m.mockImageRepository.RemoveImage(ctx, imageID)
+}
+
+func TestCleanupServiceRecordsImageRemovals(t *testing.T) {
+	repo := &partialRemovalRepository{
+		mockImageRepository: &mockImageRepository{
+			images: []models.Image{
+				{ID: "img-ok", Tags: []string{"app:1"}, Size: 2048},
+				{ID: "img-locked", Tags: []string{"app:2"}, Size: 4096},
+				{ID: "img-used", Tags: []string{"app:3"}, Size: 8192},
+			},
+			usedImages: map[string]bool{"img-used": true},
+		},
+		failIDs: map[string]bool{"img-locked": true},
+	}
+	resultRepo := &mockCleanupResultRepository{}
+	service := NewCleanupService(repo, resultRepo, &mockNotifier{}, &mockMetricsCollector{},
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host"}}, zap.NewNop())
+
+	ctx := helper.WithRequestID(context.Background(), "req-42")
+	if err := service.Cleanup(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resultRepo.savedResults) != 1 || resultRepo.savedResults[0].ID == "" {
+		t.Fatalf("expected one saved result with an ID, got %+v", resultRepo.savedResults)
+	}
+	runID := resultRepo.savedResults[0].ID
+
+	// Image đang dùng không bị xóa nên không có sự kiện
+	outcomes := map[string]repositories.ImageRemoval{}
+	for _, removal := range resultRepo.savedRemovals {
+		if removal.RunID != runID || removal.RequestID != "req-42" || removal.Time.IsZero() {
+			t.Errorf("expected removal of run %s with request ID and time, got %+v", runID, removal)
+		}
+		outcomes[removal.ImageID] = removal
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("expected removals for 2 images, got %+v", resultRepo.savedRemovals)
+	}
+	if ok := outcomes["img-ok"]; ok.Outcome != repositories.RemovalOutcomeRemoved || ok.SizeBytes != 2048 || ok.Tags[0] != "app:1" {
+		t.Errorf("unexpected removal for img-ok: %+v", ok)
+	}
+	if locked := outcomes["img-locked"]; locked.Outcome != repositories.RemovalOutcomeFailed || !strings.Contains(locked.Error, "locked") {
+		t.Errorf("unexpected removal for img-locked: %+v", locked)
+	}
+}
+
+func TestCleanupServiceLastStatsOfThisNode(t *testing.T) {
+	resultRepo := &mockCleanupResultRepository{savedResults: []repositories.CleanupResult{
+		{ID: "a", Host: models.Host{NodeName: "node-a"}, TotalCount: 4, Removed: 3},
+		{ID: "b", Host: models.Host{NodeName: "node-b"}, TotalCount: 9, Removed: 9},
+	}}
+	service := NewCleanupService(&mockImageRepository{}, resultRepo, &mockNotifier{}, &mockMetricsCollector{},
+		&mockHostIdentifier{host: models.Host{Hostname: "host-a", NodeName: "node-a"}}, zap.NewNop())
+
+	// node-b chạy sau nhưng không phải node này
+	stats, err := service.GetLastCleanupStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Host.NodeName != "node-a" || stats.TotalCount != 4 || stats.Removed != 3 {
+		t.Errorf("expected the last run of node-a, got %+v", stats)
+	}
+}
+
+func TestCleanupServiceReportsFailedRun(t *testing.T) {
+	repo := &mockImageRepository{}
+	notifier := &mockNotifier{}
+	metrics := &mockMetricsCollector{}
+	resultRepo := &mockCleanupResultRepository{}
+	service := NewCleanupService(&failingImageRepository{repo}, resultRepo, notifier, metrics,
+		&mockHostIdentifier{host: models.Host{Hostname: "test-host"}}, zap.NewNop())
+
+	if err := service.Cleanup(context.Background()); err == nil {
+		t.Fatal("expected an error when images cannot be listed")
+	}
+
+	if metrics.cleanupErrors != 1 {
+		t.Errorf("expected 1 error, got %d", metrics.cleanupErrors)
+	}
+	if notifier.count(notification.EventRunFailed) != 1 || len(notifier.reports()) != 0 {
+		t.Fatalf("expected a run failed event and no report, got %v", notifier.events)
+	}
+	failed := notifier.events[len(notifier.events)-1].(notification.RunFailed)
+	if !strings.Contains(failed.Error, "runtime unavailable") || failed.Host.Hostname != "test-host" {
+		t.Errorf("unexpected run failed event: %+v", failed)
+	}
+	if len(resultRepo.savedResults) != 0 {
+		t.Errorf("expected no saved result, got %d", len(resultRepo.savedResults))
+	}
+}
@@ -0,0 +1,8 @@
+package digest
+
+import "context"
+
+type DigestUseCase interface {
+	// SendDigest tổng hợp các lần cleanup của node trong chu kỳ vừa qua và gửi đi
+	SendDigest(ctx context.Context) error
+}
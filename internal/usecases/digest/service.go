@@ -0,0 +1,90 @@
+// internal/usecases/digest/service.go
+package digest
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/host"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxDigestResults giới hạn số lần chạy được liệt kê chi tiết trong một bản tổng hợp
+const maxDigestResults = 50
+
+// Verify that DigestService implements DigestUseCase
+var _ DigestUseCase = (*DigestService)(nil)
+
+type DigestService struct {
+	repo     repositories.CleanupResultRepository
+	notifier notification.DigestNotifier
+	hosts    host.Identifier
+	period   time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+func NewDigestService(
+	repo repositories.CleanupResultRepository,
+	notifier notification.DigestNotifier,
+	hosts host.Identifier,
+	period time.Duration,
+	logger *zap.Logger,
+) *DigestService {
+	return &DigestService{
+		repo:     repo,
+		notifier: notifier,
+		hosts:    hosts,
+		period:   period,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (s *DigestService) SendDigest(ctx context.Context) error {
+	to := s.now()
+	digest := notification.Digest{From: to.Add(-s.period), To: to}
+
+	host, err := s.hosts.Identify(ctx)
+	if err != nil {
+		s.logger.Warn("Host identity is incomplete", zap.Error(err))
+	}
+	digest.Host = host
+
+	// Chỉ lấy kết quả của node này, để các node dùng chung PostgreSQL không gửi trùng lặp
+	query := repositories.ResultQuery{From: digest.From, To: digest.To, NodeName: host.NodeName}
+	err = s.repo.StreamResults(ctx, query, func(result repositories.CleanupResult) error {
+		digest.Runs++
+		digest.Total += result.TotalCount
+		digest.Removed += result.Removed
+		digest.Skipped += result.Skipped
+		digest.TotalDuration += result.Duration
+		if result.Duration > digest.LongestRun {
+			digest.LongestRun = result.Duration
+		}
+
+		// Kết quả đến theo thứ tự thời gian tăng dần nên giữ lại các lần chạy gần nhất
+		if len(digest.Results) == maxDigestResults {
+			digest.Results = append(digest.Results[:0], digest.Results[1:]...)
+		}
+		digest.Results = append(digest.Results, result)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read cleanup results: %w", err)
+	}
+
+	if err := s.notifier.SendDigest(digest); err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+
+	s.logger.Info("Cleanup digest sent",
+		zap.Time("from", digest.From),
+		zap.Time("to", digest.To),
+		zap.Int("runs", digest.Runs),
+		zap.Int("removed", digest.Removed))
+	return nil
+}
@@ -0,0 +1,97 @@
+package digest
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// mockResultRepository chỉ cần StreamResults cho bản tổng hợp
+type mockResultRepository struct {
+	repositories.CleanupResultRepository
+	results []repositories.CleanupResult
+	query   repositories.ResultQuery
+}
+
+func (m *mockResultRepository) StreamResults(ctx context.Context, query repositories.ResultQuery, fn func(repositories.CleanupResult) error) error {
+	m.query = query
+	for _, result := range m.results {
+		if result.StartTime.Before(query.From) || !result.StartTime.Before(query.To) {
+			continue
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mockDigestNotifier struct {
+	digests []notification.Digest
+}
+
+func (m *mockDigestNotifier) SendDigest(digest notification.Digest) error {
+	m.digests = append(m.digests, digest)
+	return nil
+}
+
+type mockHostIdentifier struct{}
+
+func (mockHostIdentifier) Identify(ctx context.Context) (models.Host, error) {
+	return models.Host{Hostname: "host-1", NodeName: "node-1"}, nil
+}
+
+func TestDigestServiceSendDigest(t *testing.T) {
+	now := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	repo := &mockResultRepository{}
+	for i := 0; i < maxDigestResults+5; i++ {
+		repo.results = append(repo.results, repositories.CleanupResult{
+			ID:         string(rune('a' + i%26)),
+			StartTime:  now.Add(-time.Duration(maxDigestResults+5-i) * time.Hour),
+			Duration:   time.Duration(i+1) * time.Second,
+			TotalCount: 3,
+			Removed:    2,
+			Skipped:    1,
+		})
+	}
+	// Kết quả cũ hơn khoảng tổng hợp không được tính
+	repo.results = append([]repositories.CleanupResult{{StartTime: now.Add(-30 * 24 * time.Hour), Removed: 100}}, repo.results...)
+
+	notifier := &mockDigestNotifier{}
+	service := NewDigestService(repo, notifier, mockHostIdentifier{}, 7*24*time.Hour, zap.NewNop())
+	service.now = func() time.Time { return now }
+
+	if err := service.SendDigest(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.query.NodeName != "node-1" {
+		t.Errorf("expected results to be filtered by node, got %+v", repo.query)
+	}
+	if len(notifier.digests) != 1 {
+		t.Fatalf("expected one digest, got %d", len(notifier.digests))
+	}
+
+	digest := notifier.digests[0]
+	runs := maxDigestResults + 5
+	if digest.Runs != runs || digest.Removed != 2*runs || digest.Skipped != runs || digest.Total != 3*runs {
+		t.Errorf("unexpected totals: %+v", digest)
+	}
+	if digest.LongestRun != time.Duration(runs)*time.Second {
+		t.Errorf("expected longest run %v, got %v", time.Duration(runs)*time.Second, digest.LongestRun)
+	}
+	if len(digest.Results) != maxDigestResults {
+		t.Fatalf("expected %d listed results, got %d", maxDigestResults, len(digest.Results))
+	}
+	if last := digest.Results[len(digest.Results)-1]; last.Duration != time.Duration(runs)*time.Second {
+		t.Errorf("expected the most recent run last, got %+v", last)
+	}
+	if !digest.From.Equal(now.Add(-7*24*time.Hour)) || !digest.To.Equal(now) || digest.Host.NodeName != "node-1" {
+		t.Errorf("unexpected period or host: %v - %v %+v", digest.From, digest.To, digest.Host)
+	}
+}
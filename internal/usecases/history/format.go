@@ -0,0 +1,321 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/pkg/helper"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format là định dạng export lịch sử cleanup
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat chuyển chuỗi (không phân biệt hoa thường) thành Format, mặc định là JSON
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON, "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (expected csv, json or ndjson)", value)
+	}
+}
+
+// ExportType chọn dữ liệu được export: tổng kết từng lần chạy hoặc sự kiện xóa từng image
+type ExportType string
+
+const (
+	ExportRuns     ExportType = "runs"
+	ExportRemovals ExportType = "removals"
+)
+
+// ParseExportType chuyển chuỗi (không phân biệt hoa thường) thành ExportType, mặc định là runs
+func ParseExportType(value string) (ExportType, error) {
+	switch ExportType(strings.ToLower(strings.TrimSpace(value))) {
+	case "", ExportRuns:
+		return ExportRuns, nil
+	case ExportRemovals:
+		return ExportRemovals, nil
+	default:
+		return "", fmt.Errorf("unsupported export type %q (expected runs or removals)", value)
+	}
+}
+
+// ContentType trả về MIME type tương ứng
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// Extension trả về phần mở rộng file tương ứng
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Record là một dòng export, thời gian theo RFC3339 UTC để dễ lưu trữ lâu dài
+type Record struct {
+	ID             string            `json:"id"`
+	HostInfo       string            `json:"host_info"`
+	Hostname       string            `json:"hostname"`
+	NodeName       string            `json:"node_name"`
+	MachineID      string            `json:"machine_id"`
+	RuntimeVersion string            `json:"runtime_version"`
+	IPv4           []string          `json:"ipv4"`
+	IPv6           []string          `json:"ipv6"`
+	Labels         map[string]string `json:"labels"`
+	StartTime      string            `json:"start_time"`
+	EndTime        string            `json:"end_time"`
+	DurationMs     int64             `json:"duration_ms"`
+	TotalCount     int               `json:"total_count"`
+	Removed        int               `json:"removed"`
+	Skipped        int               `json:"skipped"`
+	CreatedAt      string            `json:"created_at"`
+	RequestID      string            `json:"request_id"`
+}
+
+var csvHeader = []string{
+	"id", "host_info", "hostname", "node_name", "machine_id", "runtime_version",
+	"ipv4", "ipv6", "labels", "start_time", "end_time", "duration_ms",
+	"total_count", "removed", "skipped", "created_at", "request_id",
+}
+
+func newRecord(result repositories.CleanupResult) Record {
+	return Record{
+		ID:             result.ID,
+		HostInfo:       result.HostInfo,
+		Hostname:       result.Host.Hostname,
+		NodeName:       result.Host.NodeName,
+		MachineID:      result.Host.MachineID,
+		RuntimeVersion: result.Host.RuntimeVersion,
+		IPv4:           result.Host.IPv4,
+		IPv6:           result.Host.IPv6,
+		Labels:         result.Host.Labels,
+		StartTime:      result.StartTime.UTC().Format(time.RFC3339),
+		EndTime:        result.EndTime.UTC().Format(time.RFC3339),
+		DurationMs:     result.Duration.Milliseconds(),
+		TotalCount:     result.TotalCount,
+		Removed:        result.Removed,
+		Skipped:        result.Skipped,
+		CreatedAt:      result.CreatedAt.UTC().Format(time.RFC3339),
+		RequestID:      result.RequestID,
+	}
+}
+
+// RemovalRecord là một dòng export sự kiện xóa image, gắn với lần chạy qua run_id
+type RemovalRecord struct {
+	RunID     string   `json:"run_id"`
+	RequestID string   `json:"request_id"`
+	Hostname  string   `json:"hostname"`
+	NodeName  string   `json:"node_name"`
+	ImageID   string   `json:"image_id"`
+	Tags      []string `json:"tags"`
+	SizeBytes uint64   `json:"size_bytes"`
+	Outcome   string   `json:"outcome"`
+	Error     string   `json:"error"`
+	Time      string   `json:"time"`
+}
+
+var removalCSVHeader = []string{
+	"run_id", "request_id", "hostname", "node_name", "image_id", "tags",
+	"size_bytes", "outcome", "error", "time",
+}
+
+func newRemovalRecord(removal repositories.ImageRemoval) RemovalRecord {
+	tags := removal.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return RemovalRecord{
+		RunID:     removal.RunID,
+		RequestID: removal.RequestID,
+		Hostname:  removal.Hostname,
+		NodeName:  removal.NodeName,
+		ImageID:   removal.ImageID,
+		Tags:      tags,
+		SizeBytes: removal.SizeBytes,
+		Outcome:   removal.Outcome,
+		Error:     removal.Error,
+		Time:      removal.Time.UTC().Format(time.RFC3339),
+	}
+}
+
+func (r RemovalRecord) csvRow() []string {
+	return []string{
+		r.RunID,
+		r.RequestID,
+		r.Hostname,
+		r.NodeName,
+		r.ImageID,
+		strings.Join(r.Tags, " "),
+		strconv.FormatUint(r.SizeBytes, 10),
+		r.Outcome,
+		r.Error,
+		r.Time,
+	}
+}
+
+func (r Record) csvRow() []string {
+	return []string{
+		r.ID,
+		r.HostInfo,
+		r.Hostname,
+		r.NodeName,
+		r.MachineID,
+		r.RuntimeVersion,
+		strings.Join(r.IPv4, " "),
+		strings.Join(r.IPv6, " "),
+		helper.FormatLabels(r.Labels),
+		r.StartTime,
+		r.EndTime,
+		strconv.FormatInt(r.DurationMs, 10),
+		strconv.Itoa(r.TotalCount),
+		strconv.Itoa(r.Removed),
+		strconv.Itoa(r.Skipped),
+		r.CreatedAt,
+		r.RequestID,
+	}
+}
+
+// exportRecord là một dòng export: Record hoặc RemovalRecord
+type exportRecord interface {
+	csvRow() []string
+}
+
+// recordEncoder ghi từng record ra writer mà không giữ lại dữ liệu trước đó.
+// Abort thay cho End khi export lỗi giữa chừng, để file bị cắt cụt không trông như một export hoàn chỉnh.
+type recordEncoder interface {
+	Begin() error
+	Encode(record exportRecord) error
+	End() error
+	Abort(err error) error
+}
+
+// exportError là marker được ghi ở cuối export bị lỗi
+type exportError struct {
+	Error string `json:"error"`
+}
+
+// newEncoder tạo encoder cho format; header là dòng đầu của CSV
+func newEncoder(w io.Writer, format Format, header []string) recordEncoder {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w), header: header}
+	case FormatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}
+	default:
+		return &jsonArrayEncoder{w: w}
+	}
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header []string
+}
+
+func (e *csvEncoder) Begin() error {
+	return e.w.Write(e.header)
+}
+
+func (e *csvEncoder) Encode(record exportRecord) error {
+	if err := e.w.Write(record.csvRow()); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// Abort ghi dòng "error,<lỗi>"; số cột khác header nên CSV reader chặt chẽ cũng báo lỗi
+func (e *csvEncoder) Abort(err error) error {
+	if werr := e.w.Write([]string{"error", err.Error()}); werr != nil {
+		return werr
+	}
+	return e.End()
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Begin() error { return nil }
+
+func (e *ndjsonEncoder) Encode(record exportRecord) error {
+	return e.enc.Encode(record)
+}
+
+func (e *ndjsonEncoder) End() error { return nil }
+
+// Abort ghi một dòng {"error": ...} cuối cùng
+func (e *ndjsonEncoder) Abort(err error) error {
+	return e.enc.Encode(exportError{Error: err.Error()})
+}
+
+// jsonArrayEncoder ghi mảng JSON từng phần tử để không phải giữ toàn bộ mảng trong bộ nhớ
+type jsonArrayEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonArrayEncoder) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonArrayEncoder) Encode(record exportRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonArrayEncoder) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// Abort ghi thêm phần tử {"error": ...} và không đóng mảng, để mọi JSON parser đều báo lỗi
+func (e *jsonArrayEncoder) Abort(err error) error {
+	data, merr := json.Marshal(exportError{Error: err.Error()})
+	if merr != nil {
+		return merr
+	}
+	if e.count > 0 {
+		if _, werr := io.WriteString(e.w, ","); werr != nil {
+			return werr
+		}
+	}
+	_, werr := e.w.Write(data)
+	return werr
+}
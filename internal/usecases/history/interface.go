@@ -0,0 +1,20 @@
+package history
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/repositories"
+	"io"
+)
+
+type HistoryUseCase interface {
+	// ListResults trả về các lần cleanup khớp query, mới nhất trước
+	ListResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error)
+
+	// Export ghi các lần cleanup trong khoảng thời gian của query ra w theo format,
+	// trả về số bản ghi đã ghi
+	Export(ctx context.Context, w io.Writer, format Format, query repositories.ResultQuery) (int, error)
+
+	// ExportRemovals ghi sự kiện xóa từng image của các lần cleanup khớp query ra w theo format,
+	// trả về số sự kiện đã ghi
+	ExportRemovals(ctx context.Context, w io.Writer, format Format, query repositories.ResultQuery) (int, error)
+}
@@ -0,0 +1,98 @@
+// internal/usecases/history/service.go
+package history
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/repositories"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Verify that HistoryService implements HistoryUseCase
+var _ HistoryUseCase = (*HistoryService)(nil)
+
+type HistoryService struct {
+	resultRepo repositories.CleanupResultRepository
+	logger     *zap.Logger
+}
+
+func NewHistoryService(resultRepo repositories.CleanupResultRepository, logger *zap.Logger) *HistoryService {
+	return &HistoryService{
+		resultRepo: resultRepo,
+		logger:     logger,
+	}
+}
+
+func (s *HistoryService) ListResults(ctx context.Context, query repositories.ResultQuery, limit, offset int) ([]repositories.CleanupResult, error) {
+	results, err := s.resultRepo.GetResults(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cleanup results: %w", err)
+	}
+	return results, nil
+}
+
+func (s *HistoryService) Export(ctx context.Context, w io.Writer, format Format, query repositories.ResultQuery) (int, error) {
+	return s.export(w, format, query, csvHeader, "cleanup results", func(fn func(exportRecord) error) error {
+		return s.resultRepo.StreamResults(ctx, query, func(result repositories.CleanupResult) error {
+			return fn(newRecord(result))
+		})
+	})
+}
+
+func (s *HistoryService) ExportRemovals(ctx context.Context, w io.Writer, format Format, query repositories.ResultQuery) (int, error) {
+	return s.export(w, format, query, removalCSVHeader, "image removals", func(fn func(exportRecord) error) error {
+		return s.resultRepo.StreamRemovals(ctx, query, func(removal repositories.ImageRemoval) error {
+			return fn(newRemovalRecord(removal))
+		})
+	})
+}
+
+// export ghi các record do stream trả về qua encoder của format; what là tên dữ liệu dùng trong lỗi và log
+func (s *HistoryService) export(w io.Writer, format Format, query repositories.ResultQuery, header []string, what string, stream func(fn func(exportRecord) error) error) (int, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return 0, fmt.Errorf("invalid time range: from %s is not before to %s",
+			query.From.Format(time.RFC3339), query.To.Format(time.RFC3339))
+	}
+
+	startTime := time.Now()
+	encoder := newEncoder(w, format, header)
+
+	if err := encoder.Begin(); err != nil {
+		return 0, fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	count := 0
+	err := stream(func(record exportRecord) error {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write export record: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to export %s: %w", what, err)
+		if abortErr := encoder.Abort(err); abortErr != nil {
+			s.logger.Warn("Failed to write export error marker", zap.Error(abortErr))
+		}
+		return count, err
+	}
+
+	if err := encoder.End(); err != nil {
+		return count, fmt.Errorf("failed to finish export: %w", err)
+	}
+
+	s.logger.Info("Cleanup history exported",
+		zap.String("type", what),
+		zap.String("format", string(format)),
+		zap.Time("from", query.From),
+		zap.Time("to", query.To),
+		zap.String("hostname", query.Hostname),
+		zap.String("node_name", query.NodeName),
+		zap.Int("records", count),
+		zap.String("duration", time.Since(startTime).String()))
+
+	return count, nil
+}
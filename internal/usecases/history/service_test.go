@@ -0,0 +1,273 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// mockResultRepository chỉ cần StreamResults/StreamRemovals cho export; streamErr được trả về sau khi đã gửi hết dữ liệu
+type mockResultRepository struct {
+	repositories.CleanupResultRepository
+	results   []repositories.CleanupResult
+	removals  []repositories.ImageRemoval
+	streamErr error
+}
+
+func (m *mockResultRepository) StreamRemovals(ctx context.Context, query repositories.ResultQuery, fn func(repositories.ImageRemoval) error) error {
+	for _, removal := range m.removals {
+		if query.NodeName != "" && removal.NodeName != query.NodeName {
+			continue
+		}
+		if err := fn(removal); err != nil {
+			return err
+		}
+	}
+	return m.streamErr
+}
+
+func (m *mockResultRepository) StreamResults(ctx context.Context, query repositories.ResultQuery, fn func(repositories.CleanupResult) error) error {
+	for _, result := range m.results {
+		if !query.From.IsZero() && result.StartTime.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && !result.StartTime.Before(query.To) {
+			continue
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	return m.streamErr
+}
+
+func TestHistoryServiceExport(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockResultRepository{results: []repositories.CleanupResult{
+		{ID: "a", HostInfo: "Host: node-1\nIP(s): 10.0.0.1", Host: models.Host{Hostname: "node-1", Labels: map[string]string{"env": "prod"}}, StartTime: base, EndTime: base.Add(time.Minute), Duration: time.Minute, TotalCount: 3, Removed: 2, Skipped: 1},
+		{ID: "b", HostInfo: "Host: node-1\nIP(s): 10.0.0.1", StartTime: base.Add(24 * time.Hour), EndTime: base.Add(24*time.Hour + time.Second), Duration: time.Second, TotalCount: 1, Removed: 0, Skipped: 1},
+		{ID: "c", HostInfo: "Host: node-1\nIP(s): 10.0.0.1", StartTime: base.Add(48 * time.Hour), EndTime: base.Add(48 * time.Hour), TotalCount: 0},
+	}}
+	service := NewHistoryService(repo, zap.NewNop())
+	query := repositories.ResultQuery{From: base, To: base.Add(48 * time.Hour)}
+
+	tests := []struct {
+		name   string
+		format Format
+		check  func(t *testing.T, output string)
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			check: func(t *testing.T, output string) {
+				rows, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+				if err != nil {
+					t.Fatalf("invalid csv: %v", err)
+				}
+				if len(rows) != 3 {
+					t.Fatalf("expected header and 2 rows, got %d rows", len(rows))
+				}
+				if rows[0][0] != "id" || rows[1][0] != "a" || rows[2][0] != "b" {
+					t.Errorf("unexpected rows: %v", rows)
+				}
+				if rows[1][11] != "60000" {
+					t.Errorf("expected duration_ms 60000, got %s", rows[1][11])
+				}
+				if rows[1][2] != "node-1" || rows[1][8] != "env=prod" {
+					t.Errorf("expected host columns node-1/env=prod, got %s/%s", rows[1][2], rows[1][8])
+				}
+			},
+		},
+		{
+			name:   "json array",
+			format: FormatJSON,
+			check: func(t *testing.T, output string) {
+				var records []Record
+				if err := json.Unmarshal([]byte(output), &records); err != nil {
+					t.Fatalf("invalid json: %v", err)
+				}
+				if len(records) != 2 || records[0].ID != "a" || records[1].ID != "b" {
+					t.Errorf("unexpected records: %+v", records)
+				}
+			},
+		},
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			check: func(t *testing.T, output string) {
+				lines := strings.Split(strings.TrimSpace(output), "\n")
+				if len(lines) != 2 {
+					t.Fatalf("expected 2 lines, got %d", len(lines))
+				}
+				var record Record
+				if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+					t.Fatalf("invalid ndjson line: %v", err)
+				}
+				if record.ID != "b" || record.StartTime != "2025-01-02T00:00:00Z" {
+					t.Errorf("unexpected record: %+v", record)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := service.Export(context.Background(), &buf, tt.format, query)
+			if err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("expected 2 records, got %d", count)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestHistoryServiceExportRemovals(t *testing.T) {
+	removedAt := time.Date(2025, 1, 1, 0, 0, 30, 0, time.UTC)
+	repo := &mockResultRepository{removals: []repositories.ImageRemoval{
+		{RunID: "a", RequestID: "req-1", NodeName: "node-1", ImageID: "sha256:1", Tags: []string{"app:1", "app:latest"}, SizeBytes: 2048, Outcome: repositories.RemovalOutcomeRemoved, Time: removedAt},
+		{RunID: "a", RequestID: "req-1", NodeName: "node-1", ImageID: "sha256:2", SizeBytes: 4096, Outcome: repositories.RemovalOutcomeFailed, Error: "image is locked", Time: removedAt},
+		{RunID: "b", NodeName: "node-2", ImageID: "sha256:3", SizeBytes: 1024, Outcome: repositories.RemovalOutcomeRemoved, Time: removedAt},
+	}}
+	service := NewHistoryService(repo, zap.NewNop())
+	query := repositories.ResultQuery{NodeName: "node-1"}
+
+	tests := []struct {
+		name   string
+		format Format
+		check  func(t *testing.T, output string)
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			check: func(t *testing.T, output string) {
+				rows, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+				if err != nil {
+					t.Fatalf("invalid csv: %v", err)
+				}
+				if len(rows) != 3 || rows[0][0] != "run_id" || rows[0][4] != "image_id" {
+					t.Fatalf("expected header and 2 rows, got %v", rows)
+				}
+				if rows[1][5] != "app:1 app:latest" || rows[1][6] != "2048" || rows[1][9] != "2025-01-01T00:00:30Z" {
+					t.Errorf("unexpected removed row: %v", rows[1])
+				}
+				if rows[2][7] != repositories.RemovalOutcomeFailed || rows[2][8] != "image is locked" {
+					t.Errorf("unexpected failed row: %v", rows[2])
+				}
+			},
+		},
+		{
+			name:   "json array",
+			format: FormatJSON,
+			check: func(t *testing.T, output string) {
+				var records []RemovalRecord
+				if err := json.Unmarshal([]byte(output), &records); err != nil {
+					t.Fatalf("invalid json: %v", err)
+				}
+				if len(records) != 2 || records[0].ImageID != "sha256:1" || records[1].Tags == nil {
+					t.Errorf("unexpected records: %+v", records)
+				}
+			},
+		},
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			check: func(t *testing.T, output string) {
+				lines := strings.Split(strings.TrimSpace(output), "\n")
+				if len(lines) != 2 {
+					t.Fatalf("expected 2 lines, got %d", len(lines))
+				}
+				var record RemovalRecord
+				if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+					t.Fatalf("invalid ndjson line: %v", err)
+				}
+				if record.RunID != "a" || record.RequestID != "req-1" || record.Outcome != repositories.RemovalOutcomeRemoved {
+					t.Errorf("unexpected record: %+v", record)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := service.ExportRemovals(context.Background(), &buf, tt.format, query)
+			if err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("expected 2 records, got %d", count)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestHistoryServiceExportEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	service := NewHistoryService(&mockResultRepository{}, zap.NewNop())
+
+	if _, err := service.Export(context.Background(), &buf, FormatJSON, repositories.ResultQuery{}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected empty array, got %q", buf.String())
+	}
+}
+
+func TestHistoryServiceExportMarksFailure(t *testing.T) {
+	repo := &mockResultRepository{
+		results:   []repositories.CleanupResult{{ID: "a", StartTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		streamErr: errors.New("connection reset"),
+	}
+	service := NewHistoryService(repo, zap.NewNop())
+
+	tests := []struct {
+		format Format
+		check  func(t *testing.T, output string)
+	}{
+		{FormatNDJSON, func(t *testing.T, output string) {
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			var marker struct {
+				Error string `json:"error"`
+			}
+			if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &marker) != nil || !strings.Contains(marker.Error, "connection reset") {
+				t.Errorf("expected a trailing error object, got %q", output)
+			}
+		}},
+		{FormatJSON, func(t *testing.T, output string) {
+			var records []json.RawMessage
+			if json.Unmarshal([]byte(output), &records) == nil || !strings.Contains(output, `{"error":`) {
+				t.Errorf("expected an unterminated array with the error, got %q", output)
+			}
+		}},
+		{FormatCSV, func(t *testing.T, output string) {
+			if _, err := csv.NewReader(strings.NewReader(output)).ReadAll(); err == nil || !strings.Contains(output, "error,") {
+				t.Errorf("expected an error row that fails strict parsing, got %q", output)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := service.Export(context.Background(), &buf, tt.format, repositories.ResultQuery{})
+			if err == nil || count != 1 {
+				t.Fatalf("expected the stream error after 1 record, got %d, %v", count, err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package maintenance
+
+import "context"
+
+type MaintenanceUseCase interface {
+	// RunMaintenance áp dụng retention policy và tối ưu database
+	RunMaintenance(ctx context.Context) error
+}
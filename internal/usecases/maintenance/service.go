@@ -0,0 +1,69 @@
+// internal/usecases/maintenance/service.go
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/repositories"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Verify that MaintenanceService implements MaintenanceUseCase
+var _ MaintenanceUseCase = (*MaintenanceService)(nil)
+
+type MaintenanceService struct {
+	repo    repositories.MaintenanceRepository
+	policy  repositories.RetentionPolicy
+	metrics metrics.MetricsCollector
+	logger  *zap.Logger
+}
+
+func NewMaintenanceService(
+	repo repositories.MaintenanceRepository,
+	policy repositories.RetentionPolicy,
+	metrics metrics.MetricsCollector,
+	logger *zap.Logger,
+) *MaintenanceService {
+	return &MaintenanceService{
+		repo:    repo,
+		policy:  policy,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+func (s *MaintenanceService) RunMaintenance(ctx context.Context) error {
+	startTime := time.Now()
+
+	var pruned int64
+	if s.policy.Enabled() {
+		var err error
+		pruned, err = s.repo.PruneResults(ctx, s.policy)
+		if err != nil {
+			return fmt.Errorf("failed to prune cleanup results: %w", err)
+		}
+		s.metrics.AddResultsPruned(pruned)
+	}
+
+	if err := s.repo.Optimize(ctx); err != nil {
+		return fmt.Errorf("failed to optimize database: %w", err)
+	}
+
+	size, err := s.repo.DatabaseSize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database size: %w", err)
+	}
+	s.metrics.SetDatabaseSize(size)
+
+	s.logger.Info("Database maintenance completed",
+		zap.Int64("pruned", pruned),
+		zap.Int64("database_size_bytes", size),
+		zap.Duration("max_age", s.policy.MaxAge),
+		zap.Int("max_rows", s.policy.MaxRows),
+		zap.String("duration", time.Since(startTime).String()))
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type mockMaintenanceRepository struct {
+	repositories.MaintenanceRepository
+	pruned      int64
+	size        int64
+	pruneErr    error
+	optimizeErr error
+	sizeErr     error
+
+	policies  []repositories.RetentionPolicy
+	optimized int
+}
+
+func (m *mockMaintenanceRepository) PruneResults(ctx context.Context, policy repositories.RetentionPolicy) (int64, error) {
+	m.policies = append(m.policies, policy)
+	return m.pruned, m.pruneErr
+}
+
+func (m *mockMaintenanceRepository) Optimize(ctx context.Context) error {
+	m.optimized++
+	return m.optimizeErr
+}
+
+func (m *mockMaintenanceRepository) DatabaseSize(ctx context.Context) (int64, error) {
+	return m.size, m.sizeErr
+}
+
+// mockMetrics chỉ ghi lại các metric của bảo trì
+type mockMetrics struct {
+	metrics.MetricsCollector
+	pruned int64
+	size   int64
+}
+
+func (m *mockMetrics) AddResultsPruned(count int64) { m.pruned += count }
+func (m *mockMetrics) SetDatabaseSize(bytes int64)  { m.size = bytes }
+
+func TestMaintenanceServiceRunMaintenance(t *testing.T) {
+	policy := repositories.RetentionPolicy{MaxAge: 90 * 24 * time.Hour, MaxRows: 1000}
+	repo := &mockMaintenanceRepository{pruned: 12, size: 4096}
+	collector := &mockMetrics{}
+	service := NewMaintenanceService(repo, policy, collector, zap.NewNop())
+
+	if err := service.RunMaintenance(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.policies) != 1 || repo.policies[0] != policy {
+		t.Errorf("expected one prune with the policy, got %v", repo.policies)
+	}
+	if repo.optimized != 1 {
+		t.Errorf("expected the database to be optimized once, got %d", repo.optimized)
+	}
+	if collector.pruned != 12 || collector.size != 4096 {
+		t.Errorf("expected pruned=12 size=4096 in metrics, got pruned=%d size=%d", collector.pruned, collector.size)
+	}
+}
+
+func TestMaintenanceServiceWithoutRetention(t *testing.T) {
+	repo := &mockMaintenanceRepository{size: 2048}
+	collector := &mockMetrics{}
+	service := NewMaintenanceService(repo, repositories.RetentionPolicy{}, collector, zap.NewNop())
+
+	if err := service.RunMaintenance(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Không có giới hạn nào thì không xóa gì, nhưng vẫn tối ưu và cập nhật kích thước
+	if len(repo.policies) != 0 {
+		t.Errorf("expected no prune without a retention policy, got %v", repo.policies)
+	}
+	if repo.optimized != 1 || collector.size != 2048 {
+		t.Errorf("expected optimize and the size metric, got optimized=%d size=%d", repo.optimized, collector.size)
+	}
+}
+
+func TestMaintenanceServiceErrors(t *testing.T) {
+	policy := repositories.RetentionPolicy{MaxRows: 10}
+	failure := errors.New("database is locked")
+
+	tests := []struct {
+		name          string
+		repo          *mockMaintenanceRepository
+		wantErr       string
+		wantOptimized int
+	}{
+		{"prune", &mockMaintenanceRepository{pruneErr: failure}, "failed to prune cleanup results", 0},
+		{"optimize", &mockMaintenanceRepository{pruned: 3, optimizeErr: failure}, "failed to optimize database", 1},
+		{"size", &mockMaintenanceRepository{pruned: 3, sizeErr: failure}, "failed to get database size", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &mockMetrics{size: -1}
+			service := NewMaintenanceService(tt.repo, policy, collector, zap.NewNop())
+
+			err := service.RunMaintenance(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, failure) {
+				t.Fatalf("expected %q wrapping the repository error, got %v", tt.wantErr, err)
+			}
+			if tt.repo.optimized != tt.wantOptimized {
+				t.Errorf("expected optimize to be called %d times, got %d", tt.wantOptimized, tt.repo.optimized)
+			}
+			if collector.size != -1 {
+				t.Errorf("expected the size metric to be left alone on error, got %d", collector.size)
+			}
+		})
+	}
+}
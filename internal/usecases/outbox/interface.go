@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/models"
+)
+
+type OutboxUseCase interface {
+	// Stats trả về số thông báo đang chờ gửi lại và số dead letter
+	Stats(ctx context.Context) (models.OutboxStats, error)
+
+	// List trả về các thông báo theo trạng thái (rỗng = mọi trạng thái), mới nhất trước
+	List(ctx context.Context, status string, limit, offset int) ([]models.OutboxMessage, error)
+
+	// Retry đưa một thông báo (thường là dead letter) về pending và gửi lại ngay
+	Retry(ctx context.Context, id string) (*models.OutboxMessage, error)
+
+	// Discard xóa một thông báo khỏi outbox
+	Discard(ctx context.Context, id string) error
+}
@@ -0,0 +1,280 @@
+// internal/usecases/outbox/service.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"go-image-cleanup/pkg/constants"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Verify that OutboxService implements OutboxUseCase and Outbox
+var (
+	_ OutboxUseCase       = (*OutboxService)(nil)
+	_ notification.Outbox = (*OutboxService)(nil)
+)
+
+// batchSize là số thông báo tối đa được gửi lại trong một vòng của worker
+const batchSize = 20
+
+// Nhãn status của image_cleanup_notifications_total, giống các lần gửi trực tiếp
+const (
+	statusSent   = "sent"
+	statusFailed = "failed"
+)
+
+// OutboxService lưu các lần gửi thất bại và gửi lại chúng từ một worker chạy nền,
+// chờ lâu gấp đôi sau mỗi lần thất bại và chuyển thành dead letter khi hết số lần thử
+type OutboxService struct {
+	repo        repositories.OutboxRepository
+	channels    notification.ChannelNotifier
+	metrics     metrics.MetricsCollector
+	logger      *zap.Logger
+	maxAttempts int           // Tính cả lần gửi đầu tiên
+	backoff     time.Duration // Khoảng chờ trước lần gửi lại đầu tiên
+	maxBackoff  time.Duration
+	wake        chan struct{}
+	now         func() time.Time
+}
+
+func NewOutboxService(
+	repo repositories.OutboxRepository,
+	channels notification.ChannelNotifier,
+	metrics metrics.MetricsCollector,
+	maxAttempts int,
+	backoff time.Duration,
+	logger *zap.Logger,
+) *OutboxService {
+	return &OutboxService{
+		repo:        repo,
+		channels:    channels,
+		metrics:     metrics,
+		logger:      logger,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		maxBackoff:  constants.OutboxMaxBackoff,
+		wake:        make(chan struct{}, 1),
+		now:         time.Now,
+	}
+}
+
+// Enqueue lưu một lần gửi thất bại; lần gửi đó được tính là lần thử đầu tiên
+func (s *OutboxService) Enqueue(ctx context.Context, channel, target, deliveryID string, event notification.Event, cause error) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type(), err)
+	}
+	if deliveryID == "" {
+		deliveryID = uuid.NewString()
+	}
+
+	message := models.OutboxMessage{
+		Channel:     channel,
+		Target:      target,
+		DeliveryID:  deliveryID,
+		EventType:   string(event.Type()),
+		Payload:     payload,
+		Status:      models.OutboxPending,
+		Attempts:    1,
+		NextAttempt: s.now().Add(s.delay(1)),
+	}
+	if cause != nil {
+		message.LastError = cause.Error()
+	}
+	if err := s.repo.Enqueue(ctx, message); err != nil {
+		return err
+	}
+
+	s.refreshMetrics(ctx)
+	return nil
+}
+
+// Run gửi lại các thông báo đến lượt cho tới khi ctx bị hủy
+func (s *OutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.OutboxPollInterval)
+	defer ticker.Stop()
+
+	s.refreshMetrics(ctx)
+	for {
+		s.DeliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// DeliverDue gửi lại các thông báo đã đến lượt và trả về số thông báo đã xử lý
+func (s *OutboxService) DeliverDue(ctx context.Context) int {
+	// Thông báo không cập nhật được vẫn đến lượt ở vòng sau, seen tránh gửi lại nó trong cùng một lần
+	seen := make(map[string]bool)
+	for ctx.Err() == nil {
+		messages, err := s.repo.Due(ctx, s.now(), batchSize)
+		if err != nil {
+			s.logger.Error("Failed to read notification outbox", zap.Error(err))
+			break
+		}
+
+		fresh := 0
+		for _, message := range messages {
+			if ctx.Err() != nil || seen[message.ID] {
+				continue
+			}
+			seen[message.ID] = true
+			fresh++
+			s.deliver(ctx, message)
+		}
+		if len(messages) < batchSize || fresh == 0 {
+			break
+		}
+	}
+
+	if len(seen) > 0 {
+		s.refreshMetrics(ctx)
+	}
+	return len(seen)
+}
+
+func (s *OutboxService) deliver(ctx context.Context, message models.OutboxMessage) {
+	log := s.logger.With(
+		zap.String("id", message.ID),
+		zap.String("channel", message.Channel),
+		zap.String("target", message.Target),
+		zap.String("event", message.EventType))
+
+	event, err := notification.DecodeEvent(notification.EventType(message.EventType), message.Payload)
+	if err != nil {
+		// Gửi lại cũng không thể thành công
+		message.Attempts++
+		s.deadLetter(ctx, log, message, err)
+		return
+	}
+
+	// Mọi lần gửi lại mang cùng delivery ID; thông báo lưu trước khi có cột delivery_id dùng ID của nó
+	deliveryID := message.DeliveryID
+	if deliveryID == "" {
+		deliveryID = message.ID
+	}
+	err = s.channels.NotifyChannel(notification.WithDeliveryID(ctx, deliveryID), message.Channel, message.Target, event)
+	if ctx.Err() != nil {
+		// Đang shutdown, lần thử này không được tính
+		return
+	}
+	message.Attempts++
+
+	if err == nil {
+		if err := s.repo.Delete(ctx, message.ID); err != nil {
+			log.Error("Failed to remove delivered notification from outbox", zap.Error(err))
+		}
+		s.metrics.IncNotifications(message.Channel, statusSent)
+		log.Info("Notification delivered from outbox", zap.Int("attempts", message.Attempts))
+		return
+	}
+
+	if message.Attempts >= s.maxAttempts {
+		s.deadLetter(ctx, log, message, err)
+		return
+	}
+
+	message.LastError = err.Error()
+	message.NextAttempt = s.now().Add(s.delay(message.Attempts))
+	message.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, message); err != nil {
+		log.Error("Failed to reschedule notification", zap.Error(err))
+		return
+	}
+	log.Warn("Notification retry failed",
+		zap.Int("attempts", message.Attempts),
+		zap.Time("next_attempt", message.NextAttempt),
+		zap.Error(err))
+}
+
+// deadLetter dừng gửi lại thông báo; nó chỉ được gửi lại khi được yêu cầu qua API
+func (s *OutboxService) deadLetter(ctx context.Context, log *zap.Logger, message models.OutboxMessage, cause error) {
+	message.Status = models.OutboxFailed
+	message.LastError = cause.Error()
+	message.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, message); err != nil {
+		log.Error("Failed to dead-letter notification", zap.Error(err))
+		return
+	}
+	s.metrics.IncNotifications(message.Channel, statusFailed)
+	log.Error("Notification dead-lettered",
+		zap.Int("attempts", message.Attempts),
+		zap.Error(cause))
+}
+
+// delay trả về khoảng chờ sau lần thử thứ attempts: backoff, 2*backoff, 4*backoff, ... tối đa maxBackoff
+func (s *OutboxService) delay(attempts int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < attempts && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.maxBackoff)
+}
+
+func (s *OutboxService) refreshMetrics(ctx context.Context) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to count outbox messages", zap.Error(err))
+		return
+	}
+	s.metrics.SetOutboxMessages(models.OutboxPending, stats.Pending)
+	s.metrics.SetOutboxMessages(models.OutboxFailed, stats.Failed)
+}
+
+func (s *OutboxService) Stats(ctx context.Context) (models.OutboxStats, error) {
+	return s.repo.Stats(ctx)
+}
+
+func (s *OutboxService) List(ctx context.Context, status string, limit, offset int) ([]models.OutboxMessage, error) {
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+func (s *OutboxService) Retry(ctx context.Context, id string) (*models.OutboxMessage, error) {
+	message, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	message.Status = models.OutboxPending
+	message.Attempts = 0
+	message.NextAttempt = s.now()
+	message.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, *message); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Notification queued for retry",
+		zap.String("id", message.ID),
+		zap.String("channel", message.Channel),
+		zap.String("event", message.EventType))
+	s.refreshMetrics(ctx)
+
+	// Đánh thức worker, bỏ qua nếu đã có tín hiệu đang chờ
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return message, nil
+}
+
+func (s *OutboxService) Discard(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("Notification discarded from outbox", zap.String("id", id))
+	s.refreshMetrics(ctx)
+	return nil
+}
@@ -0,0 +1,255 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/metrics"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/notification"
+	"go-image-cleanup/internal/domain/repositories"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// mockOutboxRepository giữ các thông báo trong bộ nhớ
+type mockOutboxRepository struct {
+	messages map[string]models.OutboxMessage
+	nextID   int
+}
+
+func newMockOutboxRepository() *mockOutboxRepository {
+	return &mockOutboxRepository{messages: make(map[string]models.OutboxMessage)}
+}
+
+func (m *mockOutboxRepository) Enqueue(ctx context.Context, message models.OutboxMessage) error {
+	m.nextID++
+	message.ID = string(rune('a' + m.nextID - 1))
+	m.messages[message.ID] = message
+	return nil
+}
+
+func (m *mockOutboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	var due []models.OutboxMessage
+	for _, message := range m.messages {
+		if message.Status == models.OutboxPending && !message.NextAttempt.After(now) {
+			due = append(due, message)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *mockOutboxRepository) Update(ctx context.Context, message models.OutboxMessage) error {
+	if _, ok := m.messages[message.ID]; !ok {
+		return repositories.ErrOutboxMessageNotFound
+	}
+	m.messages[message.ID] = message
+	return nil
+}
+
+func (m *mockOutboxRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.messages[id]; !ok {
+		return repositories.ErrOutboxMessageNotFound
+	}
+	delete(m.messages, id)
+	return nil
+}
+
+func (m *mockOutboxRepository) Get(ctx context.Context, id string) (*models.OutboxMessage, error) {
+	message, ok := m.messages[id]
+	if !ok {
+		return nil, repositories.ErrOutboxMessageNotFound
+	}
+	return &message, nil
+}
+
+func (m *mockOutboxRepository) List(ctx context.Context, status string, limit, offset int) ([]models.OutboxMessage, error) {
+	return nil, nil
+}
+
+func (m *mockOutboxRepository) Stats(ctx context.Context) (models.OutboxStats, error) {
+	var stats models.OutboxStats
+	for _, message := range m.messages {
+		switch message.Status {
+		case models.OutboxPending:
+			stats.Pending++
+		case models.OutboxFailed:
+			stats.Failed++
+		}
+	}
+	return stats, nil
+}
+
+// mockChannels thất bại với failures lần gửi đầu tiên và ghi lại đích, delivery ID của mỗi lần gửi
+type mockChannels struct {
+	failures  int
+	delivered []notification.Event
+	calls     int
+	sentTo    []string
+}
+
+func (m *mockChannels) NotifyChannel(ctx context.Context, channel, target string, event notification.Event) error {
+	m.calls++
+	m.sentTo = append(m.sentTo, channel+"/"+target+"/"+notification.DeliveryIDFromContext(ctx))
+	if m.calls <= m.failures {
+		return errors.New("telegram down")
+	}
+	m.delivered = append(m.delivered, event)
+	return nil
+}
+
+// mockMetrics chỉ ghi lại các metric của outbox
+type mockMetrics struct {
+	metrics.MetricsCollector
+	outbox        map[string]int
+	notifications map[string]int
+}
+
+func (m *mockMetrics) SetOutboxMessages(status string, count int) {
+	m.outbox[status] = count
+}
+
+func (m *mockMetrics) IncNotifications(channel, status string) {
+	m.notifications[channel+"/"+status]++
+}
+
+func newTestService(channels *mockChannels, maxAttempts int) (*OutboxService, *mockOutboxRepository, *mockMetrics, *time.Time) {
+	repo := newMockOutboxRepository()
+	collected := &mockMetrics{outbox: make(map[string]int), notifications: make(map[string]int)}
+	service := NewOutboxService(repo, channels, collected, maxAttempts, time.Minute, zap.NewNop())
+	service.maxBackoff = 5 * time.Minute
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, repo, collected, &now
+}
+
+func TestOutboxServiceRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	channels := &mockChannels{failures: 2}
+	service, repo, collected, now := newTestService(channels, 10)
+
+	event := notification.RunFailed{Host: models.Host{NodeName: "node-1"}, Error: "runtime unavailable"}
+	if err := service.Enqueue(ctx, "telegram", "-1001", "delivery-1", event, errors.New("connection refused")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if collected.outbox[models.OutboxPending] != 1 {
+		t.Errorf("expected the pending gauge to be set, got %v", collected.outbox)
+	}
+
+	// Chưa đến lượt: lần gửi đầu tiên đã tính, lần gửi lại đầu tiên chờ backoff
+	if n := service.DeliverDue(ctx); n != 0 {
+		t.Fatalf("expected nothing due before the backoff, delivered %d", n)
+	}
+
+	// Mỗi lần thất bại chờ gấp đôi, tối đa maxBackoff
+	for _, wait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		*now = now.Add(wait)
+		if n := service.DeliverDue(ctx); n != 1 {
+			t.Fatalf("expected one message to be due after %v, got %d", wait, n)
+		}
+	}
+	message := repo.messages["a"]
+	if message.Attempts != 3 || message.LastError != "telegram down" || !message.NextAttempt.Equal(now.Add(4*time.Minute)) {
+		t.Fatalf("expected the third attempt to wait 4m, got %+v", message)
+	}
+
+	*now = now.Add(4 * time.Minute)
+	if n := service.DeliverDue(ctx); n != 1 {
+		t.Fatalf("expected the message to be delivered, got %d", n)
+	}
+	if len(repo.messages) != 0 {
+		t.Errorf("expected the delivered message to be removed, got %+v", repo.messages)
+	}
+	if len(channels.delivered) != 1 || channels.delivered[0].(notification.RunFailed).Error != "runtime unavailable" {
+		t.Errorf("expected the decoded event to be delivered, got %+v", channels.delivered)
+	}
+	if collected.notifications["telegram/sent"] != 1 || collected.outbox[models.OutboxPending] != 0 {
+		t.Errorf("unexpected metrics: %v %v", collected.notifications, collected.outbox)
+	}
+	// Mọi lần gửi lại chỉ tới chat bị lỗi và mang cùng delivery ID
+	for _, sent := range channels.sentTo {
+		if sent != "telegram/-1001/delivery-1" {
+			t.Errorf("expected every retry to reuse the target and delivery ID, got %q", channels.sentTo)
+			break
+		}
+	}
+
+	if got := service.delay(10); got != 5*time.Minute {
+		t.Errorf("expected the delay to be capped at 5m, got %v", got)
+	}
+}
+
+func TestOutboxServiceDeadLettersAndRetries(t *testing.T) {
+	ctx := context.Background()
+	channels := &mockChannels{failures: 1}
+	service, repo, collected, now := newTestService(channels, 2)
+
+	if err := service.Enqueue(ctx, "slack", "", "", notification.Report{Removed: 3}, errors.New("timeout")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	*now = now.Add(time.Hour)
+	service.DeliverDue(ctx)
+	message := repo.messages["a"]
+	if message.Status != models.OutboxFailed || message.Attempts != 2 {
+		t.Fatalf("expected the message to be dead-lettered after 2 attempts, got %+v", message)
+	}
+	if message.DeliveryID == "" || message.Target != "" {
+		t.Errorf("expected a generated delivery ID for the whole channel, got %+v", message)
+	}
+	if collected.notifications["slack/failed"] != 1 || collected.outbox[models.OutboxFailed] != 1 {
+		t.Errorf("unexpected metrics: %v %v", collected.notifications, collected.outbox)
+	}
+
+	*now = now.Add(time.Hour)
+	if n := service.DeliverDue(ctx); n != 0 {
+		t.Errorf("expected dead letters not to be retried automatically, got %d", n)
+	}
+
+	retried, err := service.Retry(ctx, "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retried.Status != models.OutboxPending || retried.Attempts != 0 {
+		t.Errorf("expected the message to be pending with a fresh budget, got %+v", retried)
+	}
+	select {
+	case <-service.wake:
+	default:
+		t.Error("expected Retry to wake the worker")
+	}
+
+	// Kênh đã hoạt động lại nên lần gửi lại thành công
+	service.DeliverDue(ctx)
+	if len(repo.messages) != 0 || len(channels.delivered) != 1 {
+		t.Errorf("expected the retried message to be delivered, got %+v", repo.messages)
+	}
+
+	if _, err := service.Retry(ctx, "a"); !errors.Is(err, repositories.ErrOutboxMessageNotFound) {
+		t.Errorf("expected ErrOutboxMessageNotFound, got %v", err)
+	}
+}
+
+func TestOutboxServiceDeadLettersUndecodableMessages(t *testing.T) {
+	ctx := context.Background()
+	channels := &mockChannels{}
+	service, repo, _, now := newTestService(channels, 10)
+
+	repo.Enqueue(ctx, models.OutboxMessage{Channel: "webhook", EventType: "unknown", Payload: []byte(`{}`),
+		Status: models.OutboxPending, NextAttempt: *now})
+	service.DeliverDue(ctx)
+
+	if message := repo.messages["a"]; message.Status != models.OutboxFailed || message.LastError == "" {
+		t.Errorf("expected the message to be dead-lettered with the decode error, got %+v", message)
+	}
+	if channels.calls != 0 {
+		t.Errorf("expected no delivery attempt, got %d", channels.calls)
+	}
+}
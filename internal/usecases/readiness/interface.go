@@ -0,0 +1,38 @@
+package readiness
+
+import (
+	"context"
+	"time"
+)
+
+// Trạng thái của một check và của toàn bộ báo cáo
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+)
+
+// CheckResult là kết quả của một dependency check
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report tổng hợp kết quả của tất cả check
+type Report struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Ready cho biết mọi check đều thành công
+func (r Report) Ready() bool {
+	return r.Status == StatusPass
+}
+
+type ReadinessUseCase interface {
+	// Check chạy song song tất cả dependency check, mỗi check có timeout riêng
+	Check(ctx context.Context) Report
+}
@@ -0,0 +1,91 @@
+package readiness
+
+import (
+	"context"
+	"go-image-cleanup/internal/domain/health"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Verify that ReadinessService implements ReadinessUseCase
+var _ ReadinessUseCase = (*ReadinessService)(nil)
+
+type ReadinessService struct {
+	checkers     []health.Checker
+	checkTimeout time.Duration
+	logger       *zap.Logger
+}
+
+func NewReadinessService(checkers []health.Checker, checkTimeout time.Duration, logger *zap.Logger) *ReadinessService {
+	return &ReadinessService{
+		checkers:     checkers,
+		checkTimeout: checkTimeout,
+		logger:       logger,
+	}
+}
+
+func (s *ReadinessService) Check(ctx context.Context) Report {
+	results := make([]CheckResult, len(s.checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func(i int, checker health.Checker) {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusPass,
+		CheckedAt: time.Now().UTC(),
+		Checks:    results,
+	}
+	for _, result := range results {
+		if result.Status == StatusFail {
+			report.Status = StatusFail
+			s.logger.Warn("Readiness check failed",
+				zap.String("check", result.Name),
+				zap.String("error", result.Error))
+		}
+	}
+
+	return report
+}
+
+// runCheck chạy một check với timeout; check không trả về kịp được coi là thất bại
+func (s *ReadinessService) runCheck(ctx context.Context, checker health.Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+
+	start := time.Now()
+	go func() {
+		detail, err := checker.Check(ctx)
+		done <- outcome{detail, err}
+	}()
+
+	result := CheckResult{Name: checker.Name(), Status: StatusPass}
+	select {
+	case out := <-done:
+		result.Detail = out.detail
+		if out.err != nil {
+			result.Status = StatusFail
+			result.Error = out.err.Error()
+		}
+	case <-ctx.Done():
+		result.Status = StatusFail
+		result.Error = "check timed out: " + ctx.Err().Error()
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	return result
+}
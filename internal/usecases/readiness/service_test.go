@@ -0,0 +1,113 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/health"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Mock checker
+type mockChecker struct {
+	name   string
+	detail string
+	err    error
+	delay  time.Duration
+}
+
+func (m *mockChecker) Name() string {
+	return m.name
+}
+
+func (m *mockChecker) Check(ctx context.Context) (string, error) {
+	if m.delay > 0 {
+		// Cố tình bỏ qua ctx để kiểm tra service tự áp timeout
+		time.Sleep(m.delay)
+	}
+	return m.detail, m.err
+}
+
+func TestReadinessService(t *testing.T) {
+	logger := zap.NewNop()
+
+	tests := []struct {
+		name          string
+		checkers      []*mockChecker
+		expectedReady bool
+		expectedFails []string
+	}{
+		{
+			name: "all checks pass",
+			checkers: []*mockChecker{
+				{name: "runtime", detail: "containerd 1.7"},
+				{name: "database", detail: "reachable"},
+			},
+			expectedReady: true,
+		},
+		{
+			name: "one check fails",
+			checkers: []*mockChecker{
+				{name: "runtime", detail: "containerd 1.7"},
+				{name: "database", err: errors.New("database is locked")},
+			},
+			expectedReady: false,
+			expectedFails: []string{"database"},
+		},
+		{
+			name: "slow check times out",
+			checkers: []*mockChecker{
+				{name: "runtime", delay: time.Second},
+				{name: "database", detail: "reachable"},
+			},
+			expectedReady: false,
+			expectedFails: []string{"runtime"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkers := make([]health.Checker, len(tt.checkers))
+			for i, c := range tt.checkers {
+				checkers[i] = c
+			}
+			service := NewReadinessService(checkers, 50*time.Millisecond, logger)
+
+			start := time.Now()
+			report := service.Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("expected checks to be bounded by timeout, took %v", elapsed)
+			}
+
+			if report.Ready() != tt.expectedReady {
+				t.Errorf("expected ready=%v, got %v", tt.expectedReady, report.Ready())
+			}
+			if len(report.Checks) != len(tt.checkers) {
+				t.Fatalf("expected %d check results, got %d", len(tt.checkers), len(report.Checks))
+			}
+
+			var fails []string
+			for i, result := range report.Checks {
+				if result.Name != tt.checkers[i].name {
+					t.Errorf("expected check %d to be %s, got %s", i, tt.checkers[i].name, result.Name)
+				}
+				if result.Status == StatusFail {
+					if result.Error == "" {
+						t.Errorf("expected error message for failed check %s", result.Name)
+					}
+					fails = append(fails, result.Name)
+				}
+			}
+			if len(fails) != len(tt.expectedFails) {
+				t.Fatalf("expected failed checks %v, got %v", tt.expectedFails, fails)
+			}
+			for i := range fails {
+				if fails[i] != tt.expectedFails[i] {
+					t.Errorf("expected failed checks %v, got %v", tt.expectedFails, fails)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrScheduleNotFound được trả về khi không có job nào với tên đã cho
+	ErrScheduleNotFound = errors.New("schedule not found")
+
+	// ErrInvalidExpression được trả về khi biểu thức cron không hợp lệ
+	ErrInvalidExpression = errors.New("invalid cron expression")
+)
+
+// ScheduleStatus là trạng thái hiện tại của một job cron
+type ScheduleStatus struct {
+	Name              string     `json:"name"`
+	Expression        string     `json:"expression"`
+	DefaultExpression string     `json:"default_expression"`
+	Paused            bool       `json:"paused"`
+	NextRun           *time.Time `json:"next_run"`
+	PrevRun           *time.Time `json:"prev_run"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy         string     `json:"updated_by,omitempty"`
+}
+
+type ScheduleUseCase interface {
+	// ListSchedules trả về trạng thái các job theo thứ tự đăng ký
+	ListSchedules(ctx context.Context) []ScheduleStatus
+
+	// GetSchedule trả về trạng thái hiện tại của một job, ErrScheduleNotFound nếu không có
+	GetSchedule(ctx context.Context, name string) (*ScheduleStatus, error)
+
+	// Pause tạm dừng job cho tới khi Resume, kể cả sau khi restart
+	Pause(ctx context.Context, name, actor string) (*ScheduleStatus, error)
+
+	// Resume chạy lại job theo biểu thức hiện tại
+	Resume(ctx context.Context, name, actor string) (*ScheduleStatus, error)
+
+	// Reschedule đổi biểu thức cron của job, giữ nguyên trạng thái tạm dừng
+	Reschedule(ctx context.Context, name, expression, actor string) (*ScheduleStatus, error)
+}
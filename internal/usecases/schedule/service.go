@@ -0,0 +1,272 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"go-image-cleanup/internal/domain/models"
+	"go-image-cleanup/internal/domain/repositories"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Verify that ScheduleService implements ScheduleUseCase
+var _ ScheduleUseCase = (*ScheduleService)(nil)
+
+// job là một job cron đã đăng ký; entryID bằng 0 khi job đang tạm dừng
+type job struct {
+	name              string
+	defaultExpression string
+	expression        string
+	paused            bool
+	run               func()
+	entryID           cron.EntryID
+	prevRun           time.Time
+	updatedAt         time.Time
+	updatedBy         string
+}
+
+type ScheduleService struct {
+	cron   *cron.Cron
+	repo   repositories.ScheduleRepository
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	// order giữ thứ tự đăng ký để ListSchedules ổn định
+	order []string
+}
+
+func NewScheduleService(c *cron.Cron, repo repositories.ScheduleRepository, logger *zap.Logger) *ScheduleService {
+	return &ScheduleService{
+		cron:   c,
+		repo:   repo,
+		logger: logger,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Register thêm job vào scheduler. Lịch đã lưu qua API (nếu có) được ưu tiên hơn defaultExpression từ cấu hình.
+func (s *ScheduleService) Register(ctx context.Context, name, defaultExpression string, run func()) error {
+	if _, err := cron.ParseStandard(defaultExpression); err != nil {
+		return fmt.Errorf("%w for %s: %v", ErrInvalidExpression, name, err)
+	}
+
+	j := &job{
+		name:              name,
+		defaultExpression: defaultExpression,
+		expression:        defaultExpression,
+	}
+	// Ghi lại lần chạy gần nhất trên job thay vì entry cron, vì entry được tạo lại mỗi lần đổi lịch
+	j.run = func() {
+		s.mu.Lock()
+		j.prevRun = time.Now()
+		s.mu.Unlock()
+		run()
+	}
+
+	stored, err := s.findStored(ctx, name)
+	if err != nil {
+		// Không chặn khởi động vì lỗi đọc database, chạy theo cấu hình
+		s.logger.Warn("Failed to load persisted schedule, using configured schedule",
+			zap.String("name", name),
+			zap.Error(err))
+	}
+	if stored != nil {
+		if _, err := cron.ParseStandard(stored.Expression); err != nil {
+			s.logger.Warn("Ignoring invalid persisted schedule",
+				zap.String("name", name),
+				zap.String("expression", stored.Expression),
+				zap.Error(err))
+		} else {
+			j.expression = stored.Expression
+		}
+		j.paused = stored.Paused
+		j.updatedAt = stored.UpdatedAt
+		j.updatedBy = stored.UpdatedBy
+
+		if j.expression != defaultExpression || j.paused {
+			s.logger.Warn("Using schedule changed through the API instead of configuration",
+				zap.String("name", name),
+				zap.String("expression", j.expression),
+				zap.String("configured_expression", defaultExpression),
+				zap.Bool("paused", j.paused),
+				zap.String("updated_by", j.updatedBy))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("schedule %s is already registered", name)
+	}
+
+	if !j.paused {
+		if err := s.addEntry(j); err != nil {
+			return err
+		}
+	}
+
+	s.jobs[name] = j
+	s.order = append(s.order, name)
+
+	s.logger.Info("Job scheduled",
+		zap.String("name", name),
+		zap.String("schedule", j.expression),
+		zap.Bool("paused", j.paused))
+
+	return nil
+}
+
+func (s *ScheduleService) ListSchedules(ctx context.Context) []ScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ScheduleStatus, 0, len(s.order))
+	for _, name := range s.order {
+		statuses = append(statuses, s.status(s.jobs[name]))
+	}
+	return statuses
+}
+
+func (s *ScheduleService) GetSchedule(ctx context.Context, name string) (*ScheduleStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	status := s.status(j)
+	return &status, nil
+}
+
+func (s *ScheduleService) Pause(ctx context.Context, name, actor string) (*ScheduleStatus, error) {
+	return s.update(ctx, name, actor, func(j *job) {
+		j.paused = true
+	})
+}
+
+func (s *ScheduleService) Resume(ctx context.Context, name, actor string) (*ScheduleStatus, error) {
+	return s.update(ctx, name, actor, func(j *job) {
+		j.paused = false
+	})
+}
+
+func (s *ScheduleService) Reschedule(ctx context.Context, name, expression, actor string) (*ScheduleStatus, error) {
+	expression = strings.TrimSpace(expression)
+	if _, err := cron.ParseStandard(expression); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+
+	return s.update(ctx, name, actor, func(j *job) {
+		j.expression = expression
+	})
+}
+
+// update áp dụng thay đổi, lưu vào database rồi mới đổi entry trong cron để hai bên luôn khớp nhau
+func (s *ScheduleService) update(ctx context.Context, name, actor string, change func(j *job)) (*ScheduleStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+
+	next := *current
+	change(&next)
+	next.updatedAt = time.Now().UTC().Truncate(time.Second)
+	next.updatedBy = actor
+
+	err := s.repo.SaveSchedule(ctx, models.Schedule{
+		Name:       next.name,
+		Expression: next.expression,
+		Paused:     next.paused,
+		UpdatedAt:  next.updatedAt,
+		UpdatedBy:  next.updatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Thêm entry mới trước khi xóa entry cũ để job không bị mất nếu biểu thức lỗi
+	oldEntryID := current.entryID
+	next.entryID = 0
+	if !next.paused {
+		if err := s.addEntry(&next); err != nil {
+			return nil, err
+		}
+	}
+	if oldEntryID != 0 {
+		s.cron.Remove(oldEntryID)
+	}
+
+	*current = next
+
+	s.logger.Info("Schedule updated",
+		zap.String("name", name),
+		zap.String("schedule", current.expression),
+		zap.Bool("paused", current.paused),
+		zap.String("actor", actor))
+
+	status := s.status(current)
+	return &status, nil
+}
+
+func (s *ScheduleService) addEntry(j *job) error {
+	id, err := s.cron.AddFunc(j.expression, j.run)
+	if err != nil {
+		return fmt.Errorf("%w for %s: %v", ErrInvalidExpression, j.name, err)
+	}
+	j.entryID = id
+	return nil
+}
+
+func (s *ScheduleService) findStored(ctx context.Context, name string) (*models.Schedule, error) {
+	schedules, err := s.repo.ListSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range schedules {
+		if schedule.Name == name {
+			return &schedule, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *ScheduleService) status(j *job) ScheduleStatus {
+	status := ScheduleStatus{
+		Name:              j.name,
+		Expression:        j.expression,
+		DefaultExpression: j.defaultExpression,
+		Paused:            j.paused,
+		UpdatedBy:         j.updatedBy,
+	}
+	if !j.updatedAt.IsZero() {
+		updatedAt := j.updatedAt
+		status.UpdatedAt = &updatedAt
+	}
+
+	if j.entryID != 0 {
+		entry := s.cron.Entry(j.entryID)
+		// Next bằng 0 khi scheduler chưa Start; khi đó tính từ biểu thức
+		if !entry.Next.IsZero() {
+			status.NextRun = &entry.Next
+		} else if entry.Schedule != nil {
+			next := entry.Schedule.Next(time.Now())
+			status.NextRun = &next
+		}
+	}
+	if !j.prevRun.IsZero() {
+		prevRun := j.prevRun
+		status.PrevRun = &prevRun
+	}
+
+	return status
+}
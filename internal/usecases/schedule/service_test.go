@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"go-image-cleanup/internal/domain/models"
+	"testing"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Mock schedule repository
+type mockScheduleRepository struct {
+	schedules map[string]models.Schedule
+	saveErr   error
+}
+
+func newMockScheduleRepository(schedules ...models.Schedule) *mockScheduleRepository {
+	m := &mockScheduleRepository{schedules: make(map[string]models.Schedule)}
+	for _, schedule := range schedules {
+		m.schedules[schedule.Name] = schedule
+	}
+	return m
+}
+
+func (m *mockScheduleRepository) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	for _, schedule := range m.schedules {
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (m *mockScheduleRepository) SaveSchedule(ctx context.Context, schedule models.Schedule) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.schedules[schedule.Name] = schedule
+	return nil
+}
+
+func TestScheduleService(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	t.Run("pause, resume and reschedule are persisted", func(t *testing.T) {
+		c := cron.New()
+		repo := newMockScheduleRepository()
+		service := NewScheduleService(c, repo, logger)
+
+		if err := service.Register(ctx, models.ScheduleCleanup, "0 0 * * *", func() {}); err != nil {
+			t.Fatalf("failed to register job: %v", err)
+		}
+
+		statuses := service.ListSchedules(ctx)
+		if len(statuses) != 1 || statuses[0].Expression != "0 0 * * *" || statuses[0].NextRun == nil || statuses[0].Paused {
+			t.Fatalf("unexpected schedules: %+v", statuses)
+		}
+
+		status, err := service.Pause(ctx, models.ScheduleCleanup, "api_key:ops")
+		if err != nil {
+			t.Fatalf("failed to pause: %v", err)
+		}
+		if !status.Paused || status.NextRun != nil || status.UpdatedBy != "api_key:ops" {
+			t.Errorf("unexpected status after pause: %+v", status)
+		}
+		if len(c.Entries()) != 0 {
+			t.Errorf("expected paused job to be removed from cron, got %d entries", len(c.Entries()))
+		}
+		if !repo.schedules[models.ScheduleCleanup].Paused {
+			t.Error("expected pause to be persisted")
+		}
+
+		// Đổi lịch khi đang tạm dừng không được chạy lại job
+		status, err = service.Reschedule(ctx, models.ScheduleCleanup, "0 4 * * *", "api_key:ops")
+		if err != nil {
+			t.Fatalf("failed to reschedule: %v", err)
+		}
+		if !status.Paused || status.Expression != "0 4 * * *" || len(c.Entries()) != 0 {
+			t.Errorf("unexpected status after reschedule: %+v", status)
+		}
+
+		status, err = service.Resume(ctx, models.ScheduleCleanup, "api_key:ops")
+		if err != nil {
+			t.Fatalf("failed to resume: %v", err)
+		}
+		if status.Paused || status.NextRun == nil || status.NextRun.Hour() != 4 {
+			t.Errorf("unexpected status after resume: %+v", status)
+		}
+		if len(c.Entries()) != 1 {
+			t.Errorf("expected 1 cron entry, got %d", len(c.Entries()))
+		}
+		if stored := repo.schedules[models.ScheduleCleanup]; stored.Paused || stored.Expression != "0 4 * * *" {
+			t.Errorf("unexpected persisted schedule: %+v", stored)
+		}
+
+		current, err := service.GetSchedule(ctx, models.ScheduleCleanup)
+		if err != nil || current.Expression != "0 4 * * *" || current.Paused || current.UpdatedAt == nil {
+			t.Errorf("expected the current schedule, got %+v, %v", current, err)
+		}
+	})
+
+	t.Run("persisted schedule overrides configuration", func(t *testing.T) {
+		c := cron.New()
+		repo := newMockScheduleRepository(models.Schedule{Name: models.ScheduleCleanup, Expression: "30 1 * * *", Paused: true})
+		service := NewScheduleService(c, repo, logger)
+
+		if err := service.Register(ctx, models.ScheduleCleanup, "0 0 * * *", func() {}); err != nil {
+			t.Fatalf("failed to register job: %v", err)
+		}
+
+		statuses := service.ListSchedules(ctx)
+		if statuses[0].Expression != "30 1 * * *" || statuses[0].DefaultExpression != "0 0 * * *" || !statuses[0].Paused {
+			t.Errorf("unexpected schedule: %+v", statuses[0])
+		}
+		if len(c.Entries()) != 0 {
+			t.Errorf("expected paused job not to be scheduled, got %d entries", len(c.Entries()))
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c := cron.New()
+		repo := newMockScheduleRepository()
+		service := NewScheduleService(c, repo, logger)
+
+		if err := service.Register(ctx, models.ScheduleCleanup, "not a schedule", func() {}); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("expected ErrInvalidExpression on register, got %v", err)
+		}
+		if err := service.Register(ctx, models.ScheduleCleanup, "0 0 * * *", func() {}); err != nil {
+			t.Fatalf("failed to register job: %v", err)
+		}
+
+		if _, err := service.Pause(ctx, "unknown", "test"); !errors.Is(err, ErrScheduleNotFound) {
+			t.Errorf("expected ErrScheduleNotFound, got %v", err)
+		}
+		if _, err := service.GetSchedule(ctx, "unknown"); !errors.Is(err, ErrScheduleNotFound) {
+			t.Errorf("expected ErrScheduleNotFound from GetSchedule, got %v", err)
+		}
+		if _, err := service.Reschedule(ctx, models.ScheduleCleanup, "61 * * * *", "test"); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("expected ErrInvalidExpression, got %v", err)
+		}
+
+		// Lỗi khi lưu không được làm thay đổi lịch đang chạy
+		repo.saveErr = errors.New("database is locked")
+		if _, err := service.Pause(ctx, models.ScheduleCleanup, "test"); err == nil {
+			t.Error("expected error when saving fails")
+		}
+		if statuses := service.ListSchedules(ctx); statuses[0].Paused || len(c.Entries()) != 1 {
+			t.Errorf("expected schedule to be unchanged, got %+v", statuses[0])
+		}
+	})
+}
@@ -9,4 +9,50 @@ const (
 	APIVersion      = "v1"
 	ShutdownTimeout = 5 * time.Second
 	CleanupTimeout  = 30 * time.Minute
+
+	MaintenanceTimeout = 10 * time.Minute
+	ExportTimeout      = 10 * time.Minute
+	BackupTimeout      = 10 * time.Minute
+	DigestTimeout      = 2 * time.Minute
+
+	// Timeout cho từng dependency check của /ready và /health
+	ReadinessCheckTimeout = 3 * time.Second
+
+	// Chu kỳ kiểm tra file certificate/key/CA để nạp lại
+	TLSReloadInterval = 30 * time.Second
+
+	// Timeout cho mỗi request gửi thông báo ra ngoài (Slack, webhook, ...)
+	NotificationTimeout = 10 * time.Second
+
+	// Thời gian chờ trước lần gửi lại webhook đầu tiên, nhân đôi sau mỗi lần
+	WebhookRetryBackoff = 2 * time.Second
+
+	// Thời gian chờ trước lần gửi lại Telegram đầu tiên khi lỗi mạng hoặc 5xx, nhân đôi sau mỗi lần.
+	// Khi bị giới hạn tốc độ (429) thì chờ theo retry_after, nhưng không quá TelegramMaxRetryAfter
+	TelegramRetryBackoff  = 2 * time.Second
+	TelegramMaxRetryAfter = time.Minute
+
+	// Long polling getUpdates của bot lệnh Telegram: thời gian chờ mỗi lần gọi, thời gian chờ sau khi lỗi,
+	// và tuổi tối đa của lệnh; lệnh cũ hơn (ví dụ gửi lúc service đang dừng) bị bỏ qua
+	TelegramPollTimeout    = 30 * time.Second
+	TelegramPollRetryDelay = 5 * time.Second
+	TelegramCommandMaxAge  = time.Minute
+
+	// Chu kỳ worker outbox tìm thông báo đến lượt gửi lại, và khoảng chờ tối đa giữa hai lần gửi lại
+	OutboxPollInterval = 15 * time.Second
+	OutboxMaxBackoff   = time.Hour
+
+	// Alert đang firing được gửi lại cho Alertmanager mỗi AlertmanagerResendInterval với endsAt cách
+	// lúc gửi AlertmanagerAlertTTL; nếu service dừng hẳn, Alertmanager tự resolve alert sau khoảng này
+	AlertmanagerResendInterval = time.Minute
+	AlertmanagerAlertTTL       = 5 * time.Minute
+)
+
+// MaxRequestBodyBytes giới hạn body của request HTTP; API chỉ nhận body JSON nhỏ
+const MaxRequestBodyBytes = 64 * 1024
+
+// Phân trang cho /api/v1/results
+const (
+	DefaultResultsLimit = 50
+	MaxResultsLimit     = 500
 )
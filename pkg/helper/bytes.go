@@ -0,0 +1,18 @@
+package helper
+
+import "fmt"
+
+// FormatBytes hiển thị dung lượng theo đơn vị nhị phân, ví dụ 1536 -> "1.5 KiB"
+func FormatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTP"[exp])
+}
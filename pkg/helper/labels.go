@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseLabels parses "key=value,key2=value2" into a map. An empty string returns an empty map.
+func ParseLabels(value string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return labels, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		if err := addLabel(labels, pair); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+// ParseLabelPairs parses a list of "key=value" strings into a map
+func ParseLabelPairs(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range pairs {
+		if err := addLabel(labels, pair); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+// FormatLabels formats labels as "key=value,key2=value2" sorted by key
+func FormatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func addLabel(labels map[string]string, pair string) error {
+	key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("invalid label %q: expected key=value", pair)
+	}
+	labels[key] = strings.TrimSpace(value)
+	return nil
+}
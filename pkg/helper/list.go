@@ -0,0 +1,14 @@
+package helper
+
+import "strings"
+
+// SplitList tách chuỗi phân cách bằng dấu phẩy, bỏ khoảng trắng và phần tử rỗng
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
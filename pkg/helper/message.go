@@ -14,8 +14,9 @@ func FormatCleanupMessage(
 	total int,
 	removed int,
 	skipped int,
+	requestID string,
 ) string {
-	return fmt.Sprintf(`🔄 Image cleanup completed on:
+	message := fmt.Sprintf(`🔄 Image cleanup completed on:
 %s
 
 ⏱ Time Information:
@@ -34,4 +35,10 @@ Duration: %s
 		total,
 		removed,
 		skipped)
+
+	// Chỉ lần chạy do API kích hoạt mới có request ID
+	if requestID != "" {
+		message += fmt.Sprintf("\n\n🔗 Request ID: %s", requestID)
+	}
+	return message
 }
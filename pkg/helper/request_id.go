@@ -0,0 +1,37 @@
+// pkg/helper/request_id.go
+package helper
+
+import "context"
+
+// maxRequestIDLength giới hạn độ dài X-Request-ID nhận từ client
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID gắn request ID vào context để các lần chạy do API kích hoạt mang theo
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext trả về request ID trong context, rỗng nếu không có
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ValidRequestID kiểm tra request ID từ client: không rỗng, tối đa 128 ký tự ASCII in được,
+// không có khoảng trắng để an toàn khi ghi vào log và header
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
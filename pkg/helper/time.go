@@ -1,7 +1,10 @@
 // pkg/helper/time.go
 package helper
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // TimeInICT converts a time to ICT timezone
 func TimeInICT(t time.Time) time.Time {
@@ -17,3 +20,19 @@ func TimeInICT(t time.Time) time.Time {
 func FormatICT(t time.Time) string {
 	return TimeInICT(t).Format("2006-01-02 15:04:05 ICT")
 }
+
+// ParseTimeParam parses a time given as RFC3339 or as a plain date (YYYY-MM-DD, UTC).
+// An empty value returns the zero time.
+func ParseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected RFC3339 or YYYY-MM-DD", value)
+	}
+	return t, nil
+}
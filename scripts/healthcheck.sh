@@ -2,7 +2,29 @@
 
 # Config
 SERVICE_NAME="image-cleanup"
-HEALTH_CHECK_URL="http://localhost:8080/health"
+CONFIG_FILE="/etc/${SERVICE_NAME}/.env"
+
+# Read a value from the service config file, stripping quotes and trailing comments
+config_value() {
+    [ -f "$CONFIG_FILE" ] || return 0
+    grep -E "^$1=" "$CONFIG_FILE" | tail -n1 | cut -d= -f2- | sed -e 's/[[:space:]]*#.*$//' -e 's/"//g' -e 's/[[:space:]]*$//'
+}
+
+HTTP_PORT=$(config_value HTTP_PORT)
+HTTP_PORT=${HTTP_PORT:-8080}
+HTTP_SOCKET_PATH=$(config_value HTTP_SOCKET_PATH)
+CURL_OPTS=""
+if [ -n "$HTTP_SOCKET_PATH" ]; then
+    # The unix socket is always plain HTTP and works even when the TCP port is disabled
+    HEALTH_CHECK_URL="http://localhost/health"
+    CURL_OPTS="--unix-socket ${HTTP_SOCKET_PATH}"
+elif [ -n "$(config_value TLS_CERT_FILE)" ]; then
+    # The certificate is issued for the node name, not localhost; /health needs no client certificate
+    HEALTH_CHECK_URL="https://localhost:${HTTP_PORT}/health"
+    CURL_OPTS="-k"
+else
+    HEALTH_CHECK_URL="http://localhost:${HTTP_PORT}/health"
+fi
 MAX_RETRIES=3
 RETRY_INTERVAL=5
 LOG_FILE="/var/log/${SERVICE_NAME}/health.log"
@@ -19,7 +41,7 @@ mkdir -p "/var/log/${SERVICE_NAME}"
 # Function to do HTTP health check
 do_health_check() {
     log "Making HTTP request to: $HEALTH_CHECK_URL"
-    response=$(curl -s -m $CURL_TIMEOUT -w "\n%{http_code}" $HEALTH_CHECK_URL)
+    response=$(curl -s $CURL_OPTS -m $CURL_TIMEOUT -w "\n%{http_code}" $HEALTH_CHECK_URL)
     curl_status=$?
 
     if [ $curl_status -ne 0 ]; then
@@ -111,13 +111,76 @@ if [ ! -f "$CONFIG_DIR/.env" ]; then
     log "Creating default config file..."
     cat > "$CONFIG_DIR/.env" << EOF
 # Service configuration
-TELEGRAM_BOT_TOKEN=your_bot_token
-TELEGRAM_CHAT_ID=your_chat_id
+TELEGRAM_BOT_TOKEN=            # Telegram bot token; optional
+TELEGRAM_CHAT_ID=              # Comma-separated chat IDs, <chat_id>:<topic_id> for a forum topic
+TELEGRAM_PARSE_MODE=text       # text or MarkdownV2
+TELEGRAM_TIMEOUT=10s
+TELEGRAM_MAX_RETRIES=3
+TELEGRAM_API_URL=https://api.telegram.org
+TELEGRAM_COMMANDS_ENABLED=false  # Answer /status, /cleanup, ... from TELEGRAM_ALLOWED_IDS
+TELEGRAM_ALLOWED_IDS=          # Comma-separated chat and user IDs allowed to send commands
+SLACK_WEBHOOK_URL=             # Slack incoming webhook; optional
+WEBHOOK_URLS=                  # Comma-separated URLs for signed JSON events; optional
+WEBHOOK_SECRET=                # HMAC-SHA256 key, required with WEBHOOK_URLS
+WEBHOOK_TIMEOUT=10s
+WEBHOOK_MAX_RETRIES=3
+ALERTMANAGER_URL=              # Comma-separated Alertmanager URLs for failure alerts; optional
+ALERTMANAGER_TIMEOUT=10s
+ALERTMANAGER_FAILURE_THRESHOLD=0  # Alert when more images than this fail to be removed in a run
+ALERTMANAGER_STALE_AFTER=      # Alert when no run succeeded for this long (empty = derived from schedule)
+SMTP_HOST=                     # SMTP server for email reports; optional
+SMTP_PORT=587
+SMTP_USERNAME=
+SMTP_PASSWORD=
+SMTP_TLS=starttls              # starttls, tls or none
+EMAIL_FROM=
+EMAIL_TO=                      # Comma-separated recipients
+EMAIL_MODE=run                 # run, digest or both
+EMAIL_DIGEST_SCHEDULE="0 8 * * 1"
+EMAIL_DIGEST_PERIOD=168h
+NOTIFY_LANGUAGE=en             # en or vi
+NOTIFY_TIMEZONE=               # e.g. Europe/Berlin; default ICT
+NOTIFY_TEMPLATE_DIR=           # e.g. /etc/image-cleanup/templates
+NOTIFY_OUTBOX_MAX_ATTEMPTS=10
+NOTIFY_RETRY_BACKOFF=30s       # Doubled after each failed retry
+DISK_PRESSURE_PATH=/var/lib/containerd
+DISK_PRESSURE_THRESHOLD=85     # Used percent, 0 disables
+# Per-channel routing: <CHANNEL>_EVENTS, _ONLY_FAILURES, _MIN_REMOVED, _HOSTS, _QUIET_HOURS
+# for TELEGRAM, SLACK, WEBHOOK and EMAIL, e.g.
+# SLACK_EVENTS=run_failed,disk_pressure
+# SLACK_ONLY_FAILURES=true
+# EMAIL_QUIET_HOURS=22:00-07:00
 CLEANUP_SCHEDULE="0 0 * * *"
-HTTP_PORT=8080
+HTTP_PORT=8080                 # 0 disables TCP when HTTP_SOCKET_PATH is set
+HTTP_SOCKET_PATH=              # e.g. /run/image-cleanup/api.sock
+HTTP_SOCKET_MODE=0660
+
+# Host identity
+NODE_NAME=                     # Defaults to the hostname
+HOST_LABELS=                   # e.g. env=prod,zone=a
+
+# API authentication
+AUTH_ENABLED=true              # Require bearer API keys for /api/v1
+API_KEYS_FILE=                 # Optional file of static keys (name:scopes:sha256)
+
+# TLS
+TLS_CERT_FILE=                 # Server certificate; enables HTTPS when set
+TLS_KEY_FILE=
+TLS_CLIENT_CA_FILE=            # Require client certificates from this CA for /api/v1
 
 # Database configuration
 SQLITE_DB_PATH=${DATA_DIR}/cleanup.db
+RESULT_STORE=sqlite            # sqlite or postgres
+POSTGRES_DSN=                  # Required when RESULT_STORE=postgres
+BACKUP_DIR=${DATA_DIR}/backups
+
+# Readiness
+READY_MAX_RUN_AGE=             # Max age of the last cleanup before /ready fails (empty = derived from schedule)
+
+# History retention
+HISTORY_RETENTION_DAYS=0       # Days of cleanup history to keep, e.g. 90 (0 = unlimited)
+HISTORY_MAX_ROWS=0             # Maximum cleanup results to keep (0 = unlimited)
+MAINTENANCE_SCHEDULE="30 3 * * *"
 
 # Logger configuration
 LOG_LEVEL=info